@@ -0,0 +1,408 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"vallet-launcher/desktop"
+	"vallet-launcher/templates"
+	"vallet-launcher/utils"
+
+	"github.com/atotto/clipboard"
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Tipos de acción que puede ejecutar el launcher. Los links guardan uno de los primeros
+// seis en links.action_type; ActionSearch solo se produce al resolver texto libre.
+const (
+	ActionURL         = "url"         // Abrir en el navegador.
+	ActionFile        = "file"        // Abrir un archivo con su programa predeterminado.
+	ActionFolder      = "folder"      // Abrir una carpeta en el explorador de archivos.
+	ActionApplication = "application" // Lanzar una aplicación.
+	ActionCommand     = "command"     // Ejecutar un comando del sistema.
+	ActionSnippet     = "snippet"     // Pegar un texto en la aplicación activa.
+	ActionSearch      = "search"      // Buscar el texto con un motor de búsqueda.
+	ActionCopy        = "copy"        // Copiar un valor al portapapeles (ej: resultado de la calculadora).
+	ActionPaste       = "paste"       // Pegar un texto tal cual, sin variables (ej: historial del portapapeles).
+)
+
+// linkActionTypes son los tipos válidos para un link.
+var linkActionTypes = map[string]bool{
+	ActionURL:         true,
+	ActionFile:        true,
+	ActionFolder:      true,
+	ActionApplication: true,
+	ActionCommand:     true,
+	ActionSnippet:     true,
+}
+
+// actionLabels describe cada tipo de acción para mostrarlo en el launcher.
+var actionLabels = map[string]string{
+	ActionURL:         "Abrir",
+	ActionFile:        "Abrir archivo",
+	ActionFolder:      "Abrir carpeta",
+	ActionApplication: "Abrir aplicación",
+	ActionCommand:     "Ejecutar",
+	ActionSnippet:     "Pegar texto",
+	ActionSearch:      "Buscar",
+	ActionCopy:        "Copiar",
+	ActionPaste:       "Pegar",
+}
+
+// Action es el resultado de resolver la entrada del launcher: qué se hará al pulsar Enter.
+type Action struct {
+	Type   string `json:"type"`    // Tipo de acción (ver constantes Action*).
+	Target string `json:"target"`  // URL, ruta, comando o texto sobre el que se actúa.
+	Label  string `json:"label"`   // Descripción legible para la UI.
+	LinkID int    `json:"link_id"` // Link del que proviene la acción (0 si no viene de un link).
+}
+
+// newAction crea una acción con su descripción por defecto.
+func newAction(actionType, target string) Action {
+	return Action{Type: actionType, Target: target, Label: actionLabels[actionType] + ": " + target}
+}
+
+// knownTLDs son los dominios de primer nivel que se aceptan al reconocer una URL sin
+// esquema ("github.com"), para no confundir "notas.txt" o "1.5" con sitios web.
+var knownTLDs = map[string]bool{
+	"com": true, "org": true, "net": true, "edu": true, "gov": true, "mil": true, "int": true,
+	"io": true, "dev": true, "app": true, "ai": true, "co": true, "me": true, "tv": true,
+	"info": true, "biz": true, "xyz": true, "tech": true, "site": true, "online": true,
+	"cloud": true, "gg": true, "ly": true, "sh": true, "so": true, "to": true, "fm": true,
+	"es": true, "mx": true, "ar": true, "cl": true, "pe": true, "uy": true, "ve": true,
+	"ec": true, "bo": true, "py": true, "cr": true, "gt": true, "do": true, "pr": true,
+	"us": true, "ca": true, "uk": true, "de": true, "fr": true, "it": true, "pt": true,
+	"br": true, "nl": true, "be": true, "ch": true, "at": true, "se": true, "no": true,
+	"dk": true, "fi": true, "pl": true, "eu": true, "ru": true, "jp": true, "cn": true,
+	"kr": true, "in": true, "au": true, "nz": true, "za": true, "page": true, "blog": true,
+}
+
+// ResolveInput indica qué hará el launcher con la entrada sin ejecutarla, para que la UI
+// pueda mostrarlo antes de pulsar Enter.
+func (a *App) ResolveInput(input string) (Action, error) {
+	return a.resolveInput(strings.TrimSpace(input))
+}
+
+// resolveInput clasifica la entrada en este orden: motor de búsqueda con palabra clave,
+// link guardado, URL/ruta/ejecutable reconocible y, por último, búsqueda con el motor
+// por defecto o comando del sistema.
+func (a *App) resolveInput(input string) (Action, error) {
+	if input == "" {
+		return Action{}, fmt.Errorf("entrada vacía")
+	}
+
+	// 1. Palabra clave de un motor de búsqueda (ej: "g texto"), si no es el alias de un link.
+	if engine, args := a.engineCall(input); engine != nil {
+		return searchAction(*engine, args)
+	}
+
+	// 2. Link guardado (mismo ranking que las sugerencias).
+	links, err := a.db.SearchLinks(input)
+	if err == nil && len(links) > 0 {
+		return linkAction(links[0], input)
+	}
+
+	// 3. URL, ruta existente o ejecutable en el PATH.
+	if action, ok := classifyInput(input); ok {
+		return action, nil
+	}
+
+	// 4. El texto libre se busca con el motor por defecto en lugar de ejecutarlo.
+	// En Windows una sola palabra se intenta como comando porque 'start' también
+	// encuentra aplicaciones registradas que no están en el PATH (ej: "chrome").
+	isFreeText := strings.ContainsAny(input, " \t")
+	if isFreeText || runtime.GOOS != "windows" {
+		if engine, err := a.defaultSearchEngine(); err == nil && engine != nil {
+			return searchAction(*engine, strings.Fields(input))
+		}
+	}
+
+	// 5. Comando del sistema.
+	return newAction(ActionCommand, input), nil
+}
+
+// searchAction construye la acción de buscar los términos con un motor.
+func searchAction(engine SearchEngine, terms []string) (Action, error) {
+	tmpl, err := templates.Parse(engine.URL)
+	if err != nil {
+		return Action{}, err
+	}
+	target, err := tmpl.Expand(terms)
+	if err != nil {
+		return Action{}, err
+	}
+	action := newAction(ActionSearch, target)
+	action.Label = fmt.Sprintf("Buscar \"%s\" en %s", strings.Join(terms, " "), engine.Name)
+	return action, nil
+}
+
+// linkAction construye la acción de un link, expandiendo su plantilla con la consulta.
+func linkAction(link Link, query string) (Action, error) {
+	target, err := expandLinkURL(link, query)
+	if err != nil {
+		return Action{}, fmt.Errorf("no se pudo abrir '%s': %w", link.Name, err)
+	}
+
+	actionType := link.Type
+	if !linkActionTypes[actionType] {
+		actionType = inferActionType(target)
+	}
+
+	action := newAction(actionType, target)
+	if link.isSaved() {
+		action.LinkID = link.ID
+	}
+	action.Label = actionLabels[actionType] + ": " + link.Name
+	return action, nil
+}
+
+// executeAction ejecuta una acción resuelta.
+func (a *App) executeAction(action Action) error {
+	switch action.Type {
+	case ActionURL, ActionSearch:
+		return a.openURLWithBrowser(action.Target)
+	case ActionFile, ActionFolder:
+		return utils.OpenPath(expandPath(action.Target))
+	case ActionApplication:
+		if strings.HasSuffix(action.Target, ".desktop") {
+			return launchDesktopEntry(expandPath(action.Target), nil)
+		}
+		return utils.RunCommand(action.Target)
+	case ActionCommand:
+		return utils.RunCommand(action.Target)
+	case ActionCopy:
+		return clipboard.WriteAll(action.Target)
+	case ActionSnippet:
+		// El portapapeles se lee antes de pegar, porque PasteText lo sobrescribe mientras pega.
+		current, _ := clipboard.ReadAll()
+		text, cursorBack := templates.ExpandSnippet(action.Target, templates.SnippetVars{
+			Now:       time.Now(),
+			Clipboard: current,
+		})
+
+		// Esperar a que el foco vuelva a la aplicación anterior antes de pegar.
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			opts := a.pasteOptions()
+			opts.CursorLeft = cursorBack // Dejar el cursor donde indica {cursor}.
+			if err := utils.PasteText(text, opts); err != nil {
+				log.Printf("Error pegando snippet: %v", err)
+			}
+		}()
+		return nil
+	case ActionPaste:
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			if err := utils.PasteText(action.Target, a.pasteOptions()); err != nil {
+				log.Printf("Error pegando texto: %v", err)
+			}
+		}()
+		return nil
+	}
+	return fmt.Errorf("tipo de acción desconocido: %s", action.Type)
+}
+
+// classifyInput reconoce entradas que no son links: URLs con esquema, rutas existentes,
+// dominios con un TLD conocido y ejecutables en el PATH.
+func classifyInput(input string) (Action, bool) {
+	// URL con esquema explícito (https://, ftp://, mailto:, ...).
+	if u, err := url.Parse(input); err == nil && len(u.Scheme) > 1 && !strings.ContainsAny(input, " \t") {
+		if u.Host != "" || u.Scheme == "mailto" {
+			return newAction(ActionURL, input), true
+		}
+	}
+
+	// Ruta existente en el sistema de archivos.
+	if info, err := os.Stat(expandPath(input)); err == nil && looksLikePath(input) {
+		if info.IsDir() {
+			return newAction(ActionFolder, input), true
+		}
+		if strings.HasSuffix(input, ".desktop") {
+			return newAction(ActionApplication, input), true
+		}
+		return newAction(ActionFile, input), true
+	}
+
+	// Dominio sin esquema (ej: github.com, localhost:3000, 192.168.1.1).
+	if target, ok := domainURL(input); ok {
+		return newAction(ActionURL, target), true
+	}
+
+	// Ejecutable en el PATH.
+	fields := strings.Fields(input)
+	if _, err := exec.LookPath(fields[0]); err == nil {
+		if len(fields) == 1 {
+			return newAction(ActionApplication, input), true
+		}
+		return newAction(ActionCommand, input), true
+	}
+
+	return Action{}, false
+}
+
+// inferActionType deduce el tipo de acción de un link a partir de su URL o comando
+// guardado, sin depender de que la ruta exista en este equipo.
+func inferActionType(target string) string {
+	target = strings.TrimSpace(target)
+	if templates.HasPlaceholders(target) {
+		if strings.Contains(target, "://") {
+			return ActionURL
+		}
+		return ActionCommand
+	}
+	if u, err := url.Parse(target); err == nil && len(u.Scheme) > 1 && (u.Host != "" || u.Scheme == "mailto") {
+		return ActionURL
+	}
+	if _, ok := domainURL(target); ok {
+		return ActionURL
+	}
+	// Entrada de escritorio de Linux (ruta o identificador como "firefox.desktop").
+	if strings.HasSuffix(target, ".desktop") {
+		return ActionApplication
+	}
+	if looksLikePath(target) && !strings.ContainsAny(target, "\t") {
+		if strings.HasSuffix(target, "/") || strings.HasSuffix(target, `\`) {
+			return ActionFolder
+		}
+		if info, err := os.Stat(expandPath(target)); err == nil && info.IsDir() {
+			return ActionFolder
+		}
+		return ActionFile
+	}
+	return ActionCommand
+}
+
+// domainURL reconoce un dominio sin esquema y devuelve la URL completa. Solo acepta TLDs
+// conocidos, "localhost" y direcciones IP (con puerto opcional).
+func domainURL(input string) (string, bool) {
+	if input == "" || strings.ContainsAny(input, " \t\\") {
+		return "", false
+	}
+
+	u, err := url.Parse("https://" + input)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	host := u.Hostname()
+
+	if host == "localhost" || net.ParseIP(host) != nil {
+		return "http://" + input, true
+	}
+
+	dot := strings.LastIndex(host, ".")
+	if dot <= 0 || !knownTLDs[strings.ToLower(host[dot+1:])] {
+		return "", false
+	}
+	return "https://" + input, true
+}
+
+// looksLikePath indica si el texto tiene forma de ruta (absoluta, relativa al home o UNC).
+func looksLikePath(s string) bool {
+	return filepath.IsAbs(s) || strings.HasPrefix(s, "~") || strings.HasPrefix(s, `\\`) ||
+		strings.HasPrefix(s, "/") || strings.HasPrefix(s, "./") || strings.HasPrefix(s, `.\`)
+}
+
+// expandPath expande "~" y las variables de entorno (%USERPROFILE% o $HOME) de una ruta.
+func expandPath(path string) string {
+	if strings.HasPrefix(path, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if runtime.GOOS == "windows" {
+		var b strings.Builder
+		for {
+			start := strings.Index(path, "%")
+			if start < 0 {
+				break
+			}
+			end := strings.Index(path[start+1:], "%")
+			if end < 0 {
+				break
+			}
+			b.WriteString(path[:start])
+			b.WriteString(os.Getenv(path[start+1 : start+1+end]))
+			path = path[start+2+end:]
+		}
+		b.WriteString(path)
+		return b.String()
+	}
+	return os.ExpandEnv(path)
+}
+
+// launchDesktopEntry ejecuta una aplicación descrita por un archivo .desktop (ruta o
+// identificador como "firefox.desktop"), pasándole los archivos o URLs indicados.
+func launchDesktopEntry(idOrPath string, targets []string) error {
+	entry, err := desktop.Find(idOrPath)
+	if err != nil {
+		return err
+	}
+
+	argv, err := entry.Command(targets)
+	if err != nil {
+		return err
+	}
+	if entry.Terminal() {
+		if argv, err = desktop.TerminalCommand(argv); err != nil {
+			return err
+		}
+	}
+
+	return utils.StartDetached(expandPath(entry.WorkingDir()), argv)
+}
+
+// defaultSearchEngine devuelve el motor configurado en default_search_engine (nil si no hay).
+func (a *App) defaultSearchEngine() (*SearchEngine, error) {
+	keyword, err := a.db.GetSetting("default_search_engine")
+	if err != nil || keyword == "" {
+		return nil, err
+	}
+	return a.db.GetSearchEngineByKeyword(keyword)
+}
+
+// OpenSomething procesa la entrada del buscador (un comando, una URL o un alias guardado).
+func (a *App) OpenSomething(input string) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return
+	}
+
+	action, err := a.resolveInput(input)
+	if err != nil {
+		log.Printf("Error resolviendo '%s': %v", input, err)
+		return
+	}
+
+	if err := a.runAction(action, input); err != nil {
+		log.Printf("Error ejecutando '%s': %v", input, err)
+	}
+}
+
+// runAction oculta el launcher, ejecuta la acción y, si proviene de un link, registra la
+// apertura (frecencia) y la elección para la consulta.
+func (a *App) runAction(action Action, query string) error {
+	// Ocultar primero para que los snippets se peguen en la aplicación anterior.
+	wailsruntime.WindowHide(a.ctx)
+
+	if err := a.executeAction(action); err != nil {
+		return err
+	}
+
+	if action.LinkID != 0 {
+		if err := a.db.RecordLaunch(action.LinkID); err != nil {
+			log.Printf("Error registrando apertura del link %d: %v", action.LinkID, err)
+		}
+		if err := a.db.RecordQueryPick(query, action.LinkID); err != nil {
+			log.Printf("Error registrando elección del link %d: %v", action.LinkID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package ai
+
+import (
+	"context"
+	"sync"
+)
+
+// Fake es un backend de transcripción que no usa ningún modelo: devuelve siempre el mismo
+// resultado y recuerda el audio recibido. Sirve para probar el flujo de dictado completo.
+type Fake struct {
+	Result TranscriptionResult // Resultado que devuelve Transcribe.
+	Err    error               // Error que devuelve Transcribe, si no es nil.
+
+	mu    sync.Mutex
+	calls []Audio
+}
+
+// Transcribe devuelve Result o Err y guarda el audio recibido.
+func (f *Fake) Transcribe(ctx context.Context, audio Audio) (TranscriptionResult, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, audio)
+	f.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return TranscriptionResult{}, err
+	}
+	if f.Err != nil {
+		return TranscriptionResult{}, f.Err
+	}
+	return f.Result, nil
+}
+
+// Calls devuelve los audios recibidos, en orden.
+func (f *Fake) Calls() []Audio {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Audio(nil), f.calls...)
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpTimeout limita cuánto puede tardar una transcripción por HTTP.
+const httpTimeout = 2 * time.Minute
+
+// httpClient es el cliente compartido por los backends HTTP.
+var httpClient = &http.Client{Timeout: httpTimeout}
+
+// postAudio envía el audio como formulario multipart (campo "file") junto con los campos
+// indicados y decodifica la respuesta JSON en out.
+func postAudio(ctx context.Context, url string, headers map[string]string, fields map[string]string, audio Audio, out any) error {
+	data, err := audio.Bytes()
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for key, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := form.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+	part, err := form.CreateFormFile("file", "audio.wav")
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s respondió %s: %s", url, resp.Status, strings.TrimSpace(string(payload)))
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("respuesta inválida de %s: %w", url, err)
+	}
+	return nil
+}
+
+// language devuelve el idioma para enviar a una API, o "" para que lo detecte.
+func language(lang string) string {
+	lang = strings.TrimSpace(lang)
+	if strings.EqualFold(lang, "auto") {
+		return ""
+	}
+	return lang
+}
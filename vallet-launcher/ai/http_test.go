@@ -0,0 +1,193 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testWAV es el contenido que se envía como audio en las pruebas.
+var testWAV = []byte("RIFF....WAVEfmt ")
+
+// transcriptionRequest es lo que recibió el servidor de prueba.
+type transcriptionRequest struct {
+	path   string
+	auth   string
+	fields map[string]string
+	file   []byte
+}
+
+// newTranscriptionServer levanta un servidor que responde status y body a cualquier
+// petición y devuelve la última petición recibida.
+func newTranscriptionServer(t *testing.T, status int, body string) (string, func() transcriptionRequest) {
+	t.Helper()
+	var last transcriptionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("formulario multipart inválido: %v", err)
+		}
+		last = transcriptionRequest{path: r.URL.Path, auth: r.Header.Get("Authorization"), fields: map[string]string{}}
+		for key, values := range r.MultipartForm.Value {
+			last.fields[key] = values[0]
+		}
+		if file, _, err := r.FormFile("file"); err == nil {
+			last.file, _ = io.ReadAll(file)
+			file.Close()
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, func() transcriptionRequest { return last }
+}
+
+// checkFields compara los campos del formulario con los esperados.
+func checkFields(t *testing.T, name string, got, want map[string]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: campos = %v, se esperaba %v", name, got, want)
+		return
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s: campo %q = %q, se esperaba %q", name, key, got[key], value)
+		}
+	}
+}
+
+const verboseResponse = `{"text":" hola mundo ","language":"spanish","segments":[{"start":0,"end":1.5,"text":" hola mundo","avg_logprob":-0.2,"no_speech_prob":0.01}]}`
+
+func TestServerClientTranscribe(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		options  WhisperOptions
+		want     map[string]string
+	}{
+		{
+			name:     "idioma automático",
+			language: "auto",
+			options:  DefaultWhisperOptions(),
+			want:     map[string]string{"response_format": "verbose_json", "temperature": "0"},
+		},
+		{
+			name:     "opciones",
+			language: "es",
+			options:  WhisperOptions{Temperature: 0.2, InitialPrompt: "Vallet, Jira.", Translate: true},
+			want: map[string]string{
+				"response_format": "verbose_json",
+				"temperature":     "0.2",
+				"language":        "es",
+				"prompt":          "Vallet, Jira.",
+				"translate":       "true",
+			},
+		},
+	}
+	for _, tt := range tests {
+		url, last := newTranscriptionServer(t, http.StatusOK, verboseResponse)
+		client, err := NewServerClient(url+"/", tt.language)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.options = tt.options
+
+		result, err := client.Transcribe(context.Background(), Audio{WAV: testWAV})
+		if err != nil {
+			t.Fatalf("%s: Transcribe() = %v", tt.name, err)
+		}
+		req := last()
+		if req.path != "/inference" {
+			t.Errorf("%s: ruta = %q, se esperaba /inference", tt.name, req.path)
+		}
+		if string(req.file) != string(testWAV) {
+			t.Errorf("%s: audio enviado = %q", tt.name, req.file)
+		}
+		checkFields(t, tt.name, req.fields, tt.want)
+		if result.Text != "hola mundo" || result.Language != "es" || len(result.Segments) != 1 {
+			t.Errorf("%s: resultado = %+v", tt.name, result)
+		}
+	}
+}
+
+func TestOpenAIClientTranscribe(t *testing.T) {
+	tests := []struct {
+		name, apiKey, model, language string
+		response                      string
+		wantAuth                      string
+		want                          map[string]string
+		wantSegments                  int
+	}{
+		{
+			name: "whisper-1", apiKey: " sk-prueba ", language: "es",
+			response:     verboseResponse,
+			wantAuth:     "Bearer sk-prueba",
+			want:         map[string]string{"model": "whisper-1", "response_format": "verbose_json", "language": "es"},
+			wantSegments: 1,
+		},
+		{
+			name: "otro modelo sin clave", model: "gpt-4o-transcribe", language: "auto",
+			response: `{"text":"hola mundo"}`,
+			want:     map[string]string{"model": "gpt-4o-transcribe", "response_format": "json"},
+		},
+	}
+	for _, tt := range tests {
+		url, last := newTranscriptionServer(t, http.StatusOK, tt.response)
+		client, err := NewOpenAIClient(url+"/v1", tt.apiKey, tt.model, tt.language)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := client.Transcribe(context.Background(), Audio{WAV: testWAV})
+		if err != nil {
+			t.Fatalf("%s: Transcribe() = %v", tt.name, err)
+		}
+		req := last()
+		if req.path != "/v1/audio/transcriptions" {
+			t.Errorf("%s: ruta = %q, se esperaba /v1/audio/transcriptions", tt.name, req.path)
+		}
+		if req.auth != tt.wantAuth {
+			t.Errorf("%s: Authorization = %q, se esperaba %q", tt.name, req.auth, tt.wantAuth)
+		}
+		if string(req.file) != string(testWAV) {
+			t.Errorf("%s: audio enviado = %q", tt.name, req.file)
+		}
+		checkFields(t, tt.name, req.fields, tt.want)
+		if result.Text != "hola mundo" || len(result.Segments) != tt.wantSegments {
+			t.Errorf("%s: resultado = %+v", tt.name, result)
+		}
+	}
+}
+
+func TestTranscribeHTTPErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"error del servidor", http.StatusInternalServerError, "modelo no cargado\n", "500 Internal Server Error: modelo no cargado"},
+		{"clave rechazada", http.StatusUnauthorized, `{"error":"invalid api key"}`, "401 Unauthorized"},
+		{"respuesta inválida", http.StatusOK, "<html>", "respuesta inválida"},
+	}
+	for _, tt := range tests {
+		url, _ := newTranscriptionServer(t, tt.status, tt.body)
+		server, _ := NewServerClient(url, "auto")
+		openai, _ := NewOpenAIClient(url, "", "", "auto")
+		for backend, transcriber := range map[string]Transcriber{"servidor": server, "openai": openai} {
+			_, err := transcriber.Transcribe(context.Background(), Audio{WAV: testWAV})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s (%s): Transcribe() = %v, se esperaba un error con %q", tt.name, backend, err, tt.wantErr)
+			}
+		}
+	}
+
+	// Un audio vacío no llega a enviarse.
+	url, last := newTranscriptionServer(t, http.StatusOK, verboseResponse)
+	server, _ := NewServerClient(url, "auto")
+	if _, err := server.Transcribe(context.Background(), Audio{}); err == nil || last().path != "" {
+		t.Errorf("Transcribe(Audio{}) = %v, se esperaba un error sin petición", err)
+	}
+}
@@ -0,0 +1,479 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// serverStartTimeout es cuánto se espera a que el servidor cargue el modelo.
+	serverStartTimeout = 60 * time.Second
+	// serverStartPollInterval es cada cuánto se pregunta al servidor si ya arrancó.
+	serverStartPollInterval = 200 * time.Millisecond
+	// serverHealthInterval es cada cuánto se comprueba que el servidor sigue respondiendo.
+	serverHealthInterval = 30 * time.Second
+	// serverMaxHealthFailures es cuántas comprobaciones seguidas pueden fallar antes de
+	// reiniciar el servidor.
+	serverMaxHealthFailures = 3
+	// serverStopTimeout es cuánto se espera a que el servidor termine antes de matarlo.
+	serverStopTimeout = 5 * time.Second
+	// serverMaxRestarts es cuántas veces seguidas se reinicia un servidor que se cae antes
+	// de esperar a la siguiente transcripción para volver a intentarlo.
+	serverMaxRestarts = 5
+	// serverRestartDelay es la espera antes del primer reinicio; se duplica en cada intento.
+	serverRestartDelay = time.Second
+)
+
+// serverTimings son las esperas del servidor administrado. Las pruebas las acortan.
+type serverTimings struct {
+	start        time.Duration // Ver serverStartTimeout.
+	startPoll    time.Duration // Ver serverStartPollInterval.
+	health       time.Duration // Ver serverHealthInterval.
+	stop         time.Duration // Ver serverStopTimeout.
+	restartDelay time.Duration // Ver serverRestartDelay.
+}
+
+var defaultServerTimings = serverTimings{
+	start:        serverStartTimeout,
+	startPoll:    serverStartPollInterval,
+	health:       serverHealthInterval,
+	stop:         serverStopTimeout,
+	restartDelay: serverRestartDelay,
+}
+
+// ServerBinaryName es el nombre del binario del servidor de whisper.cpp.
+var ServerBinaryName = func() string {
+	if runtime.GOOS == "windows" {
+		return "whisper-server.exe"
+	}
+	return "whisper-server"
+}()
+
+// serverProcess es un servidor en marcha.
+type serverProcess interface {
+	// Wait bloquea hasta que el proceso termina.
+	Wait() error
+	// Stop pide al proceso que termine; Kill lo fuerza.
+	Stop() error
+	Kill() error
+}
+
+// launchFunc arranca un servidor que escuche en addr ("127.0.0.1:puerto").
+type launchFunc func(addr string) (serverProcess, error)
+
+// ManagedServer mantiene un servidor de whisper.cpp como proceso hijo para no volver a
+// cargar el modelo en cada transcripción. El servidor se arranca con la primera
+// transcripción, se vigila periódicamente, se reinicia si se cae y se detiene con Close.
+type ManagedServer struct {
+	language string
+	options  WhisperOptions
+	launch   launchFunc
+	timings  serverTimings
+
+	mu      sync.Mutex
+	proc    serverProcess
+	baseURL string
+	exited  chan struct{} // Se cierra cuando termina el proceso actual.
+	closed  bool
+
+	stop     chan struct{} // Se cierra en Close para detener la vigilancia y los arranques.
+	stopOnce sync.Once
+
+	restarts int // Reinicios seguidos tras caídas.
+}
+
+// NewManagedServer crea un servidor administrado que ejecuta binaryPath con el modelo
+// modelPath y las opciones indicadas. No arranca hasta la primera transcripción.
+func NewManagedServer(binaryPath, modelPath, lang string, options WhisperOptions) *ManagedServer {
+	options = options.withDefaults()
+	m := newManagedServer(lang, execLauncher(binaryPath, modelPath, lang, options.args()))
+	m.options = options
+	return m
+}
+
+func newManagedServer(lang string, launch launchFunc) *ManagedServer {
+	return &ManagedServer{language: lang, options: DefaultWhisperOptions(), launch: launch, timings: defaultServerTimings, stop: make(chan struct{})}
+}
+
+// Transcribe arranca el servidor si hace falta y le envía el audio.
+func (m *ManagedServer) Transcribe(ctx context.Context, audio Audio) (TranscriptionResult, error) {
+	baseURL, err := m.ensureRunning(ctx)
+	if err != nil {
+		return TranscriptionResult{}, err
+	}
+	m.resetRestarts()
+	client, err := NewServerClient(baseURL, m.language)
+	if err != nil {
+		return TranscriptionResult{}, err
+	}
+	client.options = m.options
+	return client.Transcribe(ctx, audio)
+}
+
+// Start arranca el servidor en segundo plano (para tener el modelo cargado antes de la
+// primera transcripción).
+func (m *ManagedServer) Start(ctx context.Context) error {
+	if _, err := m.ensureRunning(ctx); err != nil {
+		return err
+	}
+	m.resetRestarts()
+	return nil
+}
+
+// resetRestarts vuelve a permitir reinicios automáticos una vez que el servidor funciona.
+func (m *ManagedServer) resetRestarts() {
+	m.mu.Lock()
+	m.restarts = 0
+	m.mu.Unlock()
+}
+
+// ensureRunning devuelve la URL del servidor, arrancándolo y esperando a que cargue el
+// modelo si no está en marcha.
+func (m *ManagedServer) ensureRunning(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return "", errors.New("el servidor de whisper está detenido")
+	}
+	if m.proc != nil {
+		select {
+		case <-m.exited:
+			// Se cayó y todavía no se reinició.
+		default:
+			return m.baseURL, nil
+		}
+	}
+
+	if err := m.startLocked(); err != nil {
+		return "", err
+	}
+	if err := m.waitHealthyLocked(ctx); err != nil {
+		m.killLocked()
+		return "", err
+	}
+	return m.baseURL, nil
+}
+
+// startLocked lanza un proceso nuevo en un puerto libre y empieza a vigilarlo.
+func (m *ManagedServer) startLocked() error {
+	addr, err := freeLocalAddr()
+	if err != nil {
+		return err
+	}
+	proc, err := m.launch(addr)
+	if err != nil {
+		return fmt.Errorf("no se pudo arrancar el servidor de whisper: %w", err)
+	}
+
+	exited := make(chan struct{})
+	m.proc, m.baseURL, m.exited = proc, "http://"+addr, exited
+	fmt.Printf("🧠 Servidor de whisper arrancando en %s...\n", m.baseURL)
+
+	go m.watch(proc, exited)
+	return nil
+}
+
+// waitHealthyLocked espera a que el servidor responda a /health.
+func (m *ManagedServer) waitHealthyLocked(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timings.start)
+	defer cancel()
+
+	ticker := time.NewTicker(m.timings.startPoll)
+	defer ticker.Stop()
+	for {
+		if healthy(ctx, m.baseURL) {
+			fmt.Println("🧠 Servidor de whisper listo.")
+			return nil
+		}
+		select {
+		case <-m.exited:
+			return errors.New("el servidor de whisper terminó al arrancar (¿modelo o binario inválidos?)")
+		case <-m.stop:
+			return errors.New("el servidor de whisper está detenido")
+		case <-ctx.Done():
+			return fmt.Errorf("el servidor de whisper no respondió: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// watch espera a que termine el proceso y lo comprueba periódicamente mientras tanto. Si
+// deja de responder se mata; si termina sin que se haya pedido, se reinicia.
+func (m *ManagedServer) watch(proc serverProcess, exited chan struct{}) {
+	go func() {
+		err := proc.Wait()
+		close(exited)
+
+		m.mu.Lock()
+		closing := m.closed || m.proc != proc
+		m.mu.Unlock()
+		if !closing {
+			fmt.Printf("⚠️ El servidor de whisper terminó inesperadamente: %v\n", err)
+			m.restartAfterCrash()
+		}
+	}()
+
+	ticker := time.NewTicker(m.timings.health)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-exited:
+			return
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.Lock()
+		baseURL, current := m.baseURL, m.proc == proc
+		m.mu.Unlock()
+		if !current {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ok := healthy(ctx, baseURL)
+		cancel()
+		if ok {
+			failures = 0
+			m.resetRestarts()
+			continue
+		}
+		failures++
+		if failures >= serverMaxHealthFailures {
+			fmt.Println("⚠️ El servidor de whisper no responde; se reiniciará.")
+			proc.Kill() // Al terminar, la rutina de Wait lo reinicia.
+			return
+		}
+	}
+}
+
+// restartAfterCrash vuelve a arrancar el servidor con una espera creciente entre
+// intentos. Tras serverMaxRestarts intentos seguidos se deja de intentar hasta la
+// próxima transcripción.
+func (m *ManagedServer) restartAfterCrash() {
+	for {
+		m.mu.Lock()
+		m.restarts++
+		attempt := m.restarts
+		m.mu.Unlock()
+		if attempt > serverMaxRestarts {
+			fmt.Println("⚠️ El servidor de whisper se cayó demasiadas veces; se reintentará con la próxima transcripción.")
+			return
+		}
+
+		delay := time.Duration(1<<(attempt-1)) * m.timings.restartDelay
+		select {
+		case <-time.After(delay):
+		case <-m.stop:
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), m.timings.start)
+		_, err := m.ensureRunning(ctx)
+		cancel()
+		if err == nil {
+			return
+		}
+		fmt.Printf("❌ Error reiniciando el servidor de whisper: %v\n", err)
+	}
+}
+
+// Close detiene el servidor: primero se le pide que termine y, si no lo hace a tiempo,
+// se mata.
+func (m *ManagedServer) Close() error {
+	// Cerrar stop antes de tomar el candado interrumpe un arranque en curso.
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	if m.proc == nil {
+		return nil
+	}
+
+	m.proc.Stop()
+	select {
+	case <-m.exited:
+	case <-time.After(m.timings.stop):
+		m.killLocked()
+	}
+	m.proc = nil
+	fmt.Println("🧠 Servidor de whisper detenido.")
+	return nil
+}
+
+// killLocked mata el proceso actual y espera a que termine.
+func (m *ManagedServer) killLocked() {
+	if m.proc == nil {
+		return
+	}
+	m.proc.Kill()
+	select {
+	case <-m.exited:
+	case <-time.After(m.timings.stop):
+	}
+	m.proc = nil
+}
+
+// healthy indica si el servidor responde. Las versiones de whisper.cpp sin /health
+// responden 404, lo que también demuestra que ya escucha; 503 significa que aún carga el modelo.
+func healthy(ctx context.Context, baseURL string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/health", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound
+}
+
+// freeLocalAddr devuelve una dirección de localhost con un puerto libre.
+func freeLocalAddr() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
+
+// execProcess es un servidor lanzado como proceso hijo.
+type execProcess struct {
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+}
+
+func (p *execProcess) Wait() error {
+	if err := p.cmd.Wait(); err != nil {
+		return fmt.Errorf("%w: %s", err, lastLines(p.stderr.String(), 5))
+	}
+	return nil
+}
+
+func (p *execProcess) Stop() error { return interruptProcess(p.cmd) }
+func (p *execProcess) Kill() error { return p.cmd.Process.Kill() }
+
+// execLauncher arranca el binario whisper-server de whisper.cpp.
+func execLauncher(binaryPath, modelPath, lang string, extraArgs []string) launchFunc {
+	return func(addr string) (serverProcess, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if lang == "" {
+			lang = "auto"
+		}
+		args := []string{"-m", modelPath, "--host", host, "--port", port, "-l", lang}
+		args = append(args, extraArgs...)
+
+		cmd := exec.Command(binaryPath, args...)
+		hideConsole(cmd)
+		stderr := &bytes.Buffer{}
+		cmd.Stderr = &limitedWriter{buf: stderr, max: 64 * 1024}
+		if err := cmd.Start(); err != nil {
+			return nil, err
+		}
+		return &execProcess{cmd: cmd, stderr: stderr}, nil
+	}
+}
+
+// limitedWriter guarda como mucho max bytes (el servidor escribe mucho en stderr).
+type limitedWriter struct {
+	mu  sync.Mutex
+	buf *bytes.Buffer
+	max int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len()+len(p) > w.max {
+		// Conservar solo el final, que es donde aparecen los errores.
+		tail := append(w.buf.Bytes()[max(0, w.buf.Len()+len(p)-w.max):], p...)
+		w.buf.Reset()
+		w.buf.Write(tail[max(0, len(tail)-w.max):])
+		return len(p), nil
+	}
+	return w.buf.Write(p)
+}
+
+// lastLines devuelve las últimas n líneas de un texto.
+func lastLines(text string, n int) string {
+	lines := bytes.Split(bytes.TrimSpace([]byte(text)), []byte("\n"))
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return string(bytes.Join(lines, []byte(" | ")))
+}
+
+var (
+	sharedMu     sync.Mutex
+	sharedServer *ManagedServer
+	sharedKey    string
+)
+
+// managedServer devuelve el servidor administrado compartido por todas las
+// transcripciones. Si cambia el idioma, alguna opción o el binario o modelo, el servidor
+// anterior se detiene y se crea uno nuevo.
+func managedServer(cfg Config) (*ManagedServer, error) {
+	binaryDirs, modelDirs, err := searchDirs()
+	if err != nil {
+		return nil, err
+	}
+	binaryPath := findFile(ServerBinaryName, binaryDirs)
+	if _, err := os.Stat(binaryPath); err != nil {
+		p, err := exec.LookPath(binaryPath)
+		if err != nil {
+			return nil, fmt.Errorf("binario del servidor de whisper no encontrado: %s", binaryPath)
+		}
+		binaryPath = p
+	}
+	modelPath := modelPath(cfg.Whisper, modelDirs)
+	if _, err := os.Stat(modelPath); err != nil {
+		return nil, fmt.Errorf("modelo de whisper no encontrado: %s", modelPath)
+	}
+
+	// Cambiar cualquier opción de arranque obliga a reiniciar el servidor.
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%+v", binaryPath, modelPath, cfg.Language, cfg.Whisper.withDefaults())
+
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if sharedServer != nil && sharedKey == key {
+		return sharedServer, nil
+	}
+	if sharedServer != nil {
+		go sharedServer.Close()
+	}
+	sharedServer = NewManagedServer(binaryPath, modelPath, cfg.Language, cfg.Whisper)
+	sharedKey = key
+	return sharedServer, nil
+}
+
+// StopManagedServer detiene el servidor administrado, si hay uno en marcha. Se llama al
+// cerrar la aplicación para no dejar el proceso huérfano.
+func StopManagedServer() {
+	sharedMu.Lock()
+	server := sharedServer
+	sharedServer, sharedKey = nil, ""
+	sharedMu.Unlock()
+	if server != nil {
+		server.Close()
+	}
+}
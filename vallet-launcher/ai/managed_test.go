@@ -0,0 +1,250 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTimings acorta las esperas del servidor administrado para las pruebas.
+var testTimings = serverTimings{
+	start:        2 * time.Second,
+	startPoll:    10 * time.Millisecond,
+	health:       20 * time.Millisecond,
+	stop:         time.Second,
+	restartDelay: 10 * time.Millisecond,
+}
+
+// fakeProcess es un "whisper-server" hecho con httptest que escucha en la dirección que
+// elige el ManagedServer.
+type fakeProcess struct {
+	srv   *httptest.Server
+	done  chan struct{}
+	once  sync.Once
+	stops atomic.Int32
+	kills atomic.Int32
+}
+
+// exit simula que el proceso termina.
+func (p *fakeProcess) exit() {
+	p.once.Do(func() {
+		p.srv.Close()
+		close(p.done)
+	})
+}
+
+func (p *fakeProcess) Wait() error {
+	<-p.done
+	return errors.New("proceso terminado")
+}
+
+func (p *fakeProcess) Stop() error {
+	p.stops.Add(1)
+	p.exit()
+	return nil
+}
+
+func (p *fakeProcess) Kill() error {
+	p.kills.Add(1)
+	p.exit()
+	return nil
+}
+
+// fakeLauncher arranca fakeProcess cuyo /health responde lo que indique health (por
+// defecto 200) y cuyo /inference devuelve siempre "hola".
+type fakeLauncher struct {
+	health       atomic.Int32 // Código de estado de /health (0 = 200).
+	healthChecks atomic.Int32 // Peticiones a /health recibidas.
+
+	mu    sync.Mutex
+	procs []*fakeProcess
+}
+
+func (l *fakeLauncher) launch(addr string) (serverProcess, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		l.healthChecks.Add(1)
+		if status := l.health.Load(); status != 0 {
+			w.WriteHeader(int(status))
+		}
+	})
+	mux.HandleFunc("/inference", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":" hola","language":"spanish"}`))
+	})
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Listener.Close()
+	srv.Listener = listener
+	srv.Start()
+
+	proc := &fakeProcess{srv: srv, done: make(chan struct{})}
+	l.mu.Lock()
+	l.procs = append(l.procs, proc)
+	l.mu.Unlock()
+	return proc, nil
+}
+
+// launched devuelve los procesos arrancados hasta ahora.
+func (l *fakeLauncher) launched() []*fakeProcess {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*fakeProcess(nil), l.procs...)
+}
+
+func newTestManagedServer(t *testing.T, l *fakeLauncher) *ManagedServer {
+	t.Helper()
+	m := newManagedServer("es", l.launch)
+	m.timings = testTimings
+	t.Cleanup(func() { m.Close() })
+	return m
+}
+
+// waitFor espera a que cond se cumpla o falla la prueba.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("tiempo agotado esperando: %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestManagedServerStartsOnFirstTranscribe(t *testing.T) {
+	l := &fakeLauncher{}
+	m := newTestManagedServer(t, l)
+	if n := len(l.launched()); n != 0 {
+		t.Fatalf("procesos arrancados antes de transcribir = %d", n)
+	}
+
+	for range 2 {
+		result, err := m.Transcribe(context.Background(), Audio{WAV: []byte("RIFF")})
+		if err != nil {
+			t.Fatalf("Transcribe: %v", err)
+		}
+		if result.Text != "hola" || result.Language != "es" {
+			t.Errorf("resultado = %+v", result)
+		}
+	}
+	if n := len(l.launched()); n != 1 {
+		t.Errorf("procesos arrancados = %d, se esperaba 1", n)
+	}
+
+	m.Close()
+	if proc := l.launched()[0]; proc.stops.Load() != 1 {
+		t.Errorf("Close no detuvo el proceso (stops = %d)", proc.stops.Load())
+	}
+	if _, err := m.Transcribe(context.Background(), Audio{WAV: []byte("RIFF")}); err == nil {
+		t.Error("Transcribe funcionó después de Close")
+	}
+}
+
+func TestManagedServerWaitsForHealth(t *testing.T) {
+	l := &fakeLauncher{}
+	l.health.Store(http.StatusServiceUnavailable) // Cargando el modelo.
+	m := newTestManagedServer(t, l)
+
+	go func() {
+		for l.healthChecks.Load() < 2 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		l.health.Store(http.StatusOK)
+	}()
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if n := l.healthChecks.Load(); n < 3 {
+		t.Errorf("comprobaciones de /health = %d, se esperaban al menos 3", n)
+	}
+}
+
+func TestManagedServerStartTimeout(t *testing.T) {
+	l := &fakeLauncher{}
+	l.health.Store(http.StatusServiceUnavailable)
+	m := newTestManagedServer(t, l)
+	m.timings.start = 100 * time.Millisecond
+
+	if err := m.Start(context.Background()); err == nil {
+		t.Fatal("Start no falló con un servidor que nunca está listo")
+	}
+	if proc := l.launched()[0]; proc.kills.Load() != 1 {
+		t.Errorf("el proceso que no arrancó no se mató (kills = %d)", proc.kills.Load())
+	}
+}
+
+func TestManagedServerRestartsAfterExit(t *testing.T) {
+	l := &fakeLauncher{}
+	m := newTestManagedServer(t, l)
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	l.launched()[0].exit() // Se cae sin que se lo pidan.
+	waitFor(t, "el reinicio", func() bool { return len(l.launched()) == 2 })
+
+	if _, err := m.Transcribe(context.Background(), Audio{WAV: []byte("RIFF")}); err != nil {
+		t.Fatalf("Transcribe tras el reinicio: %v", err)
+	}
+	if n := len(l.launched()); n != 2 {
+		t.Errorf("procesos arrancados = %d, se esperaban 2", n)
+	}
+}
+
+func TestManagedServerKillsUnresponsive(t *testing.T) {
+	l := &fakeLauncher{}
+	m := newTestManagedServer(t, l)
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	first := l.launched()[0]
+
+	before := l.healthChecks.Load()
+	l.health.Store(http.StatusInternalServerError)
+	waitFor(t, "que se mate el servidor", func() bool { return first.kills.Load() == 1 })
+	if failed := l.healthChecks.Load() - before; failed < serverMaxHealthFailures {
+		t.Errorf("se mató tras %d comprobaciones fallidas, se esperaban %d", failed, serverMaxHealthFailures)
+	}
+
+	// Vuelve a responder: el reinicio arranca un proceso nuevo.
+	l.health.Store(http.StatusOK)
+	waitFor(t, "el reinicio", func() bool { return len(l.launched()) == 2 })
+	if _, err := m.Transcribe(context.Background(), Audio{WAV: []byte("RIFF")}); err != nil {
+		t.Fatalf("Transcribe tras el reinicio: %v", err)
+	}
+}
+
+func TestManagedServerCloseInterruptsStart(t *testing.T) {
+	l := &fakeLauncher{}
+	l.health.Store(http.StatusServiceUnavailable)
+	m := newTestManagedServer(t, l)
+	m.timings.start = time.Minute
+
+	errc := make(chan error, 1)
+	go func() { errc <- m.Start(context.Background()) }()
+	waitFor(t, "el arranque", func() bool { return l.healthChecks.Load() > 0 })
+
+	m.Close()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Error("Start no falló al cerrar el servidor")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close no interrumpió el arranque")
+	}
+	if proc := l.launched()[0]; proc.kills.Load() != 1 {
+		t.Errorf("el proceso a medio arrancar no se mató (kills = %d)", proc.kills.Load())
+	}
+}
@@ -0,0 +1,434 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultModelSource es la URL base de la que se descargan los modelos si no se configura
+// otra (ej: un espejo interno).
+const DefaultModelSource = "https://huggingface.co/ggerganov/whisper.cpp/resolve/main"
+
+// progressInterval limita cada cuánto se informa del progreso de una descarga.
+const progressInterval = 250 * time.Millisecond
+
+// ModelInfo describe un modelo que se puede descargar.
+type ModelInfo struct {
+	Name        string `json:"name"`        // Nombre corto (ej: "small").
+	File        string `json:"file"`        // Nombre del archivo (ej: "ggml-small.bin").
+	SizeMB      int    `json:"sizeMB"`      // Tamaño aproximado, para mostrarlo antes de descargar.
+	SHA256      string `json:"sha256"`      // Suma SHA256 en hexadecimal ("" si no se conoce).
+	Description string `json:"description"` // Descripción breve.
+}
+
+// Manifest es la lista de modelos descargables con sus sumas de comprobación.
+//
+// Formato JSON:
+//
+//	{"models": [{"name": "small", "file": "ggml-small.bin", "sizeMB": 466, "sha256": "..."}]}
+type Manifest struct {
+	Models []ModelInfo `json:"models"`
+}
+
+// defaultManifest son los modelos de whisper.cpp publicados en Hugging Face, con la suma
+// SHA256 que Hugging Face publica para cada archivo (el "oid" de Git LFS).
+var defaultManifest = Manifest{Models: []ModelInfo{
+	{Name: "tiny", File: "ggml-tiny.bin", SizeMB: 75, SHA256: "be07e048e1e599ad46341c8d2a135645097a538221678b7acdd1b1919c6e1b21", Description: "El más rápido; precisión baja."},
+	{Name: "base", File: "ggml-base.bin", SizeMB: 142, SHA256: "60ed5bc3dd14eea856493d334349b405782ddcaf0028d4b5df4088345fba2efe", Description: "Rápido; precisión aceptable."},
+	{Name: "small", File: "ggml-small.bin", SizeMB: 466, SHA256: "1be3a9b2063867b937e64e2ec7483364a79917e157fa98c5d94b5c1fffea987b", Description: "Recomendado: buen equilibrio entre velocidad y precisión."},
+	{Name: "medium", File: "ggml-medium.bin", SizeMB: 1500, SHA256: "6c14d5adee5f86394037b4e4e8b59f1673b6cee10e3cf0b11bbdbee79c156208", Description: "Más preciso; lento sin GPU."},
+	{Name: "large-v3-turbo-q5_0", File: "ggml-large-v3-turbo-q5_0.bin", SizeMB: 547, SHA256: "394221709cd5ad1f40c46e6031ca61bce88931e6e088c188294c6d5a55ffa7e2", Description: "Large v3 turbo cuantizado; muy preciso con poca memoria."},
+	{Name: "large-v3-turbo", File: "ggml-large-v3-turbo.bin", SizeMB: 1500, SHA256: "1fc70f774d38eb169993ac391eea357ef47c88757ef72ee5943879b7e8e2bc69", Description: "Large v3 turbo; muy preciso, recomendado con GPU."},
+	{Name: "large-v3", File: "ggml-large-v3.bin", SizeMB: 2900, SHA256: "64d182b440b98d5203c4f9bd541544d84c605196c4f7b845dfa11fb23594d1e2", Description: "El más preciso; necesita GPU."},
+}}
+
+// ErrUnverified indica que el manifiesto no tiene la suma SHA256 de un modelo, de modo que
+// no se puede comprobar que el archivo sea el publicado.
+var ErrUnverified = errors.New("el manifiesto no tiene la suma SHA256 del modelo")
+
+// checksumSuffix es la extensión del archivo que recuerda que un modelo ya se verificó.
+const checksumSuffix = ".sha256"
+
+// DefaultManifest devuelve la lista de modelos incluida en la aplicación.
+func DefaultManifest() Manifest {
+	return Manifest{Models: append([]ModelInfo(nil), defaultManifest.Models...)}
+}
+
+// ParseManifest lee un manifiesto en JSON y comprueba que cada modelo tenga nombre,
+// archivo .bin y, si la incluye, una suma SHA256 válida.
+func ParseManifest(data []byte) (Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("manifiesto de modelos inválido: %w", err)
+	}
+	for i, model := range m.Models {
+		if model.Name == "" || !isModelFile(model.File) {
+			return Manifest{}, fmt.Errorf("manifiesto de modelos: entrada %d sin nombre o archivo ggml-*.bin", i+1)
+		}
+		if filepath.Base(model.File) != model.File {
+			return Manifest{}, fmt.Errorf("manifiesto de modelos: archivo inválido %q", model.File)
+		}
+		if model.SHA256 != "" {
+			if sum, err := hex.DecodeString(model.SHA256); err != nil || len(sum) != sha256.Size {
+				return Manifest{}, fmt.Errorf("manifiesto de modelos: SHA256 inválido para %s", model.Name)
+			}
+			m.Models[i].SHA256 = strings.ToLower(model.SHA256)
+		}
+	}
+	return m, nil
+}
+
+// FetchManifest descarga y lee el manifiesto publicado en url.
+func FetchManifest(ctx context.Context, url string) (Manifest, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Manifest{}, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Manifest{}, fmt.Errorf("%s respondió %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Manifest{}, err
+	}
+	return ParseManifest(data)
+}
+
+// Find busca un modelo por nombre.
+func (m Manifest) Find(name string) (ModelInfo, bool) {
+	for _, model := range m.Models {
+		if model.Name == name {
+			return model, true
+		}
+	}
+	return ModelInfo{}, false
+}
+
+// InstalledModel es un modelo presente en la carpeta de modelos.
+type InstalledModel struct {
+	Name string `json:"name"` // Nombre corto, sacado del archivo ("ggml-small.bin" -> "small").
+	File string `json:"file"` // Nombre del archivo.
+	Path string `json:"path"` // Ruta completa.
+	Size int64  `json:"size"` // Tamaño en bytes.
+}
+
+// DownloadProgress informa del avance de una descarga.
+type DownloadProgress struct {
+	Name       string `json:"name"`       // Modelo que se descarga.
+	Downloaded int64  `json:"downloaded"` // Bytes descargados, incluidos los de descargas anteriores.
+	Total      int64  `json:"total"`      // Tamaño total en bytes (0 si el servidor no lo indica).
+	Verifying  bool   `json:"verifying"`  // La descarga terminó y se está comprobando la suma.
+}
+
+// ModelManager administra los modelos ggml de una carpeta: los lista, los descarga
+// (reanudando descargas interrumpidas), verifica su SHA256 y los borra.
+type ModelManager struct {
+	Dir      string       // Carpeta de los modelos (ej: ~/.vallet-os/models).
+	Source   string       // URL base de descarga; el modelo se pide en Source + "/" + File.
+	Manifest Manifest     // Modelos descargables.
+	Client   *http.Client // Cliente HTTP (sin tiempo límite: los modelos pesan gigas).
+}
+
+// DefaultModelsDir devuelve ~/.vallet-os/models.
+func DefaultModelsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".vallet-os", "models"), nil
+}
+
+// NewModelManager crea un administrador para dir que descarga de source ("" para
+// DefaultModelSource).
+func NewModelManager(dir, source string, manifest Manifest) *ModelManager {
+	source = strings.TrimRight(strings.TrimSpace(source), "/")
+	if source == "" {
+		source = DefaultModelSource
+	}
+	return &ModelManager{Dir: dir, Source: source, Manifest: manifest, Client: &http.Client{}}
+}
+
+// isModelFile indica si name parece un modelo de whisper.cpp.
+func isModelFile(name string) bool {
+	return strings.HasPrefix(name, "ggml-") && strings.HasSuffix(name, ".bin")
+}
+
+// modelName devuelve el nombre corto de un archivo de modelo.
+func modelName(file string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(file, "ggml-"), ".bin")
+}
+
+// Installed devuelve los modelos de la carpeta, ordenados por nombre. Las descargas a medias
+// (.part) no se incluyen.
+func (m *ModelManager) Installed() ([]InstalledModel, error) {
+	entries, err := os.ReadDir(m.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var models []InstalledModel
+	for _, entry := range entries {
+		if entry.IsDir() || !isModelFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		models = append(models, InstalledModel{
+			Name: modelName(entry.Name()),
+			File: entry.Name(),
+			Path: filepath.Join(m.Dir, entry.Name()),
+			Size: info.Size(),
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+// FirstVerified devuelve el primer modelo instalado cuyo SHA256 coincide con el manifiesto.
+// Los modelos sin suma o que no la superan se saltan.
+func (m *ModelManager) FirstVerified() (InstalledModel, bool) {
+	installed, _ := m.Installed()
+	for _, model := range installed {
+		if m.Verify(model.Name) == nil {
+			return model, true
+		}
+	}
+	return InstalledModel{}, false
+}
+
+// Path devuelve la ruta que tiene (o tendría) el modelo name en la carpeta. El nombre debe
+// estar en el manifiesto o ser el de un modelo instalado: nunca se construye una ruta a
+// partir de lo que llega del frontend.
+func (m *ModelManager) Path(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("nombre de modelo inválido: %q", name)
+	}
+	if model, ok := m.Manifest.Find(name); ok {
+		return filepath.Join(m.Dir, model.File), nil
+	}
+	installed, err := m.Installed()
+	if err != nil {
+		return "", err
+	}
+	for _, model := range installed {
+		if model.Name == name {
+			return model.Path, nil
+		}
+	}
+	return "", fmt.Errorf("modelo desconocido: %q", name)
+}
+
+// Download descarga el modelo name del manifiesto y devuelve su ruta. Si quedó una
+// descarga a medias se reanuda desde donde se cortó. Al terminar se comprueba la suma
+// SHA256 (si el manifiesto la tiene) antes de dejar el archivo en su sitio. progress puede
+// ser nil.
+func (m *ModelManager) Download(ctx context.Context, name string, progress func(DownloadProgress)) (string, error) {
+	model, ok := m.Manifest.Find(name)
+	if !ok {
+		return "", fmt.Errorf("modelo desconocido: %q", name)
+	}
+	if progress == nil {
+		progress = func(DownloadProgress) {}
+	}
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(m.Dir, model.File)
+	partial := dest + ".part"
+	if err := m.fetch(ctx, model, partial, progress); err != nil {
+		return "", err
+	}
+
+	if model.SHA256 != "" {
+		progress(DownloadProgress{Name: name, Verifying: true})
+		if err := VerifyFile(partial, model.SHA256); err != nil {
+			// Un archivo corrupto no se puede reanudar: se descarta para empezar de cero.
+			os.Remove(partial)
+			return "", err
+		}
+	} else {
+		fmt.Printf("⚠️ El manifiesto no tiene SHA256 para %s; no se verifica la descarga.\n", model.File)
+	}
+
+	if err := os.Rename(partial, dest); err != nil {
+		return "", err
+	}
+	if model.SHA256 != "" {
+		rememberVerified(dest, model.SHA256)
+	} else {
+		os.Remove(dest + checksumSuffix)
+	}
+	return dest, nil
+}
+
+// Verify comprueba que el modelo instalado name sea el del manifiesto. El resultado se
+// guarda en un archivo .sha256 junto al modelo para no volver a leer gigas en cada
+// activación; si el modelo cambia después, se vuelve a calcular. Devuelve un error que
+// envuelve ErrUnverified si el manifiesto no tiene la suma del modelo.
+func (m *ModelManager) Verify(name string) error {
+	model, ok := m.Manifest.Find(name)
+	if !ok || model.SHA256 == "" {
+		return fmt.Errorf("%s: %w", name, ErrUnverified)
+	}
+	path := filepath.Join(m.Dir, model.File)
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("el modelo %s no está instalado", name)
+	}
+
+	marker := path + checksumSuffix
+	if data, err := os.ReadFile(marker); err == nil && strings.EqualFold(strings.TrimSpace(string(data)), model.SHA256) {
+		if markerInfo, err := os.Stat(marker); err == nil && !markerInfo.ModTime().Before(info.ModTime()) {
+			return nil
+		}
+	}
+
+	if err := VerifyFile(path, model.SHA256); err != nil {
+		os.Remove(marker)
+		return err
+	}
+	rememberVerified(path, model.SHA256)
+	return nil
+}
+
+// rememberVerified guarda junto al modelo la suma con la que se verificó. Si no se puede
+// escribir, la próxima activación vuelve a calcularla.
+func rememberVerified(path, sum string) {
+	_ = os.WriteFile(path+checksumSuffix, []byte(strings.ToLower(sum)+"\n"), 0644)
+}
+
+// fetch descarga el modelo en partial, continuando lo que ya haya en el archivo.
+func (m *ModelManager) fetch(ctx context.Context, model ModelInfo, partial string, progress func(DownloadProgress)) error {
+	var offset int64
+	if info, err := os.Stat(partial); err == nil {
+		offset = info.Size()
+	}
+
+	url := m.Source + "/" + model.File
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error descargando %s: %w", model.File, err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		// El servidor no admite rangos (o no había nada descargado): empezar de cero.
+		offset = 0
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// Lo descargado ya es el archivo completo.
+		return nil
+	default:
+		return fmt.Errorf("%s respondió %s", url, resp.Status)
+	}
+
+	total := int64(0)
+	if resp.ContentLength > 0 {
+		total = offset + resp.ContentLength
+	}
+
+	f, err := os.OpenFile(partial, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	downloaded := offset
+	last := time.Time{}
+	buf := make([]byte, 256*1024)
+	for {
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := f.Write(buf[:n]); err != nil {
+				return err
+			}
+			downloaded += int64(n)
+			if time.Since(last) >= progressInterval {
+				last = time.Now()
+				progress(DownloadProgress{Name: model.Name, Downloaded: downloaded, Total: total})
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("descarga de %s interrumpida (se reanudará): %w", model.File, readErr)
+		}
+	}
+	if total > 0 && downloaded != total {
+		return fmt.Errorf("descarga de %s incompleta: %d de %d bytes", model.File, downloaded, total)
+	}
+	progress(DownloadProgress{Name: model.Name, Downloaded: downloaded, Total: total})
+	return f.Close()
+}
+
+// Delete borra el modelo name y cualquier descarga a medias.
+func (m *ModelManager) Delete(name string) error {
+	path, err := m.Path(name)
+	if err != nil {
+		return err
+	}
+	os.Remove(path + ".part")
+	os.Remove(path + checksumSuffix)
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("el modelo %s no está instalado", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// VerifyFile comprueba que la suma SHA256 del archivo sea want (en hexadecimal).
+func VerifyFile(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	got := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("el SHA256 de %s no coincide con el manifiesto (esperado %s, obtenido %s)", filepath.Base(path), want, got)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDefaultManifestHasChecksums(t *testing.T) {
+	data, err := json.Marshal(DefaultManifest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := ParseManifest(data)
+	if err != nil {
+		t.Fatalf("el manifiesto incluido no es válido: %v", err)
+	}
+	for _, model := range manifest.Models {
+		if model.SHA256 == "" {
+			t.Errorf("el modelo %s no tiene SHA256", model.Name)
+		}
+	}
+}
+
+// newVerifyManager crea un administrador con un modelo "tiny" instalado cuyo contenido es
+// content y cuya suma en el manifiesto es sum.
+func newVerifyManager(t *testing.T, content, sum string) *ModelManager {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ggml-tiny.bin"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := Manifest{Models: []ModelInfo{{Name: "tiny", File: "ggml-tiny.bin", SHA256: sum}}}
+	return NewModelManager(dir, "", manifest)
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestModelManagerVerify(t *testing.T) {
+	m := newVerifyManager(t, "modelo", sha256Hex("modelo"))
+	if err := m.Verify("tiny"); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.Dir, "ggml-tiny.bin"+checksumSuffix)); err != nil {
+		t.Errorf("no se guardó la verificación: %v", err)
+	}
+
+	// Si el modelo cambia después de verificarlo, se vuelve a comprobar.
+	path := filepath.Join(m.Dir, "ggml-tiny.bin")
+	os.WriteFile(path, []byte("otro"), 0644)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	if err := m.Verify("tiny"); err == nil || errors.Is(err, ErrUnverified) {
+		t.Errorf("Verify() = %v, se esperaba que la suma no coincidiera", err)
+	}
+}
+
+func TestModelManagerVerifyWithoutChecksum(t *testing.T) {
+	m := newVerifyManager(t, "modelo", "")
+	if err := m.Verify("tiny"); !errors.Is(err, ErrUnverified) {
+		t.Errorf("Verify() = %v, se esperaba ErrUnverified", err)
+	}
+	if err := m.Verify("copiado-a-mano"); !errors.Is(err, ErrUnverified) {
+		t.Errorf("Verify() de un modelo fuera del manifiesto = %v, se esperaba ErrUnverified", err)
+	}
+}
+
+func TestModelManagerFirstVerifiedSkipsUnverified(t *testing.T) {
+	m := newVerifyManager(t, "modelo", sha256Hex("otro"))
+	os.WriteFile(filepath.Join(m.Dir, "ggml-propio.bin"), []byte("modelo"), 0644)
+	if model, ok := m.FirstVerified(); ok {
+		t.Fatalf("FirstVerified() = %s, se esperaba ninguno", model.Name)
+	}
+
+	m.Manifest.Models = append(m.Manifest.Models, ModelInfo{Name: "propio", File: "ggml-propio.bin", SHA256: sha256Hex("modelo")})
+	if model, ok := m.FirstVerified(); !ok || model.Name != "propio" {
+		t.Errorf("FirstVerified() = %q, %v; se esperaba propio", model.Name, ok)
+	}
+}
+
+func TestModelManagerPathRejectsUnknownNames(t *testing.T) {
+	m := newVerifyManager(t, "modelo", "")
+	os.WriteFile(filepath.Join(m.Dir, "ggml-propio.bin"), []byte("modelo"), 0644)
+
+	for _, name := range []string{"tiny", "propio"} {
+		if _, err := m.Path(name); err != nil {
+			t.Errorf("Path(%q): %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../../secreto", `..\secreto`, "sub/modelo", "no-instalado"} {
+		if path, err := m.Path(name); err == nil {
+			t.Errorf("Path(%q) = %s, se esperaba un error", name, path)
+		}
+		if err := m.Delete(name); err == nil {
+			t.Errorf("Delete(%q) no devolvió error", name)
+		}
+	}
+}
+
+// modelContent es el contenido del modelo "tiny" que sirve newDownloadServer.
+var modelContent = strings.Repeat("ggml-tiny-", 1000)
+
+// newDownloadServer sirve modelContent en /ggml-tiny.bin. Si honorRange es false ignora
+// la cabecera Range y siempre responde 200 con el archivo completo. Devuelve el administrador
+// (con la suma de manifiesto sum) y una función con las cabeceras Range recibidas.
+func newDownloadServer(t *testing.T, honorRange bool, sum string) (*ModelManager, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ggml-tiny.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		if !honorRange {
+			r.Header.Del("Range")
+		}
+		http.ServeContent(w, r, "ggml-tiny.bin", time.Time{}, strings.NewReader(modelContent))
+	}))
+	t.Cleanup(srv.Close)
+
+	manifest := Manifest{Models: []ModelInfo{{Name: "tiny", File: "ggml-tiny.bin", SHA256: sum}}}
+	m := NewModelManager(t.TempDir(), srv.URL+"/", manifest)
+	return m, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), ranges...)
+	}
+}
+
+// checkInstalled comprueba que el modelo quedó completo en su sitio y sin descarga a medias.
+func checkInstalled(t *testing.T, m *ModelManager, path string) {
+	t.Helper()
+	if want := filepath.Join(m.Dir, "ggml-tiny.bin"); path != want {
+		t.Errorf("Download() = %s, se esperaba %s", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != modelContent {
+		t.Errorf("el modelo descargado tiene %d bytes distintos del original (%d)", len(data), len(modelContent))
+	}
+	if _, err := os.Stat(path + ".part"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("quedó la descarga a medias: %v", err)
+	}
+}
+
+func TestModelManagerDownload(t *testing.T) {
+	m, ranges := newDownloadServer(t, true, sha256Hex(modelContent))
+
+	var updates []DownloadProgress
+	path, err := m.Download(context.Background(), "tiny", func(p DownloadProgress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	checkInstalled(t, m, path)
+	if got := ranges(); len(got) != 1 || got[0] != "" {
+		t.Errorf("cabeceras Range = %q, se esperaba una petición sin Range", got)
+	}
+
+	if len(updates) < 2 {
+		t.Fatalf("progreso = %+v, se esperaban al menos la descarga y la verificación", updates)
+	}
+	total := int64(len(modelContent))
+	done := updates[len(updates)-2]
+	if done.Name != "tiny" || done.Downloaded != total || done.Total != total {
+		t.Errorf("progreso final = %+v, se esperaban %d de %d bytes", done, total, total)
+	}
+	if last := updates[len(updates)-1]; !last.Verifying {
+		t.Errorf("la última actualización debería indicar la verificación: %+v", last)
+	}
+	if err := m.Verify("tiny"); err != nil {
+		t.Errorf("Verify tras descargar: %v", err)
+	}
+}
+
+func TestModelManagerDownloadResumes(t *testing.T) {
+	m, ranges := newDownloadServer(t, true, sha256Hex(modelContent))
+	half := len(modelContent) / 2
+	partial := filepath.Join(m.Dir, "ggml-tiny.bin.part")
+	if err := os.WriteFile(partial, []byte(modelContent[:half]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var last DownloadProgress
+	path, err := m.Download(context.Background(), "tiny", func(p DownloadProgress) {
+		if !p.Verifying {
+			last = p
+		}
+	})
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	checkInstalled(t, m, path)
+	if got, want := ranges(), fmt.Sprintf("bytes=%d-", half); len(got) != 1 || got[0] != want {
+		t.Errorf("cabeceras Range = %q, se esperaba %q", got, want)
+	}
+	if total := int64(len(modelContent)); last.Downloaded != total || last.Total != total {
+		t.Errorf("progreso final = %+v, se esperaba contar lo ya descargado", last)
+	}
+}
+
+func TestModelManagerDownloadRestartsWithoutRangeSupport(t *testing.T) {
+	m, ranges := newDownloadServer(t, false, sha256Hex(modelContent))
+	partial := filepath.Join(m.Dir, "ggml-tiny.bin.part")
+	if err := os.WriteFile(partial, []byte("restos de otra descarga"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := m.Download(context.Background(), "tiny", nil)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	checkInstalled(t, m, path)
+	if got := ranges(); len(got) != 1 || got[0] == "" {
+		t.Errorf("cabeceras Range = %q, se esperaba que se pidiera continuar", got)
+	}
+}
+
+func TestModelManagerDownloadAlreadyComplete(t *testing.T) {
+	m, ranges := newDownloadServer(t, true, sha256Hex(modelContent))
+	partial := filepath.Join(m.Dir, "ggml-tiny.bin.part")
+	if err := os.WriteFile(partial, []byte(modelContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := m.Download(context.Background(), "tiny", nil)
+	if err != nil {
+		t.Fatalf("Download con la descarga ya completa (416): %v", err)
+	}
+	checkInstalled(t, m, path)
+	if got, want := ranges(), fmt.Sprintf("bytes=%d-", len(modelContent)); len(got) != 1 || got[0] != want {
+		t.Errorf("cabeceras Range = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestModelManagerDownloadChecksumMismatch(t *testing.T) {
+	m, _ := newDownloadServer(t, true, sha256Hex("otro modelo"))
+
+	if _, err := m.Download(context.Background(), "tiny", nil); err == nil {
+		t.Fatal("Download con una suma distinta no devolvió error")
+	}
+	for _, name := range []string{"ggml-tiny.bin", "ggml-tiny.bin.part", "ggml-tiny.bin" + checksumSuffix} {
+		if _, err := os.Stat(filepath.Join(m.Dir, name)); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s debería haberse borrado: %v", name, err)
+		}
+	}
+}
+
+func TestModelManagerDownloadErrors(t *testing.T) {
+	m, _ := newDownloadServer(t, true, "")
+	if _, err := m.Download(context.Background(), "no-existe", nil); err == nil {
+		t.Error("Download de un modelo fuera del manifiesto no devolvió error")
+	}
+
+	m.Manifest.Models[0].File = "ggml-otro.bin" // El servidor responde 404.
+	if _, err := m.Download(context.Background(), "tiny", nil); err == nil {
+		t.Error("Download con una respuesta 404 no devolvió error")
+	}
+}
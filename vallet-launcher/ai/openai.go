@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultOpenAIModel es el modelo que se usa si no se indica otro.
+const DefaultOpenAIModel = "whisper-1"
+
+// OpenAIClient transcribe con una API compatible con OpenAI (/v1/audio/transcriptions),
+// ya sea la de OpenAI o un servidor local que la imite.
+type OpenAIClient struct {
+	baseURL  string // URL base de la API, incluida la versión (ej: "https://api.openai.com/v1").
+	apiKey   string // Clave de la API (puede estar vacía en servidores locales).
+	model    string // Modelo de transcripción.
+	language string // Idioma del audio ("auto" o "" para detectarlo).
+}
+
+// NewOpenAIClient crea un cliente para la API en baseURL.
+func NewOpenAIClient(baseURL, apiKey, model, lang string) (*OpenAIClient, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if _, err := url.ParseRequestURI(baseURL); err != nil || baseURL == "" {
+		return nil, fmt.Errorf("URL de la API de transcripción inválida: %q", baseURL)
+	}
+	if strings.TrimSpace(model) == "" {
+		model = DefaultOpenAIModel
+	}
+	return &OpenAIClient{
+		baseURL:  baseURL,
+		apiKey:   strings.TrimSpace(apiKey),
+		model:    strings.TrimSpace(model),
+		language: lang,
+	}, nil
+}
+
+// Transcribe envía el audio al endpoint /audio/transcriptions.
+func (o *OpenAIClient) Transcribe(ctx context.Context, audio Audio) (TranscriptionResult, error) {
+	var headers map[string]string
+	if o.apiKey != "" {
+		headers = map[string]string{"Authorization": "Bearer " + o.apiKey}
+	}
+	// Solo whisper-1 admite verbose_json (con segmentos y confianza); los demás modelos
+	// devuelven únicamente el texto.
+	format := "json"
+	if o.model == DefaultOpenAIModel {
+		format = "verbose_json"
+	}
+	fields := map[string]string{
+		"model":           o.model,
+		"response_format": format,
+		"language":        language(o.language),
+	}
+
+	var resp verboseJSON
+	if err := postAudio(ctx, o.baseURL+"/audio/transcriptions", headers, fields, audio, &resp); err != nil {
+		return TranscriptionResult{}, fmt.Errorf("error en la API de transcripción: %w", err)
+	}
+	return resp.result(language(o.language)), nil
+}
@@ -0,0 +1,167 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Límites de los parámetros de decodificación.
+const (
+	MaxBeamSize      = 8    // whisper.cpp no mejora con más haces y tarda mucho más.
+	MaxBestOf        = 8    // Candidatos con muestreo por temperatura.
+	MaxTemperature   = 1.0  // Por encima de 1 el texto deja de tener sentido.
+	MaxInitialPrompt = 1000 // El prompt se recorta a unos 224 tokens; más texto no sirve.
+)
+
+// WhisperOptions son los parámetros de whisper.cpp que se pueden configurar. Los usan el
+// backend de la CLI y el servidor administrado.
+type WhisperOptions struct {
+	ModelPath     string  `json:"modelPath"`     // Ruta del modelo .bin ("" para buscar ModelName en las rutas habituales).
+	Threads       int     `json:"threads"`       // Hilos de CPU (0 = todos los núcleos).
+	BeamSize      int     `json:"beamSize"`      // Haces de la búsqueda (1 = decodificación voraz, la más rápida).
+	BestOf        int     `json:"bestOf"`        // Candidatos entre los que se elige al muestrear.
+	Temperature   float64 `json:"temperature"`   // Temperatura de muestreo (0 = determinista).
+	InitialPrompt string  `json:"initialPrompt"` // Texto que orienta el vocabulario y el estilo (nombres propios, puntuación).
+	Translate     bool    `json:"translate"`     // Traducir al inglés en vez de transcribir.
+}
+
+// DefaultWhisperOptions devuelve los valores por defecto: todos los núcleos y
+// decodificación voraz, que es lo más rápido para dictados cortos. Los núcleos se cuentan
+// al usar las opciones (ver withDefaults), así la configuración guardada sigue valiendo
+// si cambia el equipo.
+func DefaultWhisperOptions() WhisperOptions {
+	return WhisperOptions{
+		BeamSize: 1,
+		BestOf:   1,
+	}
+}
+
+// withDefaults completa con los valores por defecto los parámetros sin configurar y
+// resuelve Threads 0 al número de núcleos del equipo.
+func (o WhisperOptions) withDefaults() WhisperOptions {
+	d := DefaultWhisperOptions()
+	if o.Threads == 0 {
+		o.Threads = runtime.NumCPU()
+	}
+	if o.BeamSize == 0 {
+		o.BeamSize = d.BeamSize
+	}
+	if o.BestOf == 0 {
+		o.BestOf = d.BestOf
+	}
+	return o
+}
+
+// Validate comprueba que los parámetros estén dentro de los rangos que acepta whisper.cpp
+// y que el modelo exista.
+func (o WhisperOptions) Validate() error {
+	var errs []error
+	if o.ModelPath != "" {
+		if info, err := os.Stat(o.ModelPath); err != nil || info.IsDir() {
+			errs = append(errs, fmt.Errorf("modelo de whisper no encontrado: %s", o.ModelPath))
+		}
+	}
+	if o.Threads < 0 || o.Threads > runtime.NumCPU() {
+		errs = append(errs, fmt.Errorf("los hilos deben estar entre 1 y %d (0 para usar todos los núcleos)", runtime.NumCPU()))
+	}
+	if o.BeamSize < 1 || o.BeamSize > MaxBeamSize {
+		errs = append(errs, fmt.Errorf("el tamaño del haz debe estar entre 1 y %d", MaxBeamSize))
+	}
+	if o.BestOf < 1 || o.BestOf > MaxBestOf {
+		errs = append(errs, fmt.Errorf("los candidatos deben estar entre 1 y %d", MaxBestOf))
+	}
+	if o.Temperature < 0 || o.Temperature > MaxTemperature {
+		errs = append(errs, fmt.Errorf("la temperatura debe estar entre 0 y %g", MaxTemperature))
+	}
+	if utf8.RuneCountInString(o.InitialPrompt) > MaxInitialPrompt {
+		errs = append(errs, fmt.Errorf("el prompt inicial no puede superar los %d caracteres", MaxInitialPrompt))
+	}
+	return errors.Join(errs...)
+}
+
+// args devuelve los argumentos de decodificación comunes a whisper-cli y whisper-server.
+func (o WhisperOptions) args() []string {
+	args := []string{
+		"-t", strconv.Itoa(o.Threads),
+		"-bs", strconv.Itoa(o.BeamSize),
+		"-bo", strconv.Itoa(o.BestOf),
+		"-tp", formatTemperature(o.Temperature),
+	}
+	if o.InitialPrompt != "" {
+		args = append(args, "--prompt", o.InitialPrompt)
+	}
+	if o.Translate {
+		args = append(args, "-tr")
+	}
+	return args
+}
+
+// formatTemperature escribe la temperatura sin ceros de más ("0", "0.2").
+func formatTemperature(t float64) string {
+	return strconv.FormatFloat(t, 'f', -1, 64)
+}
+
+// languages son los códigos de idioma que reconoce whisper.
+var languages = map[string]string{
+	"en": "inglés", "zh": "chino", "de": "alemán", "es": "español", "ru": "ruso",
+	"ko": "coreano", "fr": "francés", "ja": "japonés", "pt": "portugués", "tr": "turco",
+	"pl": "polaco", "ca": "catalán", "nl": "neerlandés", "ar": "árabe", "sv": "sueco",
+	"it": "italiano", "id": "indonesio", "hi": "hindi", "fi": "finés", "vi": "vietnamita",
+	"he": "hebreo", "uk": "ucraniano", "el": "griego", "ms": "malayo", "cs": "checo",
+	"ro": "rumano", "da": "danés", "hu": "húngaro", "ta": "tamil", "no": "noruego",
+	"th": "tailandés", "ur": "urdu", "hr": "croata", "bg": "búlgaro", "lt": "lituano",
+	"la": "latín", "mi": "maorí", "ml": "malayalam", "cy": "galés", "sk": "eslovaco",
+	"te": "telugu", "fa": "persa", "lv": "letón", "bn": "bengalí", "sr": "serbio",
+	"az": "azerí", "sl": "esloveno", "kn": "canarés", "et": "estonio", "mk": "macedonio",
+	"br": "bretón", "eu": "euskera", "is": "islandés", "hy": "armenio", "ne": "nepalí",
+	"mn": "mongol", "bs": "bosnio", "kk": "kazajo", "sq": "albanés", "sw": "suajili",
+	"gl": "gallego", "mr": "maratí", "pa": "panyabí", "si": "cingalés", "km": "jemer",
+	"sn": "shona", "yo": "yoruba", "so": "somalí", "af": "afrikáans", "oc": "occitano",
+	"ka": "georgiano", "be": "bielorruso", "tg": "tayiko", "sd": "sindi", "gu": "guyaratí",
+	"am": "amárico", "yi": "ídish", "lo": "lao", "uz": "uzbeko", "fo": "feroés",
+	"ht": "criollo haitiano", "ps": "pastún", "tk": "turcomano", "nn": "nynorsk", "mt": "maltés",
+	"sa": "sánscrito", "lb": "luxemburgués", "my": "birmano", "bo": "tibetano", "tl": "tagalo",
+	"mg": "malgache", "as": "asamés", "tt": "tártaro", "haw": "hawaiano", "ln": "lingala",
+	"ha": "hausa", "ba": "baskir", "jw": "javanés", "su": "sundanés", "yue": "cantonés",
+}
+
+// Language es un idioma que reconoce whisper.
+type Language struct {
+	Code string `json:"code"` // Código ISO 639-1 (ej: "es") o "auto".
+	Name string `json:"name"` // Nombre en español.
+}
+
+// Languages devuelve los idiomas disponibles ordenados por nombre, empezando por "auto".
+func Languages() []Language {
+	list := make([]Language, 0, len(languages)+1)
+	for code, name := range languages {
+		list = append(list, Language{Code: code, Name: name})
+	}
+	sort.Slice(list, func(i, j int) bool { return sortKey(list[i].Name) < sortKey(list[j].Name) })
+	return append([]Language{{Code: "auto", Name: "Detectar automáticamente"}}, list...)
+}
+
+// accents quita las tildes para ordenar alfabéticamente ("árabe" antes que "bengalí").
+var accents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n")
+
+func sortKey(name string) string {
+	return accents.Replace(name)
+}
+
+// ValidateLanguage comprueba que lang sea "auto" o un idioma que reconoce whisper.
+func ValidateLanguage(lang string) error {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "auto" {
+		return nil
+	}
+	if _, ok := languages[lang]; !ok {
+		return fmt.Errorf("idioma desconocido: %q", lang)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWhisperOptionsValidate(t *testing.T) {
+	model := filepath.Join(t.TempDir(), "ggml-tiny.bin")
+	if err := os.WriteFile(model, []byte("modelo"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := WhisperOptions{ModelPath: model, Threads: 1, BeamSize: 1, BestOf: 1}
+	tests := []struct {
+		name    string
+		modify  func(*WhisperOptions)
+		wantErr string
+	}{
+		{"válidas", func(*WhisperOptions) {}, ""},
+		{"todos los núcleos", func(o *WhisperOptions) { o.Threads = 0 }, ""},
+		{"hilos máximos", func(o *WhisperOptions) { o.Threads = runtime.NumCPU() }, ""},
+		{"límites", func(o *WhisperOptions) {
+			o.BeamSize, o.BestOf, o.Temperature = MaxBeamSize, MaxBestOf, MaxTemperature
+			o.InitialPrompt = strings.Repeat("ñ", MaxInitialPrompt)
+		}, ""},
+		{"sin modelo", func(o *WhisperOptions) { o.ModelPath = "" }, ""},
+		{"modelo inexistente", func(o *WhisperOptions) { o.ModelPath += ".borrado" }, "modelo de whisper no encontrado"},
+		{"modelo es carpeta", func(o *WhisperOptions) { o.ModelPath = filepath.Dir(model) }, "modelo de whisper no encontrado"},
+		{"hilos negativos", func(o *WhisperOptions) { o.Threads = -1 }, "los hilos"},
+		{"más hilos que núcleos", func(o *WhisperOptions) { o.Threads = runtime.NumCPU() + 1 }, "los hilos"},
+		{"haz vacío", func(o *WhisperOptions) { o.BeamSize = 0 }, "tamaño del haz"},
+		{"haz grande", func(o *WhisperOptions) { o.BeamSize = MaxBeamSize + 1 }, "tamaño del haz"},
+		{"candidatos", func(o *WhisperOptions) { o.BestOf = MaxBestOf + 1 }, "candidatos"},
+		{"temperatura negativa", func(o *WhisperOptions) { o.Temperature = -0.1 }, "temperatura"},
+		{"temperatura alta", func(o *WhisperOptions) { o.Temperature = 1.5 }, "temperatura"},
+		{"prompt largo", func(o *WhisperOptions) { o.InitialPrompt = strings.Repeat("a", MaxInitialPrompt+1) }, "prompt inicial"},
+	}
+	for _, tt := range tests {
+		o := valid
+		tt.modify(&o)
+		err := o.Validate()
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: Validate() = %v, se esperaba nil", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: Validate() = %v, se esperaba un error con %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// Los errores se informan todos juntos.
+	err := WhisperOptions{Threads: -1, BeamSize: 0, BestOf: 0, Temperature: 2}.Validate()
+	if err == nil || strings.Count(err.Error(), "\n") != 3 {
+		t.Errorf("Validate() con cuatro errores = %v", err)
+	}
+}
+
+func TestWhisperOptionsWithDefaults(t *testing.T) {
+	got := WhisperOptions{Temperature: 0.2}.withDefaults()
+	want := WhisperOptions{Threads: runtime.NumCPU(), BeamSize: 1, BestOf: 1, Temperature: 0.2}
+	if got != want {
+		t.Errorf("withDefaults() = %+v, se esperaba %+v", got, want)
+	}
+
+	set := WhisperOptions{Threads: 2, BeamSize: 5, BestOf: 3}
+	if got := set.withDefaults(); got != set {
+		t.Errorf("withDefaults() cambió valores configurados: %+v", got)
+	}
+}
+
+func TestWhisperOptionsArgs(t *testing.T) {
+	tests := []struct {
+		options WhisperOptions
+		want    []string
+	}{
+		{
+			WhisperOptions{Threads: 4, BeamSize: 1, BestOf: 1},
+			[]string{"-t", "4", "-bs", "1", "-bo", "1", "-tp", "0"},
+		},
+		{
+			WhisperOptions{Threads: 2, BeamSize: 5, BestOf: 3, Temperature: 0.2, InitialPrompt: "Vallet, Jira.", Translate: true},
+			[]string{"-t", "2", "-bs", "5", "-bo", "3", "-tp", "0.2", "--prompt", "Vallet, Jira.", "-tr"},
+		},
+		{
+			WhisperOptions{}.withDefaults(),
+			[]string{"-t", strconv.Itoa(runtime.NumCPU()), "-bs", "1", "-bo", "1", "-tp", "0"},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.options.args(); !slices.Equal(got, tt.want) {
+			t.Errorf("%+v.args() = %q, se esperaba %q", tt.options, got, tt.want)
+		}
+	}
+}
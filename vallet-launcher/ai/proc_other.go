@@ -0,0 +1,16 @@
+//go:build !windows
+
+package ai
+
+import (
+	"os"
+	"os/exec"
+)
+
+// hideConsole no hace nada fuera de Windows: los procesos no abren consola propia.
+func hideConsole(cmd *exec.Cmd) {}
+
+// interruptProcess pide al proceso que termine ordenadamente.
+func interruptProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Signal(os.Interrupt)
+}
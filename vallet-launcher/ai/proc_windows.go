@@ -0,0 +1,22 @@
+//go:build windows
+
+package ai
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// hideConsole evita que se abra una ventana de consola al ejecutar el proceso.
+func hideConsole(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
+	}
+}
+
+// interruptProcess detiene el proceso. Windows no permite enviar Ctrl+C a un proceso sin
+// consola, así que se termina directamente.
+func interruptProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
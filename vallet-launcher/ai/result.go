@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Umbrales con los que whisper descarta un segmento por ser silencio: mucha probabilidad
+// de que no haya voz y poca confianza en el texto (típico de frases inventadas como
+// "Gracias por ver el video" al grabar ruido).
+const (
+	NoSpeechThreshold = 0.6
+	LogProbThreshold  = -1.0
+)
+
+// Segment es un fragmento de la transcripción con sus tiempos.
+type Segment struct {
+	Start        time.Duration `json:"start"`        // Inicio dentro del audio.
+	End          time.Duration `json:"end"`          // Fin dentro del audio.
+	Text         string        `json:"text"`         // Texto del segmento, sin espacios al principio ni al final.
+	AvgLogProb   float64       `json:"avgLogProb"`   // Media del logaritmo de la probabilidad de los tokens (0 = total confianza).
+	NoSpeechProb float64       `json:"noSpeechProb"` // Probabilidad de que el segmento sea silencio (0 si el backend no la informa).
+}
+
+// TranscriptionResult es el resultado de una transcripción.
+type TranscriptionResult struct {
+	Text         string    `json:"text"`         // Texto transcrito, sin espacios al principio ni al final.
+	Language     string    `json:"language"`     // Idioma detectado o indicado (ej: "es"), si el backend lo informa.
+	Segments     []Segment `json:"segments"`     // Segmentos con tiempos; vacío si el backend no los da.
+	AvgLogProb   float64   `json:"avgLogProb"`   // Media de los segmentos ponderada por su duración.
+	NoSpeechProb float64   `json:"noSpeechProb"` // Media de los segmentos ponderada por su duración.
+}
+
+// LowConfidence indica si el resultado parece una alucinación sobre silencio o ruido
+// según los umbrales de whisper. Sin segmentos (backends que no los informan) siempre es
+// false.
+func (r TranscriptionResult) LowConfidence() bool {
+	if len(r.Segments) == 0 {
+		return false
+	}
+	return r.NoSpeechProb > NoSpeechThreshold && r.AvgLogProb < LogProbThreshold
+}
+
+// summarize completa el texto (si falta) y las medias del resultado a partir de los
+// segmentos. Los segmentos sin duración cuentan como de 1 ms para no ignorarlos.
+func (r *TranscriptionResult) summarize() {
+	if len(r.Segments) == 0 {
+		r.Text = strings.TrimSpace(r.Text)
+		return
+	}
+
+	var texts []string
+	var total, logProb, noSpeech float64
+	for _, s := range r.Segments {
+		if s.Text != "" {
+			texts = append(texts, s.Text)
+		}
+		weight := math.Max(float64((s.End-s.Start)/time.Millisecond), 1)
+		total += weight
+		logProb += s.AvgLogProb * weight
+		noSpeech += s.NoSpeechProb * weight
+	}
+	if strings.TrimSpace(r.Text) == "" {
+		r.Text = strings.Join(texts, " ")
+	}
+	r.Text = strings.TrimSpace(r.Text)
+	r.AvgLogProb = logProb / total
+	r.NoSpeechProb = noSpeech / total
+}
+
+// whisperJSON es el archivo que escribe whisper-cli con -oj -ojf.
+type whisperJSON struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"` // Milisegundos.
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text         string   `json:"text"`
+		NoSpeechProb *float64 `json:"no_speech_prob"` // Solo en versiones recientes de whisper.cpp.
+		Tokens       []struct {
+			Text string  `json:"text"`
+			P    float64 `json:"p"`
+		} `json:"tokens"`
+	} `json:"transcription"`
+}
+
+// isSpecialToken indica si el token es de control ("[_BEG_]", "[_TT_150]") y no texto.
+func isSpecialToken(text string) bool {
+	return strings.HasPrefix(text, "[_") && strings.HasSuffix(text, "]")
+}
+
+// ParseWhisperJSON convierte la salida JSON de whisper-cli (-oj, y -ojf para las
+// probabilidades de los tokens) en un resultado.
+func ParseWhisperJSON(data []byte) (TranscriptionResult, error) {
+	var out whisperJSON
+	if err := json.Unmarshal(data, &out); err != nil {
+		return TranscriptionResult{}, fmt.Errorf("salida JSON de whisper inválida: %w", err)
+	}
+
+	result := TranscriptionResult{Language: out.Result.Language}
+	for _, t := range out.Transcription {
+		seg := Segment{
+			Start: time.Duration(t.Offsets.From) * time.Millisecond,
+			End:   time.Duration(t.Offsets.To) * time.Millisecond,
+			Text:  strings.TrimSpace(t.Text),
+		}
+		if t.NoSpeechProb != nil {
+			seg.NoSpeechProb = *t.NoSpeechProb
+		}
+
+		var sum float64
+		var n int
+		for _, tok := range t.Tokens {
+			if isSpecialToken(tok.Text) || tok.P <= 0 {
+				continue
+			}
+			sum += math.Log(tok.P)
+			n++
+		}
+		if n > 0 {
+			seg.AvgLogProb = sum / float64(n)
+		}
+		result.Segments = append(result.Segments, seg)
+	}
+	result.summarize()
+	return result, nil
+}
+
+// verboseJSON es la respuesta con response_format=verbose_json de whisper-server y de las
+// APIs compatibles con OpenAI.
+type verboseJSON struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+	Segments []struct {
+		Start        float64 `json:"start"` // Segundos.
+		End          float64 `json:"end"`
+		Text         string  `json:"text"`
+		AvgLogProb   float64 `json:"avg_logprob"`
+		NoSpeechProb float64 `json:"no_speech_prob"`
+	} `json:"segments"`
+}
+
+// result convierte la respuesta en un resultado. fallbackLang se usa si la respuesta no
+// indica el idioma.
+func (v verboseJSON) result(fallbackLang string) TranscriptionResult {
+	result := TranscriptionResult{Text: v.Text, Language: normalizeLanguage(v.Language)}
+	if result.Language == "" {
+		result.Language = fallbackLang
+	}
+	for _, s := range v.Segments {
+		result.Segments = append(result.Segments, Segment{
+			Start:        seconds(s.Start),
+			End:          seconds(s.End),
+			Text:         strings.TrimSpace(s.Text),
+			AvgLogProb:   s.AvgLogProb,
+			NoSpeechProb: s.NoSpeechProb,
+		})
+	}
+	result.summarize()
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// englishNames son los nombres en inglés de los idiomas de whisper, tal como los
+// devuelven whisper-server y la API de OpenAI en verbose_json ("spanish").
+var englishNames = map[string]string{
+	"english": "en", "chinese": "zh", "german": "de", "spanish": "es", "russian": "ru",
+	"korean": "ko", "french": "fr", "japanese": "ja", "portuguese": "pt", "turkish": "tr",
+	"polish": "pl", "catalan": "ca", "dutch": "nl", "arabic": "ar", "swedish": "sv",
+	"italian": "it", "indonesian": "id", "hindi": "hi", "finnish": "fi", "vietnamese": "vi",
+	"hebrew": "he", "ukrainian": "uk", "greek": "el", "malay": "ms", "czech": "cs",
+	"romanian": "ro", "danish": "da", "hungarian": "hu", "tamil": "ta", "norwegian": "no",
+	"thai": "th", "urdu": "ur", "croatian": "hr", "bulgarian": "bg", "lithuanian": "lt",
+	"latin": "la", "maori": "mi", "malayalam": "ml", "welsh": "cy", "slovak": "sk",
+	"telugu": "te", "persian": "fa", "latvian": "lv", "bengali": "bn", "serbian": "sr",
+	"azerbaijani": "az", "slovenian": "sl", "kannada": "kn", "estonian": "et", "macedonian": "mk",
+	"breton": "br", "basque": "eu", "icelandic": "is", "armenian": "hy", "nepali": "ne",
+	"mongolian": "mn", "bosnian": "bs", "kazakh": "kk", "albanian": "sq", "swahili": "sw",
+	"galician": "gl", "marathi": "mr", "punjabi": "pa", "sinhala": "si", "khmer": "km",
+	"shona": "sn", "yoruba": "yo", "somali": "so", "afrikaans": "af", "occitan": "oc",
+	"georgian": "ka", "belarusian": "be", "tajik": "tg", "sindhi": "sd", "gujarati": "gu",
+	"amharic": "am", "yiddish": "yi", "lao": "lo", "uzbek": "uz", "faroese": "fo",
+	"haitian creole": "ht", "pashto": "ps", "turkmen": "tk", "nynorsk": "nn", "maltese": "mt",
+	"sanskrit": "sa", "luxembourgish": "lb", "myanmar": "my", "tibetan": "bo", "tagalog": "tl",
+	"malagasy": "mg", "assamese": "as", "tatar": "tt", "hawaiian": "haw", "lingala": "ln",
+	"hausa": "ha", "bashkir": "ba", "javanese": "jw", "sundanese": "su", "cantonese": "yue",
+}
+
+// normalizeLanguage devuelve el código del idioma ("spanish" -> "es"), sea cual sea el
+// formato del backend.
+func normalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if code, ok := englishNames[lang]; ok {
+		return code
+	}
+	return lang
+}
@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// meanLog es la media del logaritmo de las probabilidades.
+func meanLog(ps ...float64) float64 {
+	var sum float64
+	for _, p := range ps {
+		sum += math.Log(p)
+	}
+	return sum / float64(len(ps))
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestParseWhisperJSON(t *testing.T) {
+	result, err := ParseWhisperJSON(readFixture(t, "whisper-cli.json"))
+	if err != nil {
+		t.Fatalf("ParseWhisperJSON: %v", err)
+	}
+
+	if result.Text != "Hola, ¿qué tal? Adiós." {
+		t.Errorf("Text = %q", result.Text)
+	}
+	if result.Language != "es" {
+		t.Errorf("Language = %q, se esperaba es", result.Language)
+	}
+	if len(result.Segments) != 2 {
+		t.Fatalf("segmentos = %d, se esperaban 2", len(result.Segments))
+	}
+
+	first, second := result.Segments[0], result.Segments[1]
+	if first.Start != 0 || first.End != 3*time.Second || second.Start != 3*time.Second || second.End != 4*time.Second {
+		t.Errorf("tiempos = [%v-%v] [%v-%v]", first.Start, first.End, second.Start, second.End)
+	}
+	if first.Text != "Hola, ¿qué tal?" || second.Text != "Adiós." {
+		t.Errorf("textos = %q, %q", first.Text, second.Text)
+	}
+
+	// Los tokens [_BEG_] y [_TT_…] no cuentan: solo las palabras y la puntuación.
+	wantFirst := meanLog(0.9, 0.8, 0.5, 0.6)
+	wantSecond := meanLog(0.4, 0.2)
+	if !almostEqual(first.AvgLogProb, wantFirst) || !almostEqual(second.AvgLogProb, wantSecond) {
+		t.Errorf("AvgLogProb = %v, %v; se esperaba %v, %v", first.AvgLogProb, second.AvgLogProb, wantFirst, wantSecond)
+	}
+
+	// La media del resultado se pondera por la duración: 3 s y 1 s.
+	if want := (wantFirst*3 + wantSecond*1) / 4; !almostEqual(result.AvgLogProb, want) {
+		t.Errorf("AvgLogProb del resultado = %v, se esperaba %v", result.AvgLogProb, want)
+	}
+}
+
+func TestParseWhisperJSONWithoutNoSpeechProb(t *testing.T) {
+	result, err := ParseWhisperJSON(readFixture(t, "whisper-cli.json"))
+	if err != nil {
+		t.Fatalf("ParseWhisperJSON: %v", err)
+	}
+	// La segunda frase tiene poca confianza, pero sin no_speech_prob no se puede saber si
+	// es silencio: no se descarta.
+	if result.NoSpeechProb != 0 || result.LowConfidence() {
+		t.Errorf("NoSpeechProb = %v, LowConfidence = %v; se esperaba 0, false", result.NoSpeechProb, result.LowConfidence())
+	}
+}
+
+func TestParseWhisperJSONSilence(t *testing.T) {
+	result, err := ParseWhisperJSON(readFixture(t, "whisper-cli-silence.json"))
+	if err != nil {
+		t.Fatalf("ParseWhisperJSON: %v", err)
+	}
+	if !almostEqual(result.NoSpeechProb, 0.92) {
+		t.Errorf("NoSpeechProb = %v, se esperaba 0.92", result.NoSpeechProb)
+	}
+	if want := meanLog(0.2, 0.3, 0.25, 0.4, 0.3); !almostEqual(result.AvgLogProb, want) {
+		t.Errorf("AvgLogProb = %v, se esperaba %v", result.AvgLogProb, want)
+	}
+	if !result.LowConfidence() {
+		t.Error("una alucinación sobre silencio no se marcó como de baja confianza")
+	}
+}
+
+func TestParseWhisperJSONInvalid(t *testing.T) {
+	if _, err := ParseWhisperJSON([]byte("whisper_init_from_file: failed")); err == nil {
+		t.Error("ParseWhisperJSON aceptó una salida que no es JSON")
+	}
+}
+
+func TestVerboseJSONResult(t *testing.T) {
+	data := []byte(`{
+		"task": "transcribe",
+		"language": "Spanish",
+		"duration": 4.0,
+		"text": " Hola, ¿qué tal? Adiós.",
+		"segments": [
+			{"id": 0, "start": 0.0, "end": 3.0, "text": " Hola, ¿qué tal?", "avg_logprob": -0.2, "no_speech_prob": 0.1},
+			{"id": 1, "start": 3.0, "end": 4.0, "text": " Adiós.", "avg_logprob": -1.0, "no_speech_prob": 0.5}
+		]
+	}`)
+	var v verboseJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	result := v.result("en")
+
+	if result.Language != "es" {
+		t.Errorf("Language = %q, se esperaba es", result.Language)
+	}
+	if result.Text != "Hola, ¿qué tal? Adiós." {
+		t.Errorf("Text = %q", result.Text)
+	}
+	if len(result.Segments) != 2 || result.Segments[1].Start != 3*time.Second || result.Segments[1].End != 4*time.Second {
+		t.Errorf("segmentos = %+v", result.Segments)
+	}
+	if want := (-0.2*3 - 1.0) / 4; !almostEqual(result.AvgLogProb, want) {
+		t.Errorf("AvgLogProb = %v, se esperaba %v", result.AvgLogProb, want)
+	}
+	if want := (0.1*3 + 0.5) / 4; !almostEqual(result.NoSpeechProb, want) {
+		t.Errorf("NoSpeechProb = %v, se esperaba %v", result.NoSpeechProb, want)
+	}
+}
+
+func TestVerboseJSONLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"spanish", "es"},
+		{"English", "en"},
+		{"haitian creole", "ht"},
+		{"es", "es"},
+		{"", "fr"}, // Sin idioma en la respuesta se usa el configurado.
+	}
+	for _, tt := range tests {
+		if got := (verboseJSON{Language: tt.language}).result("fr").Language; got != tt.want {
+			t.Errorf("idioma %q = %q, se esperaba %q", tt.language, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ServerClient transcribe con el servidor HTTP de whisper.cpp (whisper-server), que
+// mantiene el modelo cargado entre transcripciones.
+type ServerClient struct {
+	baseURL  string // URL base del servidor (ej: "http://127.0.0.1:8080").
+	language string // Idioma del audio ("auto" o "" para detectarlo).
+	options  WhisperOptions
+}
+
+// NewServerClient crea un cliente para el servidor de whisper.cpp en baseURL.
+func NewServerClient(baseURL, lang string) (*ServerClient, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if _, err := url.ParseRequestURI(baseURL); err != nil || baseURL == "" {
+		return nil, fmt.Errorf("URL del servidor de whisper inválida: %q", baseURL)
+	}
+	return &ServerClient{baseURL: baseURL, language: lang, options: DefaultWhisperOptions()}, nil
+}
+
+// Transcribe envía el audio al endpoint /inference y pide verbose_json para recibir los
+// segmentos con su confianza. La temperatura, el prompt y la
+// traducción se envían con cada petición; los hilos y el beam search son opciones de
+// arranque del servidor.
+func (s *ServerClient) Transcribe(ctx context.Context, audio Audio) (TranscriptionResult, error) {
+	var resp verboseJSON
+	fields := map[string]string{
+		"response_format": "verbose_json",
+		"temperature":     formatTemperature(s.options.Temperature),
+		"language":        language(s.language),
+		"prompt":          s.options.InitialPrompt,
+	}
+	if s.options.Translate {
+		fields["translate"] = "true"
+	}
+	if err := postAudio(ctx, s.baseURL+"/inference", nil, fields, audio, &resp); err != nil {
+		return TranscriptionResult{}, fmt.Errorf("error en el servidor de whisper: %w", err)
+	}
+	return resp.result(language(s.language)), nil
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Audio es una grabación a transcribir, en formato WAV. Basta con uno de los dos campos:
+// los backends HTTP envían los bytes y la CLI necesita un archivo en disco.
+type Audio struct {
+	WAV  []byte // Contenido del archivo WAV.
+	Path string // Ruta del archivo WAV, si ya está guardado.
+}
+
+// Bytes devuelve el contenido del audio, leyéndolo de Path si hace falta.
+func (a Audio) Bytes() ([]byte, error) {
+	if a.WAV != nil {
+		return a.WAV, nil
+	}
+	if a.Path == "" {
+		return nil, fmt.Errorf("audio vacío")
+	}
+	return os.ReadFile(a.Path)
+}
+
+// File devuelve la ruta de un archivo con el audio. Si solo hay bytes se escriben en un
+// archivo temporal que cleanup elimina.
+func (a Audio) File() (path string, cleanup func(), err error) {
+	if a.Path != "" {
+		return a.Path, func() {}, nil
+	}
+	f, err := os.CreateTemp("", "vallet_audio_*.wav")
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	if _, err := f.Write(a.WAV); err != nil {
+		os.Remove(f.Name())
+		return "", nil, err
+	}
+	return f.Name(), func() { os.Remove(f.Name()) }, nil
+}
+
+// Transcriber convierte audio en texto.
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio Audio) (TranscriptionResult, error)
+}
+
+// Config reúne las opciones de todos los backends; cada uno usa las suyas.
+type Config struct {
+	Language string // Idioma del audio ("auto" para detectarlo).
+
+	Whisper WhisperOptions // Parámetros de whisper.cpp (CLI y servidores).
+
+	ServerURL string // URL base del servidor de whisper.cpp (ej: "http://127.0.0.1:8080").
+
+	APIBaseURL string // URL base de una API compatible con OpenAI (ej: "https://api.openai.com/v1").
+	APIKey     string // Clave de la API.
+	APIModel   string // Modelo de la API (ej: "whisper-1").
+}
+
+// Factory crea un backend con la configuración indicada.
+type Factory func(cfg Config) (Transcriber, error)
+
+// Nombres de los backends incluidos.
+const (
+	BackendWhisperCLI    = "whisper-cli"    // Binario whisper-cli de whisper.cpp (un proceso por transcripción).
+	BackendWhisperServer = "whisper-server" // Servidor HTTP de whisper.cpp (endpoint /inference).
+	BackendOpenAI        = "openai"         // API compatible con OpenAI (/v1/audio/transcriptions).
+
+	// BackendWhisperManaged arranca whisper-server como proceso hijo y lo mantiene en marcha,
+	// así el modelo se carga una sola vez.
+	BackendWhisperManaged = "whisper-managed"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+func init() {
+	Register(BackendWhisperCLI, func(cfg Config) (Transcriber, error) {
+		return NewWhisperClient(cfg.Language, cfg.Whisper)
+	})
+	Register(BackendWhisperServer, func(cfg Config) (Transcriber, error) {
+		client, err := NewServerClient(cfg.ServerURL, cfg.Language)
+		if err != nil {
+			return nil, err
+		}
+		client.options = cfg.Whisper.withDefaults()
+		return client, nil
+	})
+	Register(BackendWhisperManaged, func(cfg Config) (Transcriber, error) {
+		return managedServer(cfg)
+	})
+	Register(BackendOpenAI, func(cfg Config) (Transcriber, error) {
+		return NewOpenAIClient(cfg.APIBaseURL, cfg.APIKey, cfg.APIModel, cfg.Language)
+	})
+}
+
+// Register agrega un backend al registro (o reemplaza el que tenga el mismo nombre).
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// New crea el backend registrado con ese nombre.
+func New(name string, cfg Config) (Transcriber, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("backend de transcripción desconocido: %q", name)
+	}
+	return factory(cfg)
+}
+
+// Backends devuelve los nombres de los backends registrados, ordenados.
+func Backends() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,127 +2,165 @@ package ai
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"syscall"
 )
 
-const (
-	WhisperType = "small"           // Tipo de modelo a utilizar (ej: tiny, base, small, medium, large).
-	ModelName   = "ggml-small.bin"  // Nombre del archivo del modelo pre-entrenado.
-	BinaryName  = "whisper-cli.exe" // Nombre del binario ejecutable de Whisper.
-)
+// ModelName es el modelo que se busca cuando no se configura una ruta (el modelo small es
+// el que mejor equilibra velocidad y precisión).
+const ModelName = "ggml-small.bin"
+
+// BinaryName es el nombre del binario ejecutable de Whisper.
+var BinaryName = func() string {
+	if runtime.GOOS == "windows" {
+		return "whisper-cli.exe"
+	}
+	return "whisper-cli"
+}()
 
-// WhisperClient maneja la comunicación con el binario de whisper.cpp.
+// WhisperClient transcribe ejecutando el binario whisper-cli de whisper.cpp.
 type WhisperClient struct {
 	binaryPath string // Ruta completa al ejecutable de Whisper.
 	modelPath  string // Ruta completa al archivo del modelo .bin.
+	language   string // Idioma del audio ("auto" o "" para detectarlo).
+	options    WhisperOptions
+}
+
+// NewWhisperClient inicializa un nuevo cliente buscando el binario en rutas comunes. El
+// modelo es options.ModelPath o, si está vacío, ModelName en esas mismas rutas.
+func NewWhisperClient(lang string, options WhisperOptions) (*WhisperClient, error) {
+	binaryDirs, modelDirs, err := searchDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Si no se encuentran, se asumen los nombres por defecto (el SO los buscará en el PATH).
+	return &WhisperClient{
+		binaryPath: findFile(BinaryName, binaryDirs),
+		modelPath:  modelPath(options, modelDirs),
+		language:   lang,
+		options:    options.withDefaults(),
+	}, nil
+}
+
+// modelPath devuelve el modelo configurado o, si no hay, el modelo por defecto encontrado
+// en dirs. Si tampoco está, se usa el primero de la carpeta de modelos que supere la
+// verificación del manifiesto: los modelos sin verificar solo se usan si el usuario los
+// activa (ver model_allow_unverified).
+func modelPath(options WhisperOptions, dirs []string) string {
+	if options.ModelPath != "" {
+		return options.ModelPath
+	}
+	path := findFile(ModelName, dirs)
+	if path != ModelName {
+		return path
+	}
+	if dir, err := DefaultModelsDir(); err == nil {
+		if model, ok := NewModelManager(dir, "", DefaultManifest()).FirstVerified(); ok {
+			return model.Path
+		}
+	}
+	return path
 }
 
-// NewWhisperClient inicializa un nuevo cliente buscando el binario y el modelo en rutas comunes.
-func NewWhisperClient() (*WhisperClient, error) {
+// searchDirs devuelve los directorios donde se buscan los binarios de whisper.cpp y el
+// modelo: junto al ejecutable, en el directorio de trabajo (útil durante el desarrollo) y
+// en sus subcarpetas habituales. El modelo se busca antes en la carpeta de modelos.
+func searchDirs() (binaryDirs, modelDirs []string, err error) {
 	// Obtener la ruta del ejecutable actual para buscar recursos relativos a él.
 	exePath, err := os.Executable()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	exeDir := filepath.Dir(exePath)
-
-	// También considerar el directorio de trabajo actual (útil durante el desarrollo).
 	cwd, _ := os.Getwd()
+	modelsDir, _ := DefaultModelsDir()
 
-	// Lista de directorios posibles donde podría estar el ejecutable de Whisper.
-	possibleBinaryDirs := []string{
+	common := []string{
 		exeDir,
 		cwd,
 		filepath.Join(exeDir, "resources"),
 		filepath.Join(cwd, "resources"),
 		filepath.Join(cwd, "ai"),
 		filepath.Join(exeDir, "ai"),
-		filepath.Join(cwd, "whisper", "whisper-cublas-12.4.0-bin-x64", "Release"),
+		filepath.Join(cwd, "whisper"),
+		filepath.Join(exeDir, "whisper"),
 	}
 
-	// Lista de directorios posibles donde podría estar el modelo de lenguaje.
-	possibleModelDirs := []string{
-		exeDir,
-		cwd,
-		filepath.Join(exeDir, "resources"),
-		filepath.Join(cwd, "resources"),
-		filepath.Join(cwd, "ai"),
-		filepath.Join(exeDir, "ai"),
+	binaryDirs = append(common[:len(common):len(common)],
+		filepath.Join(cwd, "whisper", "whisper-cublas-12.4.0-bin-x64", "Release"),
+	)
+	modelDirs = append([]string{modelsDir}, common...)
+	modelDirs = append(modelDirs,
 		filepath.Join(cwd, "whisper", "whisper.cpp.small"),
 		filepath.Join(cwd, "whisper", "whisper.cpp.small", "models"),
-	}
-
-	binaryPath := ""
-	modelPath := ""
-
-	// Buscar el binario en las rutas especificadas.
-	for _, dir := range possibleBinaryDirs {
-		bPath := filepath.Join(dir, BinaryName)
-		if _, err := os.Stat(bPath); err == nil {
-			binaryPath = bPath
-			break
-		}
-	}
+	)
+	return binaryDirs, modelDirs, nil
+}
 
-	// Buscar el modelo corregido en las rutas especificadas.
-	for _, dir := range possibleModelDirs {
-		mPath := filepath.Join(dir, ModelName)
-		if _, err := os.Stat(mPath); err == nil {
-			modelPath = mPath
-			break
+// findFile devuelve la ruta del primer directorio que contiene name, o name sin más si no
+// está en ninguno.
+func findFile(name string, dirs []string) string {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
 		}
 	}
-
-	// Si no se encuentran, se asumen los nombres por defecto (el SO los buscará en el PATH).
-	if binaryPath == "" {
-		binaryPath = BinaryName
-	}
-	if modelPath == "" {
-		modelPath = ModelName
-	}
-
-	return &WhisperClient{
-		binaryPath: binaryPath,
-		modelPath:  modelPath,
-	}, nil
+	return name
 }
 
-// Transcribe toma la ruta de un archivo .wav y devuelve el texto transcrito.
-func (w *WhisperClient) Transcribe(wavPath string) (string, error) {
+// Transcribe ejecuta whisper-cli sobre el audio y devuelve el texto transcrito con sus
+// segmentos, el idioma detectado y la confianza, leídos de la salida JSON.
+func (w *WhisperClient) Transcribe(ctx context.Context, audio Audio) (TranscriptionResult, error) {
 	// Verificar que existan el binario y el modelo antes de ejecutar.
 	if _, err := os.Stat(w.binaryPath); os.IsNotExist(err) {
 		if p, err := exec.LookPath(w.binaryPath); err == nil {
 			w.binaryPath = p
 		} else {
-			return "", fmt.Errorf("binario de whisper no encontrado: %s", w.binaryPath)
+			return TranscriptionResult{}, fmt.Errorf("binario de whisper no encontrado: %s", w.binaryPath)
 		}
 	}
 	if _, err := os.Stat(w.modelPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("modelo de whisper no encontrado: %s", w.modelPath)
+		return TranscriptionResult{}, fmt.Errorf("modelo de whisper no encontrado: %s", w.modelPath)
 	}
 
+	wavPath, cleanup, err := audio.File()
+	if err != nil {
+		return TranscriptionResult{}, err
+	}
+	defer cleanup()
+
+	lang := w.language
+	if lang == "" {
+		lang = "auto"
+	}
+
+	// whisper-cli escribe el JSON en <base>.json.
+	outDir, err := os.MkdirTemp("", "vallet_whisper_*")
+	if err != nil {
+		return TranscriptionResult{}, err
+	}
+	defer os.RemoveAll(outDir)
+	outBase := filepath.Join(outDir, "transcripcion")
+
 	// Configuración del comando para llamar a whisper-cli.
 	// -m: ruta al modelo.
 	// -f: ruta al archivo de audio.
 	// -nt: no incluir timestamps en la salida.
-	// -l auto: detectar lenguaje automáticamente.
-	// -t 8: usar 8 hilos.
-	// -bs 1 -bo 1: configuraciones de velocidad (beam search y best of).
-	cmd := exec.Command(w.binaryPath, "-m", w.modelPath, "-f", wavPath, "-nt", "-l", "auto", "-t", "8", "-bs", "1", "-bo", "1")
+	// -l: idioma del audio ("auto" para detectarlo).
+	// -oj -ojf -of: escribir segmentos, tiempos y probabilidades de los tokens en <base>.json.
+	// El resto (hilos, beam search, best of, temperatura, prompt, traducción) sale de las opciones.
+	args := append([]string{"-m", w.modelPath, "-f", wavPath, "-nt", "-l", lang, "-oj", "-ojf", "-of", outBase}, w.options.args()...)
+	cmd := exec.CommandContext(ctx, w.binaryPath, args...)
 
 	// En Windows, ocultamos la consola emergente para que no interrumpa al usuario.
-	if runtime.GOOS == "windows" {
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			HideWindow:    true,
-			CreationFlags: 0x08000000, // CREATE_NO_WINDOW
-		}
-	}
+	hideConsole(cmd)
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -130,7 +168,7 @@ func (w *WhisperClient) Transcribe(wavPath string) (string, error) {
 	cmd.Stderr = &stderr
 
 	// Ejecutar la transcripción.
-	err := cmd.Run()
+	err = cmd.Run()
 
 	// Se imprime la información del sistema (útil para diagnosticar si usa GPU/CUDA).
 	fmt.Println("--- Información del Sistema Whisper ---")
@@ -138,10 +176,20 @@ func (w *WhisperClient) Transcribe(wavPath string) (string, error) {
 	fmt.Println("---------------------------------------")
 
 	if err != nil {
-		return "", fmt.Errorf("error ejecutando whisper: %v, stderr: %s", err, stderr.String())
+		return TranscriptionResult{}, fmt.Errorf("error ejecutando whisper: %v, stderr: %s", err, stderr.String())
 	}
 
-	// Limpiar y devolver el texto transcrito.
+	// Leer el JSON; si falta (versiones antiguas de whisper.cpp) se usa el texto de la salida.
+	if data, err := os.ReadFile(outBase + ".json"); err == nil {
+		result, err := ParseWhisperJSON(data)
+		if err == nil {
+			if result.Language == "" {
+				result.Language = language(lang)
+			}
+			return result, nil
+		}
+		fmt.Printf("⚠️ %v\n", err)
+	}
 	output := strings.TrimSpace(stdout.String())
-	return output, nil
+	return TranscriptionResult{Text: output, Language: language(lang)}, nil
 }
@@ -9,70 +9,220 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"vallet-launcher/ai"
 	"vallet-launcher/audio"
+	"vallet-launcher/templates"
 	"vallet-launcher/utils"
 
 	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-// ProcessAudio recibe el audio en formato base64 desde el frontend, lo transcribe usando Whisper
-// y pega el texto resultante en la aplicación activa del usuario.
-func (a *App) ProcessAudio(base64Data string) {
+// pasteOptions lee de la configuración cómo se introduce el texto: la estrategia según las
+// reglas por aplicación para la ventana con el foco, y si se restaura el portapapeles.
+// Debe llamarse justo antes de pegar, cuando el foco ya volvió a la aplicación destino.
+func (a *App) pasteOptions() utils.PasteOptions {
+	restore, _ := a.db.GetSetting("paste_restore_clipboard")
+	delaySetting, _ := a.db.GetSetting("paste_restore_delay_ms")
+
+	opts := utils.PasteOptions{
+		Strategy:         a.pasteStrategy(),
+		RestoreClipboard: restore != "false",
+	}
+	if ms, err := strconv.Atoi(strings.TrimSpace(delaySetting)); err == nil && ms > 0 {
+		opts.RestoreDelay = time.Duration(ms) * time.Millisecond
+	}
+	return opts
+}
+
+// pasteStrategy elige la estrategia de pegado para la ventana con el foco.
+func (a *App) pasteStrategy() utils.Strategy {
+	defaultSetting, _ := a.db.GetSetting("paste_strategy")
+	rulesSetting, _ := a.db.GetSetting("paste_rules")
+
+	fallback, err := utils.ParseStrategy(defaultSetting)
+	if err != nil {
+		fallback = utils.StrategyCtrlV
+	}
+	rules, err := utils.ParseInjectionRules(rulesSetting)
+	if err != nil {
+		log.Printf("Reglas de pegado inválidas: %v", err)
+	}
+	if len(rules) == 0 {
+		return fallback
+	}
+
+	window, err := utils.ActiveWindow()
+	if err != nil {
+		return fallback
+	}
+	strategy := utils.ChooseStrategy(rules, window, fallback)
+	if strategy != fallback {
+		fmt.Printf("⌨️ Pegando en %s con %s\n", window, strategy)
+	}
+	return strategy
+}
+
+// validatePasteSetting comprueba la estrategia y las reglas de pegado antes de guardarlas
+// con UpdateSettingBackend, para que el panel muestre el error en lugar de ignorarlo al
+// pegar. Devuelve nil para las demás claves.
+func validatePasteSetting(key, value string) error {
+	switch key {
+	case "paste_strategy":
+		_, err := utils.ParseStrategy(value)
+		return err
+	case "paste_rules":
+		_, err := utils.ParseInjectionRules(value)
+		return err
+	}
+	return nil
+}
+
+// transcriptionTimeout limita cuánto puede tardar una transcripción.
+const transcriptionTimeout = 2 * time.Minute
+
+// ProcessAudio recibe el audio en formato base64 desde el frontend, lo transcribe con el
+// backend configurado y pega el texto resultante en la aplicación activa del usuario.
+// Devuelve la transcripción, también cuando se descarta por baja confianza o no tiene texto.
+func (a *App) ProcessAudio(base64Data string) (ai.TranscriptionResult, error) {
 	fmt.Println("🎙️ Procesando audio recibido...")
 
 	// 1. Decodificar la cadena base64 a bytes.
 	data, err := base64.StdEncoding.DecodeString(base64Data)
 	if err != nil {
-		log.Printf("Error decodificando audio: %v", err)
-		return
+		return ai.TranscriptionResult{}, fmt.Errorf("error decodificando audio: %w", err)
 	}
 
-	// 2. Guardar los bytes en un archivo temporal .wav.
-	tempFile := filepath.Join(os.TempDir(), "vallet_voice.wav")
-	err = os.WriteFile(tempFile, data, 0644)
+	// 2. Guardar los bytes en un archivo temporal .wav (único para no pisar otro dictado).
+	tempFile, err := a.writeTempAudio(data)
 	if err != nil {
-		log.Printf("Error guardando audio temporal: %v", err)
-		return
+		return ai.TranscriptionResult{}, fmt.Errorf("error guardando audio temporal: %w", err)
 	}
+	defer os.Remove(tempFile)
 
-	// 3. Inicializar el cliente de Whisper y transcribir el archivo.
-	whisper, err := ai.NewWhisperClient()
+	// 3. Transcribir con el backend elegido en la configuración.
+	transcriber, err := a.newTranscriber()
 	if err != nil {
-		log.Printf("Error inicializando Whisper: %v", err)
-		return
+		return ai.TranscriptionResult{}, fmt.Errorf("error inicializando la transcripción: %w", err)
 	}
 
-	text, err := whisper.Transcribe(tempFile)
+	ctx, cancel := context.WithTimeout(a.appContext(), transcriptionTimeout)
+	defer cancel()
+	result, err := transcriber.Transcribe(ctx, ai.Audio{WAV: data, Path: tempFile})
 	if err != nil {
-		log.Printf("Error en la transcripción: %v", err)
-		return
+		return ai.TranscriptionResult{}, fmt.Errorf("error en la transcripción: %w", err)
 	}
 
-	// 4. Si hay texto, pegarlo automáticamente usando las utilidades del sistema.
-	if text != "" {
-		fmt.Printf("📝 Transcripción: %s\n", text)
-		err = utils.PasteText(text)
-		if err != nil {
-			log.Printf("Error pegando texto: %v", err)
-		}
-	} else {
+	// 4. Descartar lo que whisper inventa al grabar silencio o ruido.
+	if result.LowConfidence() {
+		fmt.Printf("⚠️ Transcripción descartada por baja confianza (sin voz %.0f%%): %s\n", result.NoSpeechProb*100, result.Text)
+		return result, nil
+	}
+
+	// 5. Si hay texto, pegarlo automáticamente usando las utilidades del sistema.
+	if result.Text == "" {
 		fmt.Println("⚠️ No se detectó texto en el audio.")
+		return result, nil
+	}
+	if result.Language != "" {
+		fmt.Printf("📝 Transcripción (%s): %s\n", result.Language, result.Text)
+	} else {
+		fmt.Printf("📝 Transcripción: %s\n", result.Text)
+	}
+	if err := a.pasteText(result.Text); err != nil {
+		return result, fmt.Errorf("error pegando texto: %w", err)
+	}
+	return result, nil
+}
+
+// writeTempAudio guarda el audio en un archivo .wav nuevo dentro de a.tempDir (o del
+// directorio temporal del sistema) y devuelve su ruta.
+func (a *App) writeTempAudio(data []byte) (string, error) {
+	dir := a.tempDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	f, err := os.CreateTemp(dir, "vallet_voice_*.wav")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// pasteText introduce el texto en la aplicación activa con a.paste, o con las utilidades del
+// sistema y las opciones de la configuración si no se fijó.
+func (a *App) pasteText(text string) error {
+	if a.paste != nil {
+		return a.paste(text)
 	}
+	return utils.PasteText(text, a.pasteOptions())
+}
+
+// newTranscriber crea el backend de transcripción elegido en la configuración, salvo que
+// se haya fijado uno en a.transcriber (ej: ai.Fake para probar el dictado sin modelo).
+func (a *App) newTranscriber() (ai.Transcriber, error) {
+	if a.transcriber != nil {
+		return a.transcriber, nil
+	}
+
+	backend, _ := a.db.GetSetting("transcriber_backend")
+	if backend == "" {
+		backend = ai.BackendWhisperCLI
+	}
+	serverURL, _ := a.db.GetSetting("whisper_server_url")
+	apiBaseURL, _ := a.db.GetSetting("transcription_api_url")
+	apiKey, _ := a.db.GetSetting("transcription_api_key")
+	apiModel, _ := a.db.GetSetting("transcription_api_model")
+	whisper := a.loadWhisperSettings()
+
+	return ai.New(backend, ai.Config{
+		Language:   whisper.Language,
+		Whisper:    whisper.options(),
+		ServerURL:  serverURL,
+		APIBaseURL: apiBaseURL,
+		APIKey:     apiKey,
+		APIModel:   apiModel,
+	})
+}
+
+// GetTranscriberBackends devuelve los backends de transcripción disponibles.
+func (a *App) GetTranscriberBackends() []string {
+	return ai.Backends()
 }
 
 // App representa la estructura principal de la aplicación Wails.
 type App struct {
-	ctx context.Context // Contexto de la aplicación Wails.
-	db  *Database       // Referencia a la base de datos SQLite.
+	ctx             context.Context      // Contexto de la aplicación Wails.
+	cancel          context.CancelFunc   // Detiene las tareas en segundo plano al cerrar.
+	db              *Database            // Referencia a la base de datos SQLite.
+	reindexFiles    chan struct{}        // Pide reindexar los archivos (ver RefreshFileIndex).
+	reloadClipboard chan struct{}        // Pide releer la configuración del historial del portapapeles.
+	providers       []registeredProvider // Fuentes de resultados del launcher (ver providers.go).
+	transcriber     ai.Transcriber       // Backend de transcripción fijo; si es nil se usa el de la configuración.
+	paste           func(string) error   // Pega el texto dictado; si es nil se usa utils.PasteText.
+	tempDir         string               // Directorio del audio temporal; si está vacío se usa os.TempDir().
+	downloads       *modelDownloads      // Descargas de modelos en curso (ver models.go).
 }
 
 // NewApp crea una nueva instancia de la aplicación.
 func NewApp() *App {
-	return &App{}
+	return &App{
+		reindexFiles:    make(chan struct{}, 1),
+		reloadClipboard: make(chan struct{}, 1),
+		downloads:       newModelDownloads(),
+	}
 }
 
 // startup se ejecuta automáticamente cuando Wails arranca.
@@ -86,9 +236,17 @@ func (a *App) startup(ctx context.Context) {
 		log.Fatal("Error inicializando base de datos:", err)
 	}
 	a.db = db
+	a.registerProviders()
 
 	// Configurar los atajos de teclado globales (Ctrl+Alt+Espacio, etc.).
 	a.setupHotkeys(ctx)
+
+	// Tareas en segundo plano (se cancelan en shutdown).
+	bgCtx, cancel := context.WithCancel(ctx)
+	a.cancel = cancel
+	a.startApplicationIndexer(bgCtx)
+	a.startFileIndexer(bgCtx)
+	a.startClipboardWatcher(bgCtx)
 }
 
 // domReady se ejecuta cuando el frontend (HTML/JS) ha terminado de cargar.
@@ -113,6 +271,11 @@ func (a *App) beforeClose(ctx context.Context) (prevent bool) {
 
 // shutdown se llama al terminar definitivamente la aplicación.
 func (a *App) shutdown(ctx context.Context) {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.downloads.cancelAll()
+	ai.StopManagedServer()
 	if a.db != nil {
 		a.db.Close()
 	}
@@ -141,6 +304,10 @@ func (a *App) openURLWithBrowser(url string) error {
 			cmd = exec.Command("cmd", "/C", "start", "brave", url)
 		case "opera":
 			cmd = exec.Command("cmd", "/C", "start", "opera", url)
+		case "chromium":
+			cmd = exec.Command("cmd", "/C", "start", "chromium", url)
+		case "vivaldi":
+			cmd = exec.Command("cmd", "/C", "start", "vivaldi", url)
 		default:
 			wailsruntime.BrowserOpenURL(a.ctx, url)
 			return nil
@@ -148,43 +315,110 @@ func (a *App) openURLWithBrowser(url string) error {
 		return cmd.Start()
 	}
 
+	// En Linux se busca el ejecutable del navegador en el PATH (los nombres cambian según
+	// la distribución) y se lanza desvinculado para que sobreviva al launcher.
+	if runtime.GOOS == "linux" {
+		for _, name := range linuxBrowsers[browser] {
+			if path, err := exec.LookPath(name); err == nil {
+				return utils.StartDetached("", []string{path, url})
+			}
+		}
+		fmt.Printf("⚠️ Navegador '%s' no encontrado, usando el predeterminado del sistema.\n", browser)
+		return utils.OpenPath(url)
+	}
+
 	wailsruntime.BrowserOpenURL(a.ctx, url)
 	return nil
 }
 
-// OpenSomething procesa la entrada del buscador (un comando, una URL o un alias guardado).
-func (a *App) OpenSomething(input string) {
-	input = strings.TrimSpace(input)
-	if input == "" {
-		return
+// linuxBrowsers son los nombres de ejecutable de cada navegador en las distribuciones Linux.
+var linuxBrowsers = map[string][]string{
+	"chrome":   {"google-chrome", "google-chrome-stable", "chromium", "chromium-browser"},
+	"chromium": {"chromium", "chromium-browser"},
+	"firefox":  {"firefox", "firefox-esr"},
+	"edge":     {"microsoft-edge", "microsoft-edge-stable"},
+	"brave":    {"brave-browser", "brave"},
+	"opera":    {"opera"},
+	"vivaldi":  {"vivaldi", "vivaldi-stable"},
+}
+
+// OpenLink abre una sugerencia elegida en el launcher. Recibe la consulta escrita para
+// aprender qué link se eligió con ella.
+func (a *App) OpenLink(id int, query string) error {
+	link, err := a.db.GetLinkByID(id)
+	if err != nil {
+		return err
 	}
+	action, err := linkAction(*link, query)
+	if err != nil {
+		return err
+	}
+	return a.runAction(action, query)
+}
 
-	// 1. Buscar si el input coincide con un alias de link en la base de datos.
-	links, err := a.db.SearchLinks(input)
-	if err == nil && len(links) > 0 {
-		a.openURLWithBrowser(links[0].URL)
-		wailsruntime.WindowHide(a.ctx)
-		return
+// engineCall indica si la entrada es una búsqueda con palabra clave ("g texto") y devuelve
+// el motor y los términos a buscar. Devuelve nil si la primera palabra no es un motor o si
+// es el alias de un link guardado: el link del usuario tiene prioridad sobre el motor.
+func (a *App) engineCall(input string) (*SearchEngine, []string) {
+	keyword, args := splitKeyword(input)
+	if len(args) == 0 {
+		return nil, nil
+	}
+	isAlias, err := a.db.HasLinkAlias(keyword)
+	if err != nil {
+		log.Printf("Error buscando el alias '%s': %v", keyword, err)
+	} else if isAlias {
+		return nil, nil
+	}
+	engine, err := a.db.GetSearchEngineByKeyword(keyword)
+	if err != nil {
+		log.Printf("Error buscando motor '%s': %v", keyword, err)
+		return nil, nil
+	}
+	return engine, args
+}
+
+// splitKeyword separa la consulta en la primera palabra (alias) y el resto (argumentos).
+func splitKeyword(query string) (keyword string, args []string) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "", nil
 	}
+	return fields[0], fields[1:]
+}
 
-	// 2. Verificar si es una URL directa (ej: google.com).
-	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") || (strings.Contains(input, ".") && !strings.Contains(input, " ")) {
-		url := input
-		if !strings.HasPrefix(input, "http") {
-			url = "https://" + input
-		}
-		a.openURLWithBrowser(url)
-	} else {
-		// 3. Intentar ejecutarlo como un comando del sistema (abrir apps como 'notepad').
-		if runtime.GOOS == "windows" {
-			exec.Command("cmd", "/C", "start", "", input).Start()
-		} else {
-			exec.Command("open", input).Start()
+// aliasArgs indica si la consulta empieza con el alias completo de un link, palabra por
+// palabra y sin distinguir mayúsculas, y devuelve las palabras que siguen como argumentos.
+// Así un alias de varias palabras ("mi wiki") no se confunde con su primera palabra.
+func aliasArgs(query, alias string) (args []string, ok bool) {
+	words := strings.Fields(alias)
+	fields := strings.Fields(query)
+	if len(words) == 0 || len(fields) < len(words) {
+		return nil, false
+	}
+	for i, word := range words {
+		if !strings.EqualFold(fields[i], word) {
+			return nil, false
 		}
 	}
+	return fields[len(words):], true
+}
+
+// expandLinkURL devuelve la URL final de un link. Las plantillas reciben como argumentos
+// las palabras que siguen al alias en la consulta; sin argumentos se usan los valores por
+// defecto de la plantilla.
+func expandLinkURL(link Link, query string) (string, error) {
+	if link.Type == ActionSnippet || !templates.HasPlaceholders(link.URL) {
+		return link.URL, nil
+	}
 
-	// Ocultar la ventana del launcher después de realizar la acción.
-	wailsruntime.WindowHide(a.ctx)
+	tmpl, err := templates.Parse(link.URL)
+	if err != nil {
+		return "", err
+	}
+
+	args, _ := aliasArgs(query, link.Name)
+	return tmpl.Expand(args)
 }
 
 // HideWindow oculta la ventana principal.
@@ -251,8 +485,12 @@ func (a *App) GetLinkByID(id int) (*Link, error) {
 	return a.db.GetLinkByID(id)
 }
 
-// SearchLinks busca links que coincidan con el texto ingresado.
+// SearchLinks busca links que coincidan con el texto ingresado. Las búsquedas con palabra
+// clave ("g texto") no muestran links, así Enter las envía al motor de búsqueda.
 func (a *App) SearchLinks(query string) ([]Link, error) {
+	if engine, _ := a.engineCall(query); engine != nil {
+		return []Link{}, nil
+	}
 	return a.db.SearchLinks(query)
 }
 
@@ -271,6 +509,50 @@ func (a *App) DeleteLink(id int) error {
 	return a.db.DeleteLink(id)
 }
 
+// ResetLinkLearning olvida las consultas con las que se ha elegido un link.
+func (a *App) ResetLinkLearning(id int) error {
+	return a.db.ResetQueryPicks(id)
+}
+
+// ============ Operaciones CRUD para Motores de Búsqueda ============
+
+// GetAllSearchEngines obtiene los motores de búsqueda con palabra clave.
+func (a *App) GetAllSearchEngines() ([]SearchEngine, error) {
+	return a.db.GetAllSearchEngines()
+}
+
+// CreateSearchEngine agrega un motor de búsqueda (ej: "yt" -> YouTube).
+func (a *App) CreateSearchEngine(engine SearchEngine) (int64, error) {
+	return a.db.CreateSearchEngine(engine)
+}
+
+// UpdateSearchEngine actualiza un motor de búsqueda existente.
+func (a *App) UpdateSearchEngine(engine SearchEngine) error {
+	return a.db.UpdateSearchEngine(engine)
+}
+
+// DeleteSearchEngine elimina un motor de búsqueda.
+func (a *App) DeleteSearchEngine(id int) error {
+	return a.db.DeleteSearchEngine(id)
+}
+
+// SetDefaultSearchEngine elige el motor con el que se busca el texto libre del launcher.
+// Una palabra clave vacía desactiva la búsqueda por defecto.
+func (a *App) SetDefaultSearchEngine(keyword string) error {
+	keyword = strings.TrimSpace(keyword)
+	if keyword != "" {
+		engine, err := a.db.GetSearchEngineByKeyword(keyword)
+		if err != nil {
+			return err
+		}
+		if engine == nil {
+			return fmt.Errorf("no existe un motor de búsqueda con la palabra clave '%s'", keyword)
+		}
+		keyword = engine.Keyword
+	}
+	return a.db.UpdateSetting("default_search_engine", keyword)
+}
+
 // GetSettingBackend recupera un valor de configuración desde la base de datos.
 func (a *App) GetSettingBackend(key string) (string, error) {
 	return a.db.GetSetting(key)
@@ -278,7 +560,23 @@ func (a *App) GetSettingBackend(key string) (string, error) {
 
 // UpdateSettingBackend actualiza o crea un valor de configuración.
 func (a *App) UpdateSettingBackend(key, value string) error {
-	return a.db.UpdateSetting(key, value)
+	if err := a.validateWhisperSetting(key, value); err != nil {
+		return err
+	}
+	if err := validatePasteSetting(key, value); err != nil {
+		return err
+	}
+	if err := a.db.UpdateSetting(key, value); err != nil {
+		return err
+	}
+	// Los cambios en la búsqueda de archivos se aplican reindexando.
+	if strings.HasPrefix(key, "file_search_") {
+		a.RefreshFileIndex()
+	}
+	if strings.HasPrefix(key, "clipboard_history_") {
+		a.reloadClipboardHistorySettings()
+	}
+	return nil
 }
 
 // ============ Operaciones CRUD para Carpetas (Folders) ============
@@ -298,11 +596,21 @@ func (a *App) UpdateFolder(folder Folder) error {
 	return a.db.UpdateFolder(folder)
 }
 
-// DeleteFolder elimina una carpeta.
+// MoveFolder mueve una carpeta dentro de otra (o al primer nivel si parentID es 0).
+func (a *App) MoveFolder(id, parentID int) error {
+	return a.db.MoveFolder(id, parentID)
+}
+
+// DeleteFolder elimina una carpeta y sus subcarpetas; sus links pasan a la carpeta padre.
 func (a *App) DeleteFolder(id int) error {
 	return a.db.DeleteFolder(id)
 }
 
+// GetFolderTree obtiene la jerarquía completa de carpetas para el panel de administración.
+func (a *App) GetFolderTree() ([]FolderNode, error) {
+	return a.db.GetFolderTree()
+}
+
 // LogToolUsage registra en la base de datos el uso de una herramienta.
 func (a *App) LogToolUsage(toolType string) {
 	dayOfWeek := getSpanishDay()
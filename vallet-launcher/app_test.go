@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"vallet-launcher/ai"
+)
+
+// newDictationApp crea una App con el backend de transcripción dado y un pegado que
+// guarda los textos en pasted, sin base de datos ni teclado.
+func newDictationApp(t *testing.T, fake *ai.Fake, pasted *[]string) *App {
+	t.Helper()
+	return &App{
+		transcriber: fake,
+		tempDir:     t.TempDir(),
+		paste: func(text string) error {
+			*pasted = append(*pasted, text)
+			return nil
+		},
+	}
+}
+
+func TestProcessAudioTranscribesAndPastes(t *testing.T) {
+	wav := []byte("RIFF....WAVEfmt ")
+	fake := &ai.Fake{Result: ai.TranscriptionResult{Text: "hola mundo", Language: "es"}}
+	var pasted []string
+	app := newDictationApp(t, fake, &pasted)
+
+	result, err := app.ProcessAudio(base64.StdEncoding.EncodeToString(wav))
+	if err != nil {
+		t.Fatalf("ProcessAudio: %v", err)
+	}
+	if result.Text != "hola mundo" || result.Language != "es" {
+		t.Errorf("resultado = %+v", result)
+	}
+	if len(pasted) != 1 || pasted[0] != "hola mundo" {
+		t.Errorf("pegado = %v, se esperaba [hola mundo]", pasted)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("llamadas al backend = %d, se esperaba 1", len(calls))
+	}
+	if !bytes.Equal(calls[0].WAV, wav) {
+		t.Errorf("audio recibido = %q, se esperaba %q", calls[0].WAV, wav)
+	}
+	if filepath.Dir(calls[0].Path) != app.tempDir {
+		t.Errorf("audio temporal en %s, se esperaba dentro de %s", calls[0].Path, app.tempDir)
+	}
+	if _, err := os.Stat(calls[0].Path); !os.IsNotExist(err) {
+		t.Errorf("el audio temporal %s no se borró", calls[0].Path)
+	}
+}
+
+func TestProcessAudioSkipsLowConfidence(t *testing.T) {
+	fake := &ai.Fake{Result: ai.TranscriptionResult{
+		Text:         "Gracias por ver el video",
+		Segments:     []ai.Segment{{End: time.Second, Text: "Gracias por ver el video"}},
+		AvgLogProb:   -1.5,
+		NoSpeechProb: 0.9,
+	}}
+	var pasted []string
+	app := newDictationApp(t, fake, &pasted)
+
+	result, err := app.ProcessAudio(base64.StdEncoding.EncodeToString([]byte("RIFF")))
+	if err != nil {
+		t.Fatalf("ProcessAudio: %v", err)
+	}
+	if !result.LowConfidence() {
+		t.Errorf("resultado = %+v, se esperaba baja confianza", result)
+	}
+	if len(pasted) != 0 {
+		t.Errorf("pegado = %v, no se debía pegar", pasted)
+	}
+}
+
+func TestProcessAudioErrors(t *testing.T) {
+	var pasted []string
+	app := newDictationApp(t, &ai.Fake{Err: errors.New("modelo roto")}, &pasted)
+
+	if _, err := app.ProcessAudio("no es base64"); err == nil {
+		t.Error("ProcessAudio aceptó un audio que no es base64")
+	}
+	if _, err := app.ProcessAudio(base64.StdEncoding.EncodeToString([]byte("RIFF"))); err == nil {
+		t.Error("ProcessAudio no devolvió el error del backend")
+	}
+	if len(pasted) != 0 {
+		t.Errorf("pegado = %v, no se debía pegar", pasted)
+	}
+
+	app = newDictationApp(t, &ai.Fake{Result: ai.TranscriptionResult{Text: "hola"}}, &pasted)
+	app.paste = func(string) error { return errors.New("sin foco") }
+	result, err := app.ProcessAudio(base64.StdEncoding.EncodeToString([]byte("RIFF")))
+	if err == nil {
+		t.Error("ProcessAudio no devolvió el error del pegado")
+	}
+	if result.Text != "hola" {
+		t.Errorf("resultado = %+v, se esperaba la transcripción aunque falle el pegado", result)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"time"
+
+	"vallet-launcher/desktop"
+)
+
+// applicationRescanInterval es cada cuánto se revisan los archivos .desktop. Solo se
+// comparan fechas y tamaños, así que revisar a menudo es barato.
+const applicationRescanInterval = time.Minute
+
+// supportsDesktopEntries indica si el sistema usa archivos .desktop (Linux y BSD).
+func supportsDesktopEntries() bool {
+	return runtime.GOOS != "windows" && runtime.GOOS != "darwin"
+}
+
+// startApplicationIndexer indexa las aplicaciones instaladas al arrancar y vuelve a
+// revisarlas periódicamente hasta que se cancela el contexto.
+func (a *App) startApplicationIndexer(ctx context.Context) {
+	if !supportsDesktopEntries() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(applicationRescanInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := a.RefreshApplications(); err != nil {
+				fmt.Printf("❌ Error indexando aplicaciones: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RefreshApplications vuelve a leer los archivos .desktop que cambiaron y devuelve
+// cuántas aplicaciones se agregaron, actualizaron o eliminaron del índice.
+func (a *App) RefreshApplications() (int, error) {
+	if !supportsDesktopEntries() {
+		return 0, nil
+	}
+
+	changes, err := a.db.SyncApplications(desktop.Scan())
+	if err != nil {
+		return 0, err
+	}
+	if changes > 0 {
+		fmt.Printf("📦 Índice de aplicaciones actualizado (%d cambios).\n", changes)
+	}
+	return changes, nil
+}
+
+// applicationProvider busca entre las aplicaciones instaladas.
+type applicationProvider struct {
+	app *App
+}
+
+func (p *applicationProvider) Name() string { return SourceApplication }
+
+func (p *applicationProvider) Search(ctx context.Context, query string) []Result {
+	apps, err := p.app.db.GetApplications()
+	if err != nil {
+		log.Println("Error leyendo aplicaciones:", err)
+		return nil
+	}
+
+	links := make([]Link, len(apps))
+	for i, app := range apps {
+		links[i] = applicationLink(app)
+	}
+
+	var results []Result
+	for _, s := range scoreLinks(query, links, rankSignals{}) {
+		subtitle := "Aplicación"
+		if s.link.Description != "" {
+			subtitle += " · " + s.link.Description
+		}
+		results = append(results, linkResult(s, subtitle))
+	}
+	return results
+}
+
+// Execute lanza la aplicación elegida.
+func (p *applicationProvider) Execute(result Result) error {
+	app, err := p.app.db.GetApplicationByID(result.ID)
+	if err != nil {
+		return err
+	}
+	action, err := linkAction(applicationLink(*app), result.Query)
+	if err != nil {
+		return err
+	}
+	return p.app.runAction(action, result.Query)
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package audio
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+)
+
+// players son los reproductores de línea de comandos probados en orden.
+var players = []string{"paplay", "pw-play", "aplay", "afplay"}
+
+// PlayWav reproduce un archivo .wav de forma asíncrona con el primer reproductor disponible.
+func PlayWav(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	for _, player := range players {
+		if p, err := exec.LookPath(player); err == nil {
+			cmd := exec.Command(p, absPath)
+			if err := cmd.Start(); err != nil {
+				return err
+			}
+			go cmd.Wait()
+			return nil
+		}
+	}
+	return fmt.Errorf("no se encontró un reproductor de audio (%v)", players)
+}
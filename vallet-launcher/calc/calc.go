@@ -0,0 +1,181 @@
+package calc
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Answer es el resultado de una consulta a la calculadora.
+type Answer struct {
+	Value float64 // Valor numérico del resultado.
+	Text  string  // Resultado formateado para mostrar (con unidad si es una conversión).
+	Copy  string  // Valor que se copia al portapapeles (sin la unidad).
+}
+
+var (
+	// datePattern reconoce fechas ("2024-01-05", "12/05/2024", "5.1.24"), que no se deben
+	// restar ni dividir.
+	datePattern = regexp.MustCompile(`^(?:\d{4}[-/.]\d{1,2}[-/.]\d{1,2}|\d{1,2}[-/.]\d{1,2}[-/.]\d{2,4})$`)
+	// phonePattern reconoce grupos de dígitos unidos por guiones o espacios, con prefijo
+	// internacional opcional ("555-1234", "+34 600 123 456").
+	phonePattern = regexp.MustCompile(`^\+?\d+(?:[- ]\d+)+$`)
+	// arrowPattern separa "<expresión> <unidad> -> <unidad>".
+	arrowPattern = regexp.MustCompile(`^(.+?)\s*(?:->|=>)\s*(.+)$`)
+)
+
+// conversionKeywords separan el valor de la unidad destino ("5 km in mi", "100 f a c").
+var conversionKeywords = map[string]bool{"in": true, "to": true, "a": true, "en": true, "as": true, "como": true}
+
+// Calculate interpreta la entrada del launcher como una operación ("1920*1080",
+// "200 + 15%"), una conversión de unidades ("5 km in mi", "100 f a c") o un cambio de base
+// ("255 in hex"). Devuelve false si la entrada no parece un cálculo.
+func Calculate(input string) (Answer, bool) {
+	input = strings.TrimSpace(input)
+	input = strings.TrimPrefix(input, "=")
+	if input == "" {
+		return Answer{}, false
+	}
+
+	if answer, ok := convertKeyword(input); ok {
+		return answer, true
+	}
+	if m := arrowPattern.FindStringSubmatch(input); m != nil {
+		if answer, ok := convert(strings.TrimSpace(m[1]), strings.TrimSpace(m[2])); ok {
+			return answer, true
+		}
+	}
+
+	if !looksLikeMath(input) {
+		return Answer{}, false
+	}
+	v, err := Evaluate(input)
+	if err != nil {
+		return Answer{}, false
+	}
+	text := FormatNumber(v)
+	return Answer{Value: v, Text: text, Copy: text}, true
+}
+
+// convertKeyword prueba cada palabra clave de conversión empezando por la derecha y se
+// queda con la primera división en la que origen y destino se reconocen. Así "in" puede ser
+// a la vez la pulgada y la palabra clave ("12 in in cm", "5 ft in in").
+func convertKeyword(input string) (Answer, bool) {
+	fields := strings.Fields(input)
+	for i := len(fields) - 2; i >= 1; i-- {
+		if !conversionKeywords[fields[i]] {
+			continue
+		}
+		left, target := strings.Join(fields[:i], " "), strings.Join(fields[i+1:], " ")
+		if answer, ok := convert(left, target); ok {
+			return answer, true
+		}
+	}
+	return Answer{}, false
+}
+
+// convert resuelve una conversión de unidades o de base numérica.
+func convert(left, target string) (Answer, bool) {
+	// Cambio de base: "255 in hex", "0xff to bin".
+	if base, prefix, ok := numberBase(target); ok {
+		v, err := Evaluate(left)
+		if err != nil || v != math.Trunc(v) || math.Abs(v) >= 1<<63 {
+			return Answer{}, false
+		}
+		text := strconv.FormatInt(int64(v), base)
+		if v < 0 {
+			text = "-" + prefix + text[1:]
+		} else {
+			text = prefix + text
+		}
+		return Answer{Value: v, Text: text, Copy: text}, true
+	}
+
+	v, from, ok := splitValueUnit(left)
+	if !ok {
+		return Answer{}, false
+	}
+	to, ok := lookupUnit(target)
+	if !ok || from.category != to.category {
+		return Answer{}, false
+	}
+
+	result := to.from(from.base(v))
+	text := FormatNumber(result)
+	return Answer{Value: result, Text: text + " " + to.symbol, Copy: text}, true
+}
+
+// splitValueUnit separa la expresión de la unidad que la sigue ("5*2 km", "100°F"),
+// probando primero la unidad más larga posible.
+func splitValueUnit(left string) (float64, unit, bool) {
+	for i := range left {
+		if i == 0 {
+			continue
+		}
+		u, ok := lookupUnit(left[i:])
+		if !ok {
+			continue
+		}
+		if v, err := Evaluate(left[:i]); err == nil {
+			return v, u, true
+		}
+	}
+	return 0, unit{}, false
+}
+
+// numberBase reconoce los destinos hex, bin, oct y dec.
+func numberBase(target string) (int, string, bool) {
+	switch strings.ToLower(target) {
+	case "hex", "hexadecimal":
+		return 16, "0x", true
+	case "bin", "binary", "binario":
+		return 2, "0b", true
+	case "oct", "octal":
+		return 8, "0o", true
+	case "dec", "decimal":
+		return 10, "", true
+	}
+	return 0, "", false
+}
+
+// minPhoneDigits es a partir de cuántos dígitos unos grupos unidos por guiones se leen
+// como un teléfono y no como una resta ("555-1234"; para restar, "1000 - 250").
+const minPhoneDigits = 7
+
+// looksLikeMath evita tratar como cálculo un número suelto, una palabra, una fecha o un
+// teléfono: exige al menos un dígito y algún operador, paréntesis o función, o un literal
+// hexadecimal/binario.
+func looksLikeMath(input string) bool {
+	digits := 0
+	for _, r := range input {
+		if unicode.IsDigit(r) {
+			digits++
+		}
+	}
+	if digits == 0 || datePattern.MatchString(input) {
+		return false
+	}
+	if phonePattern.MatchString(input) && digits >= minPhoneDigits {
+		return false
+	}
+	lower := strings.ToLower(input)
+	if strings.HasPrefix(lower, "0x") || strings.HasPrefix(lower, "0b") || strings.HasPrefix(lower, "0o") {
+		return true
+	}
+	return strings.ContainsAny(input, "+-*/^%()×÷") || strings.IndexFunc(input, unicode.IsLetter) >= 0
+}
+
+// FormatNumber formatea un resultado con hasta 12 cifras significativas y sin ceros
+// sobrantes ("0.1+0.2" muestra 0.3).
+func FormatNumber(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(v, 'g', 12, 64)
+	if strings.ContainsAny(s, "eE") {
+		return s
+	}
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
@@ -0,0 +1,75 @@
+package calc
+
+import "testing"
+
+func TestCalculateBaseConversion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"255 in hex", "0xff", true},
+		{"-10 in bin", "-0b1010", true},
+		{"9223372036854775807 in hex", "", false}, // Se redondea a 2^63 al pasar por float64.
+		{"2^63 in hex", "", false},
+		{"-2^63 in hex", "", false},
+		{"2^62 in hex", "0x4000000000000000", true},
+		{"1.5 in hex", "", false},
+	}
+	for _, tt := range tests {
+		answer, ok := Calculate(tt.input)
+		if ok != tt.ok || answer.Text != tt.want {
+			t.Errorf("Calculate(%q) = %q, %v; se esperaba %q, %v", tt.input, answer.Text, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCalculateRejectsDatesAndPhones(t *testing.T) {
+	for _, input := range []string{
+		"2024-01-05", "12/05/2024", "5/1/24", "2024/5/1", "12.05.2024",
+		"555-1234", "600-123-456", "+34 600 123 456", "91 555 12 34",
+		"42", "hola", "",
+	} {
+		if answer, ok := Calculate(input); ok {
+			t.Errorf("Calculate(%q) = %q, no se esperaba un cálculo", input, answer.Text)
+		}
+	}
+}
+
+func TestCalculateOperations(t *testing.T) {
+	tests := map[string]string{
+		"10-5":       "5",
+		"1000 - 250": "750",
+		"12 / 4":     "3",
+		"(555-1234)": "-679",
+		"1920*1080":  "2073600",
+		"200 + 15%":  "230",
+		"0xff":       "255",
+		"5 km in m":  "5000 m",
+		"=2+2":       "4",
+		"1/2/3":      "0.166666666667",
+		"100-20":     "80",
+	}
+	for input, want := range tests {
+		answer, ok := Calculate(input)
+		if !ok || answer.Text != want {
+			t.Errorf("Calculate(%q) = %q, %v; se esperaba %q", input, answer.Text, ok, want)
+		}
+	}
+}
+
+func TestCalculateInchConversions(t *testing.T) {
+	tests := map[string]string{
+		"12 in to cm": "30.48 cm",
+		"12 in in cm": "30.48 cm",
+		"5 ft in in":  "60 in",
+		"1 in -> mm":  "25.4 mm",
+		"5 km in mi":  "3.10685596119 mi",
+	}
+	for input, want := range tests {
+		answer, ok := Calculate(input)
+		if !ok || answer.Text != want {
+			t.Errorf("Calculate(%q) = %q, %v; se esperaba %q", input, answer.Text, ok, want)
+		}
+	}
+}
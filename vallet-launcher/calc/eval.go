@@ -0,0 +1,459 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Evaluate calcula una expresión aritmética. Soporta + - * / ^ (o **), paréntesis,
+// módulo (10 % 3 o 10 mod 3), porcentajes (50%, 200 + 10%), literales hexadecimales
+// (0xff), binarios (0b1010) y octales (0o17), las constantes pi y e, y funciones comunes
+// (sqrt, abs, sin, cos, tan, ln, log, exp, round, floor, ceil, min, max...).
+func Evaluate(expr string) (float64, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return 0, err
+	}
+	if len(tokens) == 0 {
+		return 0, fmt.Errorf("expresión vacía")
+	}
+	p := &parser{tokens: tokens}
+
+	v, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	if p.pos < len(p.tokens) {
+		return 0, fmt.Errorf("símbolo inesperado %q", p.tokens[p.pos].text)
+	}
+	if math.IsNaN(v.value) || math.IsInf(v.value, 0) {
+		return 0, fmt.Errorf("resultado no definido")
+	}
+	return v.value, nil
+}
+
+type tokenKind int
+
+const (
+	tokNumber tokenKind = iota
+	tokIdent
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+}
+
+// tokenize separa la expresión en números, identificadores, operadores y paréntesis.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			if r == '0' && i+1 < len(runes) && strings.ContainsRune("xXbBoO", runes[i+1]) {
+				i += 2
+				for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune("abcdefABCDEF_", runes[i])) {
+					i++
+				}
+				text := string(runes[start:i])
+				n, err := strconv.ParseInt(strings.ReplaceAll(text, "_", ""), 0, 64)
+				if err != nil {
+					return nil, fmt.Errorf("número inválido %q", text)
+				}
+				tokens = append(tokens, token{kind: tokNumber, text: text, value: float64(n)})
+				continue
+			}
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			// Notación científica: 1e6, 2.5E-3.
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for j < len(runes) && unicode.IsDigit(runes[j]) {
+						j++
+					}
+					i = j
+				}
+			}
+			text := string(runes[start:i])
+			n, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+			if err != nil {
+				return nil, fmt.Errorf("número inválido %q", text)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, value: n})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: strings.ToLower(string(runes[start:i]))})
+
+		case r == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			tokens = append(tokens, token{kind: tokOp, text: "^"})
+			i += 2
+
+		case strings.ContainsRune("+-*/^%×÷−", r):
+			op := string(r)
+			switch r {
+			case '×':
+				op = "*"
+			case '÷':
+				op = "/"
+			case '−':
+				op = "-"
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op})
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")"})
+			i++
+		case r == ',' || r == ';':
+			tokens = append(tokens, token{kind: tokComma, text: ","})
+			i++
+
+		default:
+			return nil, fmt.Errorf("carácter no válido %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+// operand es un valor intermedio. percent indica que se escribió como porcentaje ("10%"),
+// para que "200 + 10%" sume el 10% de 200.
+type operand struct {
+	value   float64
+	percent bool
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t == nil || t.kind != tokOp {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseExpr: term (('+' | '-') term)*
+func (p *parser) parseExpr() (operand, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return operand{}, err
+	}
+	for p.isOp("+", "-") {
+		op := p.tokens[p.pos].text
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return operand{}, err
+		}
+
+		// "a + b%" suma el b% de a; "a - b%" lo resta.
+		delta := right.value
+		if right.percent && !left.percent {
+			delta = left.value * right.value
+		}
+		if op == "+" {
+			left = operand{value: left.value + delta}
+		} else {
+			left = operand{value: left.value - delta}
+		}
+	}
+	return left, nil
+}
+
+// parseTerm: unary (('*' | '/' | '%' | 'mod') unary)*
+func (p *parser) parseTerm() (operand, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return operand{}, err
+	}
+	for {
+		var op string
+		switch t := p.peek(); {
+		case p.isOp("*", "/", "%"):
+			op = t.text
+		case t != nil && t.kind == tokIdent && t.text == "mod":
+			op = "%"
+		case t != nil && (t.kind == tokLParen || t.kind == tokIdent && isFunctionOrConstant(t.text)):
+			// Multiplicación implícita: 2(3+4), 2pi.
+			op = "implicit"
+		default:
+			return left, nil
+		}
+		if op != "implicit" {
+			p.pos++
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return operand{}, err
+		}
+		switch op {
+		case "*", "implicit":
+			left = operand{value: left.value * right.value}
+		case "/":
+			if right.value == 0 {
+				return operand{}, fmt.Errorf("división por cero")
+			}
+			left = operand{value: left.value / right.value}
+		case "%":
+			if right.value == 0 {
+				return operand{}, fmt.Errorf("división por cero")
+			}
+			left = operand{value: math.Mod(left.value, right.value)}
+		}
+	}
+}
+
+// parseUnary: ('-' | '+') unary | power
+func (p *parser) parseUnary() (operand, error) {
+	if p.isOp("-", "+") {
+		neg := p.tokens[p.pos].text == "-"
+		p.pos++
+		v, err := p.parseUnary()
+		if err != nil {
+			return operand{}, err
+		}
+		if neg {
+			v.value = -v.value
+		}
+		return v, nil
+	}
+	return p.parsePower()
+}
+
+// parsePower: postfix ('^' unary)?  (asociativa por la derecha: 2^3^2 = 2^9)
+func (p *parser) parsePower() (operand, error) {
+	base, err := p.parsePostfix()
+	if err != nil {
+		return operand{}, err
+	}
+	if p.isOp("^") {
+		p.pos++
+		exp, err := p.parseUnary()
+		if err != nil {
+			return operand{}, err
+		}
+		return operand{value: math.Pow(base.value, exp.value)}, nil
+	}
+	return base, nil
+}
+
+// parsePostfix: primary '%'?  El '%' es porcentaje si no le sigue un operando (si le
+// sigue, es el operador módulo y lo consume parseTerm).
+func (p *parser) parsePostfix() (operand, error) {
+	v, err := p.parsePrimary()
+	if err != nil {
+		return operand{}, err
+	}
+	if p.isOp("%") && !p.operandFollows(p.pos+1) {
+		p.pos++
+		return operand{value: v.value / 100, percent: true}, nil
+	}
+	return v, nil
+}
+
+// operandFollows indica si en la posición i empieza un operando.
+func (p *parser) operandFollows(i int) bool {
+	if i >= len(p.tokens) {
+		return false
+	}
+	switch t := p.tokens[i]; t.kind {
+	case tokNumber, tokLParen:
+		return true
+	case tokIdent:
+		return t.text != "mod"
+	case tokOp:
+		// "10 % -3": un signo seguido de operando.
+		return (t.text == "-" || t.text == "+") && p.operandFollows(i+1)
+	}
+	return false
+}
+
+// parsePrimary: número | '(' expr ')' | constante | función '(' args ')'
+func (p *parser) parsePrimary() (operand, error) {
+	t := p.peek()
+	if t == nil {
+		return operand{}, fmt.Errorf("expresión incompleta")
+	}
+
+	switch t.kind {
+	case tokNumber:
+		p.pos++
+		return operand{value: t.value}, nil
+
+	case tokLParen:
+		p.pos++
+		v, err := p.parseExpr()
+		if err != nil {
+			return operand{}, err
+		}
+		if t := p.peek(); t == nil || t.kind != tokRParen {
+			return operand{}, fmt.Errorf("falta ')'")
+		}
+		p.pos++
+		return v, nil
+
+	case tokIdent:
+		p.pos++
+		if c, ok := constants[t.text]; ok {
+			return operand{value: c}, nil
+		}
+		fn, ok := functions[t.text]
+		if !ok {
+			return operand{}, fmt.Errorf("función desconocida %q", t.text)
+		}
+		args, err := p.parseArgs()
+		if err != nil {
+			return operand{}, err
+		}
+		if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+			return operand{}, fmt.Errorf("número de argumentos incorrecto para %s", t.text)
+		}
+		return operand{value: fn.call(args)}, nil
+	}
+
+	return operand{}, fmt.Errorf("símbolo inesperado %q", t.text)
+}
+
+// parseArgs lee la lista de argumentos de una función. Sin paréntesis se acepta un único
+// argumento ("sqrt 16").
+func (p *parser) parseArgs() ([]float64, error) {
+	if t := p.peek(); t == nil || t.kind != tokLParen {
+		v, err := p.parsePower()
+		if err != nil {
+			return nil, err
+		}
+		return []float64{v.value}, nil
+	}
+	p.pos++
+
+	var args []float64
+	for {
+		v, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v.value)
+
+		t := p.peek()
+		if t == nil {
+			return nil, fmt.Errorf("falta ')'")
+		}
+		p.pos++
+		if t.kind == tokRParen {
+			return args, nil
+		}
+		if t.kind != tokComma {
+			return nil, fmt.Errorf("se esperaba ',' o ')'")
+		}
+	}
+}
+
+var constants = map[string]float64{
+	"pi":  math.Pi,
+	"π":   math.Pi,
+	"e":   math.E,
+	"tau": 2 * math.Pi,
+	"phi": math.Phi,
+}
+
+type function struct {
+	minArgs int
+	maxArgs int // -1 = sin límite.
+	call    func(args []float64) float64
+}
+
+func unary(f func(float64) float64) function {
+	return function{minArgs: 1, maxArgs: 1, call: func(a []float64) float64 { return f(a[0]) }}
+}
+
+var functions = map[string]function{
+	"sqrt":  unary(math.Sqrt),
+	"cbrt":  unary(math.Cbrt),
+	"abs":   unary(math.Abs),
+	"sin":   unary(math.Sin),
+	"cos":   unary(math.Cos),
+	"tan":   unary(math.Tan),
+	"asin":  unary(math.Asin),
+	"acos":  unary(math.Acos),
+	"atan":  unary(math.Atan),
+	"sinh":  unary(math.Sinh),
+	"cosh":  unary(math.Cosh),
+	"tanh":  unary(math.Tanh),
+	"ln":    unary(math.Log),
+	"log":   unary(math.Log10),
+	"log2":  unary(math.Log2),
+	"exp":   unary(math.Exp),
+	"floor": unary(math.Floor),
+	"ceil":  unary(math.Ceil),
+	"round": unary(math.Round),
+	"trunc": unary(math.Trunc),
+	"rad":   unary(func(x float64) float64 { return x * math.Pi / 180 }),
+	"deg":   unary(func(x float64) float64 { return x * 180 / math.Pi }),
+	"pow": {minArgs: 2, maxArgs: 2, call: func(a []float64) float64 {
+		return math.Pow(a[0], a[1])
+	}},
+	"min": {minArgs: 1, maxArgs: -1, call: func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	}},
+	"max": {minArgs: 1, maxArgs: -1, call: func(a []float64) float64 {
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	}},
+}
+
+// isFunctionOrConstant indica si el identificador es una función o constante conocida.
+func isFunctionOrConstant(name string) bool {
+	_, isFn := functions[name]
+	_, isConst := constants[name]
+	return isFn || isConst
+}
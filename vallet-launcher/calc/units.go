@@ -0,0 +1,150 @@
+package calc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Categorías de unidades. Solo se convierte entre unidades de la misma categoría.
+const (
+	Length      = "longitud"
+	Mass        = "masa"
+	Temperature = "temperatura"
+	Data        = "datos"
+	Time        = "tiempo"
+)
+
+// unit es una unidad de medida. factor convierte a la unidad base de su categoría
+// (metro, kilogramo, byte, segundo); las temperaturas usan toBase/fromBase (Kelvin).
+type unit struct {
+	symbol   string
+	category string
+	factor   float64
+	toBase   func(float64) float64
+	fromBase func(float64) float64
+}
+
+func (u unit) base(v float64) float64 {
+	if u.toBase != nil {
+		return u.toBase(v)
+	}
+	return v * u.factor
+}
+
+func (u unit) from(v float64) float64 {
+	if u.fromBase != nil {
+		return u.fromBase(v)
+	}
+	return v / u.factor
+}
+
+// unitList define las unidades con sus alias. El primer alias es el símbolo que se muestra.
+var unitList = []struct {
+	aliases  []string
+	category string
+	factor   float64
+}{
+	// Longitud (base: metro).
+	{[]string{"mm", "milímetro", "milímetros", "millimeter", "millimeters"}, Length, 0.001},
+	{[]string{"cm", "centímetro", "centímetros", "centimeter", "centimeters"}, Length, 0.01},
+	{[]string{"m", "metro", "metros", "meter", "meters"}, Length, 1},
+	{[]string{"km", "kilómetro", "kilómetros", "kilometer", "kilometers"}, Length, 1000},
+	{[]string{"in", "pulgada", "pulgadas", "inch", "inches", `"`}, Length, 0.0254},
+	{[]string{"ft", "pie", "pies", "foot", "feet"}, Length, 0.3048},
+	{[]string{"yd", "yarda", "yardas", "yard", "yards"}, Length, 0.9144},
+	{[]string{"mi", "milla", "millas", "mile", "miles"}, Length, 1609.344},
+	{[]string{"nmi", "milla náutica", "nautical mile"}, Length, 1852},
+
+	// Masa (base: kilogramo).
+	{[]string{"mg", "miligramo", "miligramos", "milligram", "milligrams"}, Mass, 1e-6},
+	{[]string{"g", "gramo", "gramos", "gram", "grams"}, Mass, 0.001},
+	{[]string{"kg", "kilo", "kilos", "kilogramo", "kilogramos", "kilogram", "kilograms"}, Mass, 1},
+	{[]string{"t", "tonelada", "toneladas", "tonne", "tonnes"}, Mass, 1000},
+	{[]string{"oz", "onza", "onzas", "ounce", "ounces"}, Mass, 0.028349523125},
+	{[]string{"lb", "lbs", "libra", "libras", "pound", "pounds"}, Mass, 0.45359237},
+	{[]string{"st", "stone", "stones"}, Mass, 6.35029318},
+
+	// Datos (base: byte). KB, MB... son decimales; KiB, MiB... binarios.
+	{[]string{"bit", "bits"}, Data, 0.125},
+	{[]string{"B", "byte", "bytes"}, Data, 1},
+	{[]string{"KB", "kb", "kilobyte", "kilobytes"}, Data, 1e3},
+	{[]string{"MB", "mb", "megabyte", "megabytes"}, Data, 1e6},
+	{[]string{"GB", "gb", "gigabyte", "gigabytes"}, Data, 1e9},
+	{[]string{"TB", "tb", "terabyte", "terabytes"}, Data, 1e12},
+	{[]string{"PB", "pb", "petabyte", "petabytes"}, Data, 1e15},
+	{[]string{"KiB", "kib", "kibibyte", "kibibytes"}, Data, 1 << 10},
+	{[]string{"MiB", "mib", "mebibyte", "mebibytes"}, Data, 1 << 20},
+	{[]string{"GiB", "gib", "gibibyte", "gibibytes"}, Data, 1 << 30},
+	{[]string{"TiB", "tib", "tebibyte", "tebibytes"}, Data, 1 << 40},
+	{[]string{"Kb", "kbit", "kilobit", "kilobits"}, Data, 1e3 / 8},
+	{[]string{"Mb", "mbit", "megabit", "megabits"}, Data, 1e6 / 8},
+	{[]string{"Gb", "gbit", "gigabit", "gigabits"}, Data, 1e9 / 8},
+
+	// Tiempo (base: segundo).
+	{[]string{"ms", "milisegundo", "milisegundos", "millisecond", "milliseconds"}, Time, 0.001},
+	{[]string{"s", "seg", "sec", "segundo", "segundos", "second", "seconds"}, Time, 1},
+	{[]string{"min", "minuto", "minutos", "minute", "minutes"}, Time, 60},
+	{[]string{"h", "hr", "hora", "horas", "hour", "hours"}, Time, 3600},
+	{[]string{"d", "día", "días", "dia", "dias", "day", "days"}, Time, 86400},
+	{[]string{"sem", "semana", "semanas", "wk", "week", "weeks"}, Time, 604800},
+	{[]string{"mes", "meses", "month", "months"}, Time, 2629746},     // 1/12 de año gregoriano.
+	{[]string{"año", "años", "yr", "year", "years"}, Time, 31556952}, // 365.2425 días.
+}
+
+// units indexa las unidades por alias (sensible a mayúsculas) y lowerUnits por alias en
+// minúsculas, para aceptar "gb" además de "GB" sin confundir "Mb" (megabit) con "MB".
+var units, lowerUnits = buildUnits()
+
+func buildUnits() (map[string]unit, map[string]unit) {
+	exact := make(map[string]unit)
+	lower := make(map[string]unit)
+	add := func(aliases []string, u unit) {
+		for _, alias := range aliases {
+			exact[alias] = u
+			if _, taken := lower[strings.ToLower(alias)]; !taken {
+				lower[strings.ToLower(alias)] = u
+			}
+		}
+	}
+
+	for _, def := range unitList {
+		add(def.aliases, unit{symbol: def.aliases[0], category: def.category, factor: def.factor})
+	}
+
+	// Temperatura (base: Kelvin).
+	add([]string{"°C", "c", "°c", "celsius", "ºc", "ºC"}, unit{symbol: "°C", category: Temperature,
+		toBase:   func(v float64) float64 { return v + 273.15 },
+		fromBase: func(v float64) float64 { return v - 273.15 }})
+	add([]string{"°F", "f", "°f", "fahrenheit", "ºf", "ºF"}, unit{symbol: "°F", category: Temperature,
+		toBase:   func(v float64) float64 { return (v-32)*5/9 + 273.15 },
+		fromBase: func(v float64) float64 { return (v-273.15)*9/5 + 32 }})
+	add([]string{"K", "k", "kelvin"}, unit{symbol: "K", category: Temperature, factor: 1})
+
+	return exact, lower
+}
+
+// lookupUnit busca una unidad por su alias.
+func lookupUnit(name string) (unit, bool) {
+	name = strings.TrimSpace(name)
+	if u, ok := units[name]; ok {
+		return u, true
+	}
+	u, ok := lowerUnits[strings.ToLower(name)]
+	return u, ok
+}
+
+// Convert convierte un valor entre dos unidades de la misma categoría.
+func Convert(value float64, from, to string) (float64, error) {
+	fu, ok := lookupUnit(from)
+	if !ok {
+		return 0, fmt.Errorf("unidad desconocida %q", from)
+	}
+	tu, ok := lookupUnit(to)
+	if !ok {
+		return 0, fmt.Errorf("unidad desconocida %q", to)
+	}
+	if fu.category != tu.category {
+		return 0, fmt.Errorf("no se puede convertir %s (%s) a %s (%s)", fu.symbol, fu.category, tu.symbol, tu.category)
+	}
+	return tu.from(fu.base(value)), nil
+}
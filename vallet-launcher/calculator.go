@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+
+	"vallet-launcher/calc"
+	"vallet-launcher/fuzzy"
+)
+
+// calculatorScore pone el resultado de la calculadora justo por debajo de un alias escrito
+// completo: si la consulta es un cálculo válido, es casi seguro lo que se buscaba, salvo que
+// el usuario haya creado un link con ese alias (ej: "2fa").
+const calculatorScore = fuzzy.ScoreExact + exactAliasBonus - 1
+
+// calculatorProvider evalúa operaciones y conversiones de unidades ("1920*1080",
+// "5 km in mi"). Al elegir el resultado se copia el valor al portapapeles.
+type calculatorProvider struct {
+	app *App
+}
+
+func (p *calculatorProvider) Name() string { return "calculator" }
+
+func (p *calculatorProvider) Search(ctx context.Context, query string) []Result {
+	answer, ok := calc.Calculate(query)
+	if !ok {
+		return nil
+	}
+	return []Result{{
+		Title:    "= " + answer.Text,
+		Subtitle: query + " · Enter para copiar",
+		Type:     ActionCopy,
+		Target:   answer.Copy,
+		Score:    calculatorScore,
+	}}
+}
+
+// Execute copia el valor al portapapeles.
+func (p *calculatorProvider) Execute(result Result) error {
+	return p.app.runAction(newAction(ActionCopy, result.Target), result.Query)
+}
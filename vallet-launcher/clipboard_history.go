@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"vallet-launcher/cliphistory"
+	"vallet-launcher/utils"
+
+	"github.com/atotto/clipboard"
+)
+
+const (
+	// clipboardPollInterval es cada cuánto se revisa el contenido del portapapeles.
+	clipboardPollInterval = time.Second
+	// defaultClipboardHistorySize se usa si clipboard_history_size no es un número válido.
+	defaultClipboardHistorySize = 200
+	// clipboardPrefix activa el modo historial en el launcher ("cb" o "cb texto").
+	clipboardPrefix = "cb"
+	// maxClipboardResults limita cuántos textos del historial se muestran.
+	maxClipboardResults = 50
+)
+
+// clipboardHistorySettings es la configuración vigente del historial del portapapeles.
+type clipboardHistorySettings struct {
+	enabled bool
+	size    int
+	filter  cliphistory.Filter
+}
+
+// loadClipboardHistorySettings lee la configuración del historial del portapapeles.
+func (a *App) loadClipboardHistorySettings() clipboardHistorySettings {
+	enabled, _ := a.db.GetSetting("clipboard_history_enabled")
+	sizeSetting, _ := a.db.GetSetting("clipboard_history_size")
+	skipSensitive, _ := a.db.GetSetting("clipboard_history_skip_sensitive")
+	exclude, _ := a.db.GetSetting("clipboard_history_exclude")
+
+	size, err := strconv.Atoi(strings.TrimSpace(sizeSetting))
+	if err != nil || size <= 0 {
+		size = defaultClipboardHistorySize
+	}
+
+	rules, err := cliphistory.ParseRules(exclude)
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+
+	return clipboardHistorySettings{
+		enabled: enabled != "false",
+		size:    size,
+		filter: cliphistory.Filter{
+			SkipSensitive: skipSensitive != "false",
+			Exclude:       rules,
+		},
+	}
+}
+
+// startClipboardWatcher revisa el portapapeles periódicamente y guarda en el historial
+// cada texto nuevo que pase los filtros, hasta que se cancela el contexto.
+func (a *App) startClipboardWatcher(ctx context.Context) {
+	go func() {
+		settings := a.loadClipboardHistorySettings()
+		ticker := time.NewTicker(clipboardPollInterval)
+		defer ticker.Stop()
+
+		last := ""
+		readFailed := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-a.reloadClipboard:
+				settings = a.loadClipboardHistorySettings()
+				continue
+			case <-ticker.C:
+			}
+			// Durante un pegado el portapapeles tiene el texto dictado o el snippet y luego
+			// el contenido restaurado: nada de eso lo copió el usuario.
+			if !settings.enabled || utils.PasteInProgress() {
+				continue
+			}
+
+			text, err := clipboard.ReadAll()
+			if err != nil {
+				// Se informa una sola vez (ej: falta xclip o wl-clipboard en Linux).
+				if !readFailed {
+					fmt.Printf("⚠️ No se pudo leer el portapapeles: %v\n", err)
+					readFailed = true
+				}
+				continue
+			}
+			readFailed = false
+			if text == last {
+				continue
+			}
+			last = text
+
+			if !settings.filter.Allow(text) {
+				continue
+			}
+			if err := a.db.AddClipboardEntry(text, settings.size); err != nil {
+				log.Printf("Error guardando en el historial del portapapeles: %v", err)
+			}
+		}
+	}()
+}
+
+// reloadClipboardHistorySettings aplica los cambios de configuración del historial.
+func (a *App) reloadClipboardHistorySettings() {
+	select {
+	case a.reloadClipboard <- struct{}{}:
+	default:
+		// Ya hay una recarga pendiente.
+	}
+}
+
+// GetClipboardHistory devuelve el historial del portapapeles filtrado por la consulta.
+func (a *App) GetClipboardHistory(query string) ([]ClipboardEntry, error) {
+	return a.db.GetClipboardHistory(query, maxClipboardResults)
+}
+
+// ToggleClipboardPin fija o libera un texto del historial y devuelve el nuevo estado.
+func (a *App) ToggleClipboardPin(id int) (bool, error) {
+	entry, err := a.db.GetClipboardEntry(id)
+	if err != nil {
+		return false, err
+	}
+	pinned := !entry.Pinned
+	return pinned, a.db.SetClipboardPinned(id, pinned)
+}
+
+// DeleteClipboardEntry elimina un texto del historial.
+func (a *App) DeleteClipboardEntry(id int) error {
+	return a.db.DeleteClipboardEntry(id)
+}
+
+// ClearClipboardHistory borra el historial del portapapeles, salvo los textos fijados.
+func (a *App) ClearClipboardHistory() error {
+	return a.db.ClearClipboardHistory()
+}
+
+// clipboardProvider muestra el historial del portapapeles cuando la consulta empieza
+// por "cb" y pega el texto elegido en la aplicación activa.
+type clipboardProvider struct {
+	app *App
+}
+
+func (p *clipboardProvider) Name() string { return SourceClipboard }
+
+func (p *clipboardProvider) Prefix() string { return clipboardPrefix }
+
+func (p *clipboardProvider) Search(ctx context.Context, query string) []Result {
+	entries, err := p.app.db.GetClipboardHistory(query, maxClipboardResults)
+	if err != nil {
+		log.Println("Error buscando en el historial del portapapeles:", err)
+		return nil
+	}
+
+	// El orden de la base de datos (fijados y luego más recientes) se conserva.
+	results := make([]Result, 0, len(entries))
+	for i, entry := range entries {
+		results = append(results, Result{
+			ID:       entry.ID,
+			Title:    previewLine(entry.Content),
+			Subtitle: clipboardSubtitle(entry),
+			Type:     ActionPaste,
+			Target:   entry.Content,
+			Score:    len(entries) - i,
+		})
+	}
+	return results
+}
+
+// Execute pega el texto en la aplicación activa y lo sube al principio del historial. El
+// watcher no lo registra por sí solo: durante el pegado no lee el portapapeles.
+func (p *clipboardProvider) Execute(result Result) error {
+	entry, err := p.app.db.GetClipboardEntry(result.ID)
+	if err != nil {
+		return err
+	}
+	if err := p.app.runAction(newAction(ActionPaste, entry.Content), result.Query); err != nil {
+		return err
+	}
+	if err := p.app.db.TouchClipboardEntry(entry.ID); err != nil {
+		log.Printf("Error actualizando el historial del portapapeles: %v", err)
+	}
+	return nil
+}
+
+// clipboardSubtitle describe un texto del historial: si está fijado, cuándo se copió y
+// su tamaño.
+func clipboardSubtitle(entry ClipboardEntry) string {
+	var parts []string
+	if entry.Pinned {
+		parts = append(parts, "📌 Fijado")
+	}
+	if copied, ok := parseDBTime(entry.LastCopiedAt); ok {
+		parts = append(parts, formatAgo(time.Since(copied)))
+	}
+	if lines := strings.Count(strings.TrimSpace(entry.Content), "\n") + 1; lines > 1 {
+		parts = append(parts, fmt.Sprintf("%d líneas", lines))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d caracteres", len([]rune(entry.Content))))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// parseDBTime interpreta una fecha UTC guardada por SQLite.
+func parseDBTime(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// formatAgo expresa una duración de forma aproximada ("hace 5 min").
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "hace un momento"
+	case d < time.Hour:
+		return fmt.Sprintf("hace %d min", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("hace %d h", int(d.Hours()))
+	default:
+		return fmt.Sprintf("hace %d días", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,161 @@
+package cliphistory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxEntrySize es el tamaño máximo (en bytes) de un texto que se guarda en el historial.
+// Los textos más grandes suelen ser archivos completos y no merece la pena recordarlos.
+const MaxEntrySize = 64 * 1024
+
+// tokenPatterns reconocen credenciales con un formato conocido.
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`^(ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{30,}$`), // GitHub
+	regexp.MustCompile(`^github_pat_[A-Za-z0-9_]{40,}$`),
+	regexp.MustCompile(`^glpat-[A-Za-z0-9_-]{20,}$`),                                   // GitLab
+	regexp.MustCompile(`^(sk|pk|rk)[-_](live|test|proj|ant)?[-_]?[A-Za-z0-9_-]{20,}$`), // OpenAI, Stripe, Anthropic
+	regexp.MustCompile(`^xox[abprs]-[A-Za-z0-9-]{10,}$`),                               // Slack
+	regexp.MustCompile(`^AKIA[0-9A-Z]{16}$`),                                           // AWS
+	regexp.MustCompile(`^AIza[0-9A-Za-z_-]{35}$`),                                      // Google
+	regexp.MustCompile(`^npm_[A-Za-z0-9]{36}$`),
+	regexp.MustCompile(`^eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*$`), // JWT
+	regexp.MustCompile(`(?i)^(bearer|token|basic)\s+[A-Za-z0-9._~+/=-]{16,}$`),
+	regexp.MustCompile(`(?i)^[a-z][a-z0-9+.-]*://[^/\s:@]+:[^/\s@]+@`), // URL con contraseña
+}
+
+// LooksSensitive indica si el texto parece una contraseña o un token: credenciales con un
+// formato conocido, palabras sueltas que mezclan mayúsculas, minúsculas, dígitos y
+// símbolos, o cadenas largas y aleatorias sin espacios.
+func LooksSensitive(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, pattern := range tokenPatterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+
+	// Las contraseñas y tokens son una sola "palabra".
+	if text == "" || strings.ContainsFunc(text, unicode.IsSpace) || utf8.RuneCountInString(text) > 256 {
+		return false
+	}
+	if looksLikeIdentifier(text) {
+		return false
+	}
+
+	length := utf8.RuneCountInString(text)
+	classes := characterClasses(text)
+	switch {
+	case length >= 8 && length <= 64 && classes >= 4:
+		return true
+	case length >= 20 && classes >= 3 && entropy(text) >= 3.5:
+		return true
+	}
+	return false
+}
+
+// looksLikeIdentifier reconoce textos sin espacios que no son secretos: URLs, rutas,
+// correos, números, hashes hexadecimales y UUIDs.
+func looksLikeIdentifier(text string) bool {
+	if strings.Contains(text, "://") || strings.HasPrefix(text, "www.") {
+		return true
+	}
+	if strings.HasPrefix(text, "/") || strings.HasPrefix(text, "~/") || strings.HasPrefix(text, `\\`) ||
+		(len(text) > 2 && text[1] == ':' && (text[2] == '\\' || text[2] == '/')) {
+		return true
+	}
+	if at := strings.IndexByte(text, '@'); at > 0 && strings.Contains(text[at:], ".") && !strings.ContainsAny(text, ":/") {
+		return true
+	}
+	return strings.Trim(text, "0123456789abcdefABCDEF-.,:") == ""
+}
+
+// characterClasses cuenta cuántas clases de caracteres (minúsculas, mayúsculas, dígitos
+// y símbolos) aparecen en el texto.
+func characterClasses(text string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range text {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	count := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			count++
+		}
+	}
+	return count
+}
+
+// entropy calcula la entropía de Shannon del texto en bits por carácter.
+func entropy(text string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range text {
+		counts[r]++
+		total++
+	}
+	var bits float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// Filter decide qué textos copiados se guardan en el historial.
+type Filter struct {
+	SkipSensitive bool             // Omitir lo que parece una contraseña o un token.
+	Exclude       []*regexp.Regexp // Expresiones definidas por el usuario; si alguna coincide se omite.
+}
+
+// ParseRules compila las reglas de exclusión, una expresión regular por línea. Las líneas
+// vacías y las que empiezan con '#' se ignoran. Las expresiones no distinguen mayúsculas.
+// Las reglas inválidas se omiten y se informan en el error, sin descartar las demás.
+func ParseRules(value string) ([]*regexp.Regexp, error) {
+	var rules []*regexp.Regexp
+	var errs []error
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule, err := regexp.Compile("(?i)" + line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("regla de exclusión inválida %q: %w", line, err))
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, errors.Join(errs...)
+}
+
+// Allow indica si el texto debe guardarse en el historial.
+func (f Filter) Allow(text string) bool {
+	if strings.TrimSpace(text) == "" || len(text) > MaxEntrySize {
+		return false
+	}
+	if f.SkipSensitive && LooksSensitive(text) {
+		return false
+	}
+	for _, rule := range f.Exclude {
+		if rule.MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,11 +1,20 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"vallet-launcher/desktop"
+	"vallet-launcher/filesearch"
+	"vallet-launcher/templates"
 
 	_ "modernc.org/sqlite"
 )
@@ -14,20 +23,81 @@ import (
 type Link struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`        // Alias o nombre del link.
-	URL         string `json:"url"`         // Dirección web o comando.
+	URL         string `json:"url"`         // Dirección web, ruta, comando o texto según el tipo.
+	Type        string `json:"type"`        // Tipo de acción: url, file, folder, application, command o snippet.
 	Description string `json:"description"` // Descripción opcional.
-	Category    string `json:"category"`    // Categoría para organizar links (ahora se refiere al nombre o ID de la carpeta).
+	FolderID    int    `json:"folder_id"`   // ID de la carpeta a la que pertenece el link.
+	Category    string `json:"category"`    // Ruta de la carpeta (resuelta a partir de folder_id).
 	CreatedAt   string `json:"created_at"`  // Fecha de creación.
+
+	Source   string   `json:"source"`             // Origen del resultado: link guardado o aplicación instalada.
+	Keywords []string `json:"keywords,omitempty"` // Palabras clave adicionales (solo aplicaciones).
+	Icon     string   `json:"icon,omitempty"`     // Nombre o ruta del icono (solo aplicaciones).
+}
+
+const (
+	// Orígenes de los resultados del launcher.
+	SourceLink        = "link"        // Link creado por el usuario (tabla links).
+	SourceApplication = "application" // Aplicación instalada (tabla applications).
+	SourceFile        = "file"        // Archivo o carpeta indexado (tabla indexed_files).
+	SourceClipboard   = "clipboard"   // Texto del historial del portapapeles (tabla clipboard_history).
+)
+
+// isSaved indica si el resultado es un link guardado (y no una aplicación o un archivo).
+func (l Link) isSaved() bool {
+	return l.Source == "" || l.Source == SourceLink
+}
+
+// Application es una aplicación instalada, leída de un archivo .desktop.
+type Application struct {
+	ID          int      `json:"id"`
+	DesktopID   string   `json:"desktop_id"`   // Identificador de escritorio (ej: "firefox.desktop").
+	Path        string   `json:"path"`         // Ruta del archivo .desktop.
+	Name        string   `json:"name"`         // Nombre localizado.
+	GenericName string   `json:"generic_name"` // Nombre genérico localizado (ej: "Navegador web").
+	Comment     string   `json:"comment"`      // Descripción localizada.
+	Keywords    []string `json:"keywords"`     // Palabras clave localizadas.
+	Exec        string   `json:"exec"`         // Línea de comandos (clave Exec).
+	Icon        string   `json:"icon"`         // Nombre o ruta del icono.
+	NoDisplay   bool     `json:"no_display"`   // La aplicación no debe mostrarse en menús.
 }
 
-// Folder representa una agrupación de links.
+// ClipboardEntry es un texto copiado guardado en el historial del portapapeles.
+type ClipboardEntry struct {
+	ID           int    `json:"id"`
+	Content      string `json:"content"`        // Texto copiado.
+	Pinned       bool   `json:"pinned"`         // Los textos fijados no se eliminan al recortar el historial.
+	CopyCount    int    `json:"copy_count"`     // Veces que se copió el mismo texto.
+	CreatedAt    string `json:"created_at"`     // Primera vez que se copió.
+	LastCopiedAt string `json:"last_copied_at"` // Última vez que se copió.
+}
+
+// Folder representa una agrupación de links. Las carpetas pueden anidarse.
 type Folder struct {
 	ID          int    `json:"id"`
+	ParentID    int    `json:"parent_id"`   // ID de la carpeta padre (0 si es de primer nivel).
 	Name        string `json:"name"`        // Nombre de la carpeta.
+	Path        string `json:"path"`        // Ruta completa (ej: "Trabajo/Clientes/Acme").
 	Description string `json:"description"` // Descripción opcional.
 	CreatedAt   string `json:"created_at"`  // Fecha de creación.
 }
 
+// FolderNode es un nodo del árbol de carpetas que se muestra en el panel de administración.
+type FolderNode struct {
+	Folder   Folder       `json:"folder"`
+	Children []FolderNode `json:"children"`
+}
+
+// SearchEngine representa un motor de búsqueda invocable con una palabra clave
+// desde el launcher (ej: "g texto" o "wiki texto").
+type SearchEngine struct {
+	ID        int    `json:"id"`
+	Keyword   string `json:"keyword"`    // Palabra clave (ej: "g", "ddg").
+	Name      string `json:"name"`       // Nombre visible del motor.
+	URL       string `json:"url"`        // Plantilla de búsqueda con el marcador {query}.
+	CreatedAt string `json:"created_at"` // Fecha de creación.
+}
+
 // UsageLog representa un registro de uso de una herramienta.
 type UsageLog struct {
 	Date      string `json:"date"`
@@ -38,10 +108,11 @@ type UsageLog struct {
 
 // Database encapsula la conexión a la base de datos SQLite.
 type Database struct {
-	db *sql.DB
+	db  *sql.DB
+	fts bool // Indica si existe el índice FTS5 links_fts.
 }
 
-// NewDatabase inicializa la conexión con SQLite, creando el archivo y las tablas si no existen.
+// NewDatabase inicializa la conexión con SQLite, creando el archivo si no existe y aplicando las migraciones pendientes.
 func NewDatabase() (*Database, error) {
 	// Obtener el directorio de datos del usuario (Home).
 	homeDir, err := os.UserHomeDir()
@@ -68,66 +139,60 @@ func NewDatabase() (*Database, error) {
 		}
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	return openDatabase(dbPath)
+}
+
+// openDatabase abre (o crea) la base de datos en dbPath y aplica las migraciones pendientes.
+func openDatabase(dbPath string) (*Database, error) {
+	// Las claves foráneas se activan por conexión, por eso se indican en el DSN. WAL deja
+	// leer mientras se escribe (ej: buscar durante la indexación de archivos) y busy_timeout
+	// espera a que termine otra escritura en vez de fallar con SQLITE_BUSY.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
 	if err != nil {
 		return nil, err
 	}
 
 	database := &Database{db: db}
-	if err := database.createTables(); err != nil {
+	if err := database.migrate(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
+	fts, err := database.ensureLinksFTS()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	database.fts = fts
+
 	return database, nil
 }
 
-// createTables crea las tablas necesarias ('links', 'settings', 'folders') si no existen.
-func (d *Database) createTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS links (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL,
-			url TEXT NOT NULL,
-			description TEXT,
-			category TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);`,
-		`CREATE TABLE IF NOT EXISTS settings (
-			key TEXT PRIMARY KEY,
-			value TEXT
-		);`,
-		`CREATE TABLE IF NOT EXISTS folders (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE,
-			description TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);`,
-		`CREATE TABLE IF NOT EXISTS usage_stats (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			tool_type TEXT NOT NULL,
-			day_of_week TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		);`,
-	}
-
-	for _, query := range queries {
-		if _, err := d.db.Exec(query); err != nil {
-			return err
-		}
+// ensureLinksFTS crea el índice FTS5 links_fts si falta y devuelve si está disponible. La
+// migración 5 no lo crea con un SQLite sin FTS5; comprobarlo en cada arranque hace que el
+// índice aparezca en cuanto se usa una compilación que sí lo incluye.
+func (d *Database) ensureLinksFTS() (bool, error) {
+	var ftsTables int
+	err := d.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'links_fts'").Scan(&ftsTables)
+	if err != nil || ftsTables > 0 {
+		return ftsTables > 0, err
 	}
 
-	// Insertar configuraciones por defecto si es la primera vez que se ejecuta.
-	d.db.Exec("INSERT OR IGNORE INTO settings (key, value) VALUES ('run_in_background', 'false')")
-	d.db.Exec("INSERT OR IGNORE INTO settings (key, value) VALUES ('default_browser', 'system')")
-	d.db.Exec("INSERT OR IGNORE INTO settings (key, value) VALUES ('play_audio_transcription', 'true')")
-
-	// Insertar carpeta 'General' por defecto.
-	d.db.Exec("INSERT OR IGNORE INTO folders (name, description) VALUES ('General', 'Carpeta predeterminada para todos los links')")
-
-	// Migrar links de la categoría 'general' (o similar) a 'General' para consistencia con la carpeta.
-	d.db.Exec("UPDATE links SET category = 'General' WHERE LOWER(category) = 'general'")
+	tx, err := d.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
 
-	return nil
+	created, err := createLinksFTS(tx)
+	if err != nil || !created {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	log.Println("✅ Índice FTS5 de links creado")
+	return true, nil
 }
 
 // GetSetting recupera un valor de la tabla settings.
@@ -148,8 +213,25 @@ func (d *Database) UpdateSetting(key, value string) error {
 
 // ============ Métodos para Links ============
 
+// linkSelect es la consulta base para leer links junto con la ruta de su carpeta.
+const linkSelect = `SELECT l.id, l.name, l.url, l.action_type, COALESCE(l.description, ''), l.folder_id, fp.path, l.created_at
+	FROM links l JOIN folder_paths fp ON fp.id = l.folder_id`
+
+// scanLink lee una fila producida por linkSelect.
+func scanLink(row interface{ Scan(...any) error }) (Link, error) {
+	var link Link
+	err := row.Scan(&link.ID, &link.Name, &link.URL, &link.Type, &link.Description, &link.FolderID, &link.Category, &link.CreatedAt)
+	link.Source = SourceLink
+	return link, err
+}
+
 func (d *Database) GetAllLinks() ([]Link, error) {
-	rows, err := d.db.Query("SELECT id, name, url, description, category, created_at FROM links ORDER BY created_at DESC")
+	return d.queryLinks(context.Background(), linkSelect+" ORDER BY l.created_at DESC")
+}
+
+// queryLinks ejecuta una consulta basada en linkSelect y devuelve los links que encuentra.
+func (d *Database) queryLinks(ctx context.Context, query string, args ...any) ([]Link, error) {
+	rows, err := d.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -157,8 +239,7 @@ func (d *Database) GetAllLinks() ([]Link, error) {
 
 	var links []Link
 	for rows.Next() {
-		var link Link
-		err := rows.Scan(&link.ID, &link.Name, &link.URL, &link.Description, &link.Category, &link.CreatedAt)
+		link, err := scanLink(rows)
 		if err != nil {
 			log.Println("Error scanning link:", err)
 			continue
@@ -166,51 +247,139 @@ func (d *Database) GetAllLinks() ([]Link, error) {
 		links = append(links, link)
 	}
 
-	return links, nil
+	return links, rows.Err()
 }
 
 func (d *Database) GetLinkByID(id int) (*Link, error) {
-	var link Link
-	err := d.db.QueryRow("SELECT id, name, url, description, category, created_at FROM links WHERE id = ?", id).
-		Scan(&link.ID, &link.Name, &link.URL, &link.Description, &link.Category, &link.CreatedAt)
+	link, err := scanLink(d.db.QueryRow(linkSelect+" WHERE l.id = ?", id))
 	if err != nil {
 		return nil, err
 	}
 	return &link, nil
 }
 
+// HasLinkAlias indica si algún link guardado se llama exactamente name (sin distinguir
+// mayúsculas).
+func (d *Database) HasLinkAlias(name string) (bool, error) {
+	var exists bool
+	err := d.db.QueryRow("SELECT EXISTS(SELECT 1 FROM links WHERE name = ? COLLATE NOCASE)", name).Scan(&exists)
+	return exists, err
+}
+
+// SearchLinks devuelve los links que coinciden con la consulta ordenados por relevancia
+// (ver rankLinks). La búsqueda de texto aporta los resultados del índice FTS5 (o LIKE),
+// la frecencia favorece los links que más se abren y query_picks los que ya se eligieron
+// para esta misma consulta.
 func (d *Database) SearchLinks(query string) ([]Link, error) {
-	searchQuery := "%" + query + "%"
-	rows, err := d.db.Query(
-		"SELECT id, name, url, description, category, created_at FROM links WHERE name LIKE ? OR url LIKE ? OR description LIKE ? ORDER BY created_at DESC",
-		searchQuery, searchQuery, searchQuery,
-	)
+	if strings.TrimSpace(query) == "" {
+		return d.GetAllLinks()
+	}
+
+	scored, err := d.searchLinksScored(context.Background(), query)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	links := make([]Link, len(scored))
+	for i, s := range scored {
+		links[i] = s.link
+	}
+	return links, nil
+}
 
-	var links []Link
-	for rows.Next() {
-		var link Link
-		err := rows.Scan(&link.ID, &link.Name, &link.URL, &link.Description, &link.Category, &link.CreatedAt)
-		if err != nil {
-			continue
+// searchLinksScored busca y puntúa los links para una consulta no vacía. Si se cancela el
+// contexto (ej: el usuario siguió escribiendo) se abandonan las consultas pendientes.
+func (d *Database) searchLinksScored(ctx context.Context, query string) ([]scoredLink, error) {
+	links, err := d.queryLinks(ctx, linkSelect+" ORDER BY l.created_at DESC")
+	if err != nil {
+		return nil, err
+	}
+
+	textHits, err := d.searchLinksText(ctx, query)
+	if err != nil {
+		log.Println("Error en búsqueda de texto:", err)
+	}
+
+	frecencies, err := d.GetLinkFrecencies(ctx)
+	if err != nil {
+		log.Println("Error leyendo frecencia de links:", err)
+	}
+
+	picks, err := d.GetQueryPicks(ctx, query)
+	if err != nil {
+		log.Println("Error leyendo elecciones aprendidas:", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return scoreLinks(query, links, rankSignals{
+		textHits:   textHits,
+		frecencies: frecencies,
+		picks:      picks,
+	}), nil
+}
+
+// searchLinksText busca links usando el índice FTS5 (con prefijos y ranking BM25) y, si no
+// está disponible o la consulta no se puede expresar en FTS, con LIKE.
+func (d *Database) searchLinksText(ctx context.Context, query string) ([]Link, error) {
+	if d.fts {
+		if match := ftsMatchQuery(query); match != "" {
+			links, err := d.searchLinksFTS(ctx, match)
+			if err == nil || ctx.Err() != nil {
+				return links, err
+			}
+			log.Println("Error en búsqueda FTS, usando LIKE:", err)
 		}
-		links = append(links, link)
 	}
+	return d.searchLinksLike(ctx, query)
+}
 
-	return links, nil
+// searchLinksFTS ordena por BM25 ponderando las coincidencias en el nombre por encima
+// de las de la URL y la descripción.
+func (d *Database) searchLinksFTS(ctx context.Context, match string) ([]Link, error) {
+	return d.queryLinks(ctx,
+		linkSelect+` JOIN links_fts ON links_fts.rowid = l.id
+		WHERE links_fts MATCH ?
+		ORDER BY bm25(links_fts, 10.0, 2.0, 1.0), l.created_at DESC`,
+		match,
+	)
+}
+
+func (d *Database) searchLinksLike(ctx context.Context, query string) ([]Link, error) {
+	searchQuery := "%" + query + "%"
+	return d.queryLinks(ctx,
+		linkSelect+" WHERE l.name LIKE ? OR l.url LIKE ? OR l.description LIKE ? ORDER BY l.created_at DESC",
+		searchQuery, searchQuery, searchQuery,
+	)
+}
+
+// ftsMatchQuery convierte el texto del usuario en una expresión MATCH de FTS5: cada
+// palabra se busca como prefijo ("git hub" -> "git"* "hub"*). Las comillas evitan que
+// caracteres como '-' o ':' se interpreten como operadores.
+func ftsMatchQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, `"`+w+`"*`)
+	}
+	return strings.Join(terms, " ")
 }
 
 func (d *Database) CreateLink(link Link) (int64, error) {
-	// Si no tiene categoría, asignar 'General' por defecto.
-	if link.Category == "" {
-		link.Category = "General"
+	if err := validateLink(&link); err != nil {
+		return 0, err
+	}
+	folderID, err := d.resolveFolderID(link)
+	if err != nil {
+		return 0, err
 	}
 	result, err := d.db.Exec(
-		"INSERT INTO links (name, url, description, category) VALUES (?, ?, ?, ?)",
-		link.Name, link.URL, link.Description, link.Category,
+		"INSERT INTO links (name, url, action_type, description, folder_id) VALUES (?, ?, ?, ?, ?)",
+		link.Name, link.URL, link.Type, link.Description, folderID,
 	)
 	if err != nil {
 		return 0, err
@@ -219,9 +388,16 @@ func (d *Database) CreateLink(link Link) (int64, error) {
 }
 
 func (d *Database) UpdateLink(link Link) error {
-	_, err := d.db.Exec(
-		"UPDATE links SET name = ?, url = ?, description = ?, category = ? WHERE id = ?",
-		link.Name, link.URL, link.Description, link.Category, link.ID,
+	if err := validateLink(&link); err != nil {
+		return err
+	}
+	folderID, err := d.resolveFolderID(link)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(
+		"UPDATE links SET name = ?, url = ?, action_type = ?, description = ?, folder_id = ? WHERE id = ?",
+		link.Name, link.URL, link.Type, link.Description, folderID, link.ID,
 	)
 	return err
 }
@@ -231,10 +407,67 @@ func (d *Database) DeleteLink(id int) error {
 	return err
 }
 
+// validateLink rechaza links con tipo desconocido o plantillas mal formadas (ej: "{1" o
+// "{foo}") para que el error aparezca al guardarlos y no al abrirlos desde el launcher.
+// Si no se indica el tipo, se deduce de la URL.
+func validateLink(link *Link) error {
+	if link.Type == "" {
+		link.Type = inferActionType(link.URL)
+	}
+	if !linkActionTypes[link.Type] {
+		return fmt.Errorf("tipo de link desconocido: %s", link.Type)
+	}
+	if link.Type != ActionSnippet && templates.HasPlaceholders(link.URL) {
+		if _, err := templates.Parse(link.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveFolderID determina la carpeta de un link. Se prioriza folder_id; si no viene,
+// se busca la carpeta por su ruta (category) para mantener compatibilidad con el frontend.
+// Si no hay coincidencia, el link va a la carpeta predeterminada.
+func (d *Database) resolveFolderID(link Link) (int, error) {
+	if link.FolderID > 0 {
+		return link.FolderID, nil
+	}
+
+	if name := strings.TrimSpace(link.Category); name != "" {
+		var id int
+		err := d.db.QueryRow("SELECT id FROM folder_paths WHERE path = ?", name).Scan(&id)
+		if err == nil {
+			return id, nil
+		}
+		if err != sql.ErrNoRows {
+			return 0, err
+		}
+	}
+
+	return d.defaultFolderID()
+}
+
+// defaultFolderID devuelve el ID de la carpeta 'General', que no se puede eliminar y
+// recibe los links de las carpetas borradas.
+func (d *Database) defaultFolderID() (int, error) {
+	value, err := d.GetSetting("default_folder_id")
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("configuración default_folder_id inválida: %q", value)
+	}
+	return id, nil
+}
+
 // ============ Métodos para Carpetas (Folders) ============
 
+// GetAllFolders devuelve todas las carpetas ordenadas por su ruta completa.
 func (d *Database) GetAllFolders() ([]Folder, error) {
-	rows, err := d.db.Query("SELECT id, name, description, created_at FROM folders ORDER BY name ASC")
+	rows, err := d.db.Query(`SELECT f.id, COALESCE(f.parent_id, 0), f.name, fp.path, COALESCE(f.description, ''), f.created_at
+		FROM folders f JOIN folder_paths fp ON fp.id = f.id
+		ORDER BY fp.path ASC`)
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +476,7 @@ func (d *Database) GetAllFolders() ([]Folder, error) {
 	var folders []Folder
 	for rows.Next() {
 		var f Folder
-		err := rows.Scan(&f.ID, &f.Name, &f.Description, &f.CreatedAt)
+		err := rows.Scan(&f.ID, &f.ParentID, &f.Name, &f.Path, &f.Description, &f.CreatedAt)
 		if err != nil {
 			log.Println("Error scanning folder:", err)
 			continue
@@ -254,10 +487,38 @@ func (d *Database) GetAllFolders() ([]Folder, error) {
 	return folders, nil
 }
 
+// GetFolderTree devuelve las carpetas organizadas como árbol a partir de las de primer nivel.
+func (d *Database) GetFolderTree() ([]FolderNode, error) {
+	folders, err := d.GetAllFolders()
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]Folder)
+	for _, f := range folders {
+		children[f.ParentID] = append(children[f.ParentID], f)
+	}
+
+	var build func(parentID int) []FolderNode
+	build = func(parentID int) []FolderNode {
+		nodes := []FolderNode{}
+		for _, f := range children[parentID] {
+			nodes = append(nodes, FolderNode{Folder: f, Children: build(f.ID)})
+		}
+		return nodes
+	}
+
+	return build(0), nil
+}
+
 func (d *Database) CreateFolder(folder Folder) (int64, error) {
+	name, err := validateFolderName(folder.Name)
+	if err != nil {
+		return 0, err
+	}
 	result, err := d.db.Exec(
-		"INSERT INTO folders (name, description) VALUES (?, ?)",
-		folder.Name, folder.Description,
+		"INSERT INTO folders (parent_id, name, description) VALUES (?, ?, ?)",
+		nullableID(folder.ParentID), name, folder.Description,
 	)
 	if err != nil {
 		return 0, err
@@ -265,49 +526,228 @@ func (d *Database) CreateFolder(folder Folder) (int64, error) {
 	return result.LastInsertId()
 }
 
+// UpdateFolder actualiza nombre y descripción. Los links referencian la carpeta por ID,
+// así que un cambio de nombre no requiere tocarlos. Para cambiar el padre se usa MoveFolder.
 func (d *Database) UpdateFolder(folder Folder) error {
-	// Al actualizar el nombre de una carpeta, también deberíamos actualizar los links asociados
-	// si usamos el nombre como identificador de categoría.
-	// Primero obtenemos el nombre antiguo si es necesario, pero aquí asumimos que el ID es estable.
+	name, err := validateFolderName(folder.Name)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec("UPDATE folders SET name = ?, description = ? WHERE id = ?", name, folder.Description, folder.ID)
+	return err
+}
+
+// MoveFolder cambia la carpeta padre (0 para moverla al primer nivel). Se rechaza mover
+// una carpeta dentro de sí misma o de uno de sus descendientes. La comprobación y el cambio
+// van en la misma transacción para que dos movimientos simultáneos no formen un ciclo.
+func (d *Database) MoveFolder(id, parentID int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if parentID != 0 {
+		var exists bool
+		if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM folders WHERE id = ?)", parentID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return folderNotFound(parentID)
+		}
+
+		descendants, err := folderSubtree(tx, id)
+		if err != nil {
+			return err
+		}
+		for _, descendant := range descendants {
+			if descendant == parentID {
+				return fmt.Errorf("no se puede mover una carpeta dentro de sí misma o de una subcarpeta")
+			}
+		}
+	}
+
+	result, err := tx.Exec("UPDATE folders SET parent_id = ? WHERE id = ?", nullableID(parentID), id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return folderNotFound(id)
+	}
+	return tx.Commit()
+}
 
-	var oldName string
-	err := d.db.QueryRow("SELECT name FROM folders WHERE id = ?", folder.ID).Scan(&oldName)
+// DeleteFolder elimina una carpeta junto con todas sus subcarpetas. Los links que
+// contenían pasan a la carpeta padre o, si era de primer nivel, a la carpeta predeterminada.
+func (d *Database) DeleteFolder(id int) error {
+	defaultID, err := d.defaultFolderID()
 	if err != nil {
 		return err
 	}
 
+	// Las lecturas van dentro de la transacción para que un MoveFolder simultáneo no cambie
+	// el árbol entre la comprobación y el borrado.
 	tx, err := d.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	_, err = tx.Exec("UPDATE folders SET name = ?, description = ? WHERE id = ?", folder.Name, folder.Description, folder.ID)
+	var parentID int
+	err = tx.QueryRow("SELECT COALESCE(parent_id, 0) FROM folders WHERE id = ?", id).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return folderNotFound(id)
+	}
 	if err != nil {
-		tx.Rollback()
 		return err
 	}
+	if parentID == 0 {
+		parentID = defaultID
+	}
 
-	if oldName != folder.Name {
-		_, err = tx.Exec("UPDATE links SET category = ? WHERE category = ?", folder.Name, oldName)
-		if err != nil {
-			tx.Rollback()
-			return err
+	subtree, err := folderSubtree(tx, id)
+	if err != nil {
+		return err
+	}
+
+	// No permitir borrar la carpeta 'General' (ni directamente ni como subcarpeta).
+	for _, folderID := range subtree {
+		if folderID == defaultID {
+			return fmt.Errorf("no se puede eliminar la carpeta predeterminada 'General'")
 		}
 	}
 
+	const subtreeQuery = `WITH RECURSIVE subtree(id) AS (
+		SELECT ? UNION ALL SELECT f.id FROM folders f JOIN subtree ON f.parent_id = subtree.id
+	)`
+
+	// Reasignar los links de toda la rama a la carpeta padre.
+	_, err = tx.Exec(subtreeQuery+" UPDATE links SET folder_id = ? WHERE folder_id IN (SELECT id FROM subtree)", id, parentID)
+	if err != nil {
+		return err
+	}
+
+	// Un único DELETE para que la restricción parent_id se valide con toda la rama borrada.
+	_, err = tx.Exec(subtreeQuery+" DELETE FROM folders WHERE id IN (SELECT id FROM subtree)", id)
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
-func (d *Database) DeleteFolder(id int) error {
-	var name string
-	err := d.db.QueryRow("SELECT name FROM folders WHERE id = ?", id).Scan(&name)
+// folderNotFound es el error de las operaciones sobre una carpeta que no existe.
+func folderNotFound(id int) error {
+	return fmt.Errorf("carpeta no encontrada (id %d)", id)
+}
+
+// folderSubtree devuelve el ID de la carpeta indicada y los de todos sus descendientes.
+// q es la base de datos o la transacción en curso.
+func folderSubtree(q interface {
+	Query(string, ...any) (*sql.Rows, error)
+}, id int) ([]int, error) {
+	rows, err := q.Query(`WITH RECURSIVE subtree(id) AS (
+		SELECT ? UNION ALL SELECT f.id FROM folders f JOIN subtree ON f.parent_id = subtree.id
+	) SELECT id FROM subtree`, id)
 	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var folderID int
+		if err := rows.Scan(&folderID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, folderID)
+	}
+	return ids, rows.Err()
+}
+
+// validateFolderName limpia el nombre y rechaza los que romperían la ruta de la carpeta.
+func validateFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("el nombre de la carpeta no puede estar vacío")
+	}
+	if strings.Contains(name, "/") {
+		return "", fmt.Errorf("el nombre de la carpeta no puede contener '/'")
+	}
+	return name, nil
+}
+
+// nullableID convierte un ID opcional (0 = sin valor) en NULL para SQLite.
+func nullableID(id int) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// ============ Métodos para Motores de Búsqueda ============
+
+func (d *Database) GetAllSearchEngines() ([]SearchEngine, error) {
+	rows, err := d.db.Query("SELECT id, keyword, name, url, created_at FROM search_engines ORDER BY keyword ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var engines []SearchEngine
+	for rows.Next() {
+		var e SearchEngine
+		if err := rows.Scan(&e.ID, &e.Keyword, &e.Name, &e.URL, &e.CreatedAt); err != nil {
+			log.Println("Error scanning search engine:", err)
+			continue
+		}
+		engines = append(engines, e)
+	}
+
+	return engines, nil
+}
+
+// GetSearchEngineByKeyword busca un motor por su palabra clave (sin distinguir mayúsculas).
+// Devuelve nil si no existe.
+func (d *Database) GetSearchEngineByKeyword(keyword string) (*SearchEngine, error) {
+	var e SearchEngine
+	err := d.db.QueryRow("SELECT id, keyword, name, url, created_at FROM search_engines WHERE keyword = ?", keyword).
+		Scan(&e.ID, &e.Keyword, &e.Name, &e.URL, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (d *Database) CreateSearchEngine(engine SearchEngine) (int64, error) {
+	if err := validateSearchEngine(&engine); err != nil {
+		return 0, err
+	}
+	result, err := d.db.Exec(
+		"INSERT INTO search_engines (keyword, name, url) VALUES (?, ?, ?)",
+		engine.Keyword, engine.Name, engine.URL,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateSearchEngine actualiza un motor. Si cambia la palabra clave del motor por defecto,
+// también se actualiza la configuración default_search_engine.
+func (d *Database) UpdateSearchEngine(engine SearchEngine) error {
+	if err := validateSearchEngine(&engine); err != nil {
 		return err
 	}
 
-	// No permitir borrar la carpeta 'General'
-	if name == "General" {
-		return fmt.Errorf("no se puede eliminar la carpeta predeterminada 'General'")
+	var oldKeyword string
+	if err := d.db.QueryRow("SELECT keyword FROM search_engines WHERE id = ?", engine.ID).Scan(&oldKeyword); err != nil {
+		return err
 	}
 
 	tx, err := d.db.Begin()
@@ -315,14 +755,13 @@ func (d *Database) DeleteFolder(id int) error {
 		return err
 	}
 
-	// Mover links de esta carpeta a 'General' antes de borrarla
-	_, err = tx.Exec("UPDATE links SET category = 'General' WHERE category = ?", name)
+	_, err = tx.Exec("UPDATE search_engines SET keyword = ?, name = ?, url = ? WHERE id = ?", engine.Keyword, engine.Name, engine.URL, engine.ID)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	_, err = tx.Exec("DELETE FROM folders WHERE id = ?", id)
+	_, err = tx.Exec("UPDATE settings SET value = ? WHERE key = 'default_search_engine' AND value = ? COLLATE NOCASE", engine.Keyword, oldKeyword)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -331,6 +770,474 @@ func (d *Database) DeleteFolder(id int) error {
 	return tx.Commit()
 }
 
+func (d *Database) DeleteSearchEngine(id int) error {
+	_, err := d.db.Exec("DELETE FROM search_engines WHERE id = ?", id)
+	return err
+}
+
+// validateSearchEngine limpia los campos del motor y exige una plantilla con {query}.
+func validateSearchEngine(engine *SearchEngine) error {
+	engine.Keyword = strings.TrimSpace(engine.Keyword)
+	engine.Name = strings.TrimSpace(engine.Name)
+	engine.URL = strings.TrimSpace(engine.URL)
+
+	if engine.Keyword == "" || strings.ContainsAny(engine.Keyword, " \t") {
+		return fmt.Errorf("la palabra clave del motor no puede estar vacía ni contener espacios")
+	}
+	if engine.Name == "" {
+		engine.Name = engine.Keyword
+	}
+	tmpl, err := templates.Parse(engine.URL)
+	if err != nil {
+		return err
+	}
+	if !tmpl.HasQuery() {
+		return fmt.Errorf("la URL del motor debe incluir el marcador {query}")
+	}
+	return nil
+}
+
+// ============ Métodos para Aplicaciones ============
+
+const applicationSelect = `SELECT id, desktop_id, path, name, generic_name, comment, keywords, exec, icon, no_display FROM applications`
+
+// scanApplication lee una fila producida por applicationSelect.
+func scanApplication(row interface{ Scan(...any) error }) (Application, error) {
+	var app Application
+	var keywords string
+	err := row.Scan(&app.ID, &app.DesktopID, &app.Path, &app.Name, &app.GenericName, &app.Comment, &keywords, &app.Exec, &app.Icon, &app.NoDisplay)
+	if keywords != "" {
+		app.Keywords = strings.Split(keywords, ";")
+	}
+	return app, err
+}
+
+// GetApplications devuelve las aplicaciones indexadas visibles (sin NoDisplay) cuyo
+// programa está instalado.
+func (d *Database) GetApplications() ([]Application, error) {
+	rows, err := d.db.Query(applicationSelect + " WHERE no_display = 0 AND available = 1 ORDER BY name COLLATE NOCASE")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var apps []Application
+	for rows.Next() {
+		app, err := scanApplication(rows)
+		if err != nil {
+			log.Println("Error scanning application:", err)
+			continue
+		}
+		apps = append(apps, app)
+	}
+	return apps, nil
+}
+
+func (d *Database) GetApplicationByID(id int) (*Application, error) {
+	app, err := scanApplication(d.db.QueryRow(applicationSelect+" WHERE id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// SyncApplications actualiza el índice de aplicaciones con los archivos .desktop
+// encontrados. Solo se vuelven a leer los archivos nuevos o cuya fecha, tamaño o idioma
+// cambió, y se eliminan los que ya no existen. De los demás solo se vuelve a comprobar si
+// su programa (TryExec) sigue instalado. Devuelve cuántas filas cambiaron.
+func (d *Database) SyncApplications(files []desktop.File) (int, error) {
+	type indexed struct {
+		path      string
+		modTime   int64
+		size      int64
+		locale    string
+		tryExec   string
+		available bool
+	}
+
+	rows, err := d.db.Query("SELECT desktop_id, path, mod_time, size, locale, try_exec, available FROM applications")
+	if err != nil {
+		return 0, err
+	}
+	current := make(map[string]indexed)
+	for rows.Next() {
+		var id string
+		var row indexed
+		if err := rows.Scan(&id, &row.path, &row.modTime, &row.size, &row.locale, &row.tryExec, &row.available); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		current[id] = row
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	locale := desktop.Locale()
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	changes := 0
+	for _, file := range files {
+		old, exists := current[file.ID]
+		delete(current, file.ID)
+		if exists && old.path == file.Path && old.modTime == file.ModTime.Unix() && old.size == file.Size && old.locale == locale {
+			// El .desktop no cambió, pero su programa pudo instalarse o desinstalarse.
+			if available := desktop.TryExecAvailable(old.tryExec); available != old.available {
+				if _, err := tx.Exec("UPDATE applications SET available = ? WHERE desktop_id = ?", available, file.ID); err != nil {
+					return 0, err
+				}
+				changes++
+			}
+			continue
+		}
+
+		entry, err := desktop.Load(file.Path)
+		if err != nil || !entry.IsApplication() {
+			// Los archivos inválidos u ocultos no se indexan.
+			if exists {
+				if _, err := tx.Exec("DELETE FROM applications WHERE desktop_id = ?", file.ID); err != nil {
+					return 0, err
+				}
+				changes++
+			}
+			continue
+		}
+
+		// Las entradas de programas no instalados se guardan como no disponibles, así no se
+		// vuelven a leer en cada reindexado.
+		_, err = tx.Exec(`INSERT INTO applications (desktop_id, path, name, generic_name, comment, keywords, exec, icon, no_display, mod_time, size, locale, try_exec, available)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(desktop_id) DO UPDATE SET path = excluded.path, name = excluded.name,
+				generic_name = excluded.generic_name, comment = excluded.comment, keywords = excluded.keywords,
+				exec = excluded.exec, icon = excluded.icon, no_display = excluded.no_display,
+				mod_time = excluded.mod_time, size = excluded.size, locale = excluded.locale,
+				try_exec = excluded.try_exec, available = excluded.available,
+				indexed_at = CURRENT_TIMESTAMP`,
+			file.ID, file.Path, entry.Name(), entry.LocaleString("GenericName"), entry.LocaleString("Comment"),
+			strings.Join(entry.LocaleStrings("Keywords"), ";"), entry.String("Exec"), entry.String("Icon"),
+			entry.Bool("NoDisplay"), file.ModTime.Unix(), file.Size, locale,
+			entry.String("TryExec"), entry.Available(),
+		)
+		if err != nil {
+			return 0, err
+		}
+		changes++
+	}
+
+	// Los que quedan en current ya no existen en disco.
+	for id := range current {
+		if _, err := tx.Exec("DELETE FROM applications WHERE desktop_id = ?", id); err != nil {
+			return 0, err
+		}
+		changes++
+	}
+
+	return changes, tx.Commit()
+}
+
+// applicationLink presenta una aplicación como resultado del launcher. La URL es la ruta
+// del .desktop, de modo que al abrirla se lanza con launchDesktopEntry.
+func applicationLink(app Application) Link {
+	description := app.GenericName
+	if description == "" {
+		description = app.Comment
+	}
+	return Link{
+		ID:          app.ID,
+		Name:        app.Name,
+		URL:         app.Path,
+		Type:        ActionApplication,
+		Description: description,
+		Category:    "Aplicaciones",
+		Source:      SourceApplication,
+		Keywords:    app.Keywords,
+		Icon:        app.Icon,
+	}
+}
+
+// ============ Métodos para Archivos Indexados ============
+
+// fileCandidates limita cuántos archivos devuelve SQLite antes de puntuarlos.
+const fileCandidates = 200
+
+// fileSyncBatch es cuántos cambios se guardan por transacción al sincronizar archivos, para
+// no bloquear durante mucho tiempo las demás escrituras (uso, portapapeles).
+const fileSyncBatch = 500
+
+// SyncFiles recorre las raíces configuradas y actualiza indexed_files: agrega o actualiza
+// los archivos nuevos o modificados y elimina los que ya no existen. Primero se recorre el
+// disco sin tocar la base de datos (si el contexto se cancela a mitad del recorrido no se
+// guarda nada) y después se guardan los cambios en lotes de fileSyncBatch.
+func (d *Database) SyncFiles(ctx context.Context, opts filesearch.Options) (int, error) {
+	type indexed struct {
+		modTime int64
+		size    int64
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT path, mod_time, size FROM indexed_files")
+	if err != nil {
+		return 0, err
+	}
+	current := make(map[string]indexed)
+	for rows.Next() {
+		var path string
+		var row indexed
+		if err := rows.Scan(&path, &row.modTime, &row.size); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		current[path] = row
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var changed []filesearch.Entry
+	err = filesearch.Walk(ctx, opts, func(entry filesearch.Entry) error {
+		old, exists := current[entry.Path]
+		delete(current, entry.Path)
+		if !exists || old.modTime != entry.ModTime.Unix() || old.size != entry.Size {
+			changed = append(changed, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Lo que queda en current ya no existe o quedó fuera de los filtros.
+	removed := make([]string, 0, len(current))
+	for path := range current {
+		removed = append(removed, path)
+	}
+
+	changes := len(changed) + len(removed)
+	for len(changed) > 0 || len(removed) > 0 {
+		n := min(len(changed), fileSyncBatch)
+		m := min(len(removed), fileSyncBatch-n)
+		if err := d.writeFileChanges(ctx, changed[:n], removed[:m]); err != nil {
+			return 0, err
+		}
+		changed, removed = changed[n:], removed[m:]
+	}
+	return changes, nil
+}
+
+// writeFileChanges guarda en una transacción los archivos modificados y elimina los que
+// ya no existen.
+func (d *Database) writeFileChanges(ctx context.Context, changed []filesearch.Entry, removed []string) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	upsert, err := tx.PrepareContext(ctx, `INSERT INTO indexed_files (root, path, name, is_dir, mod_time, size)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET root = excluded.root, name = excluded.name, is_dir = excluded.is_dir,
+			mod_time = excluded.mod_time, size = excluded.size, indexed_at = CURRENT_TIMESTAMP`)
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+
+	for _, entry := range changed {
+		if _, err := upsert.ExecContext(ctx, entry.Root, entry.Path, entry.Name, entry.IsDir, entry.ModTime.Unix(), entry.Size); err != nil {
+			return err
+		}
+	}
+	for _, path := range removed {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM indexed_files WHERE path = ?", path); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchFiles devuelve los archivos cuyo nombre contiene las letras de la consulta en
+// orden (el mismo criterio de subsecuencia que fuzzy.Score), como candidatos a puntuar.
+func (d *Database) SearchFiles(query string) ([]Link, error) {
+	var pattern strings.Builder
+	pattern.WriteByte('%')
+	for _, r := range strings.Join(strings.Fields(query), "") {
+		if r == '%' || r == '_' || r == '\\' {
+			pattern.WriteByte('\\')
+		}
+		pattern.WriteRune(r)
+		pattern.WriteByte('%')
+	}
+
+	rows, err := d.db.Query(`SELECT id, path, name, is_dir FROM indexed_files
+		WHERE name LIKE ? ESCAPE '\' ORDER BY is_dir DESC, length(name) LIMIT ?`, pattern.String(), fileCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []Link
+	for rows.Next() {
+		var id int
+		var path, name string
+		var isDir bool
+		if err := rows.Scan(&id, &path, &name, &isDir); err != nil {
+			log.Println("Error scanning indexed file:", err)
+			continue
+		}
+		files = append(files, fileLink(id, path, name, isDir))
+	}
+	return files, rows.Err()
+}
+
+func (d *Database) GetIndexedFileByID(id int) (*Link, error) {
+	var path, name string
+	var isDir bool
+	err := d.db.QueryRow("SELECT path, name, is_dir FROM indexed_files WHERE id = ?", id).Scan(&path, &name, &isDir)
+	if err != nil {
+		return nil, err
+	}
+	link := fileLink(id, path, name, isDir)
+	return &link, nil
+}
+
+// ClearFileIndex vacía el índice de archivos (al desactivar la búsqueda de archivos).
+func (d *Database) ClearFileIndex() error {
+	_, err := d.db.Exec("DELETE FROM indexed_files")
+	return err
+}
+
+// fileLink presenta un archivo indexado como resultado del launcher.
+func fileLink(id int, path, name string, isDir bool) Link {
+	actionType := ActionFile
+	if isDir {
+		actionType = ActionFolder
+	}
+	return Link{
+		ID:          id,
+		Name:        name,
+		URL:         path,
+		Type:        actionType,
+		Description: filepath.Dir(path),
+		Category:    "Archivos",
+		Source:      SourceFile,
+	}
+}
+
+// ============ Métodos para el Historial del Portapapeles ============
+
+const clipboardSelect = "SELECT id, content, pinned, copy_count, created_at, last_copied_at FROM clipboard_history"
+
+func scanClipboardEntry(row interface{ Scan(...any) error }) (ClipboardEntry, error) {
+	var entry ClipboardEntry
+	err := row.Scan(&entry.ID, &entry.Content, &entry.Pinned, &entry.CopyCount, &entry.CreatedAt, &entry.LastCopiedAt)
+	return entry, err
+}
+
+// AddClipboardEntry guarda un texto copiado. Si ya estaba en el historial solo se actualiza
+// su fecha, de modo que vuelve al principio sin duplicarse. Después se recorta el historial
+// a los limit textos no fijados más recientes.
+func (d *Database) AddClipboardEntry(content string, limit int) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO clipboard_history (content) VALUES (?)
+		ON CONFLICT(content) DO UPDATE SET copy_count = copy_count + 1,
+			last_copied_at = strftime('%Y-%m-%d %H:%M:%f', 'now')`, content)
+	if err != nil {
+		return err
+	}
+	if err := trimClipboardHistory(tx, limit); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// trimClipboardHistory elimina los textos no fijados que exceden el límite.
+func trimClipboardHistory(tx *sql.Tx, limit int) error {
+	_, err := tx.Exec(`DELETE FROM clipboard_history WHERE pinned = 0 AND id NOT IN (
+		SELECT id FROM clipboard_history WHERE pinned = 0 ORDER BY last_copied_at DESC, id DESC LIMIT ?)`, limit)
+	return err
+}
+
+// GetClipboardHistory devuelve los textos que contienen todas las palabras de la consulta
+// (todos si está vacía): primero los fijados y luego del más reciente al más antiguo.
+func (d *Database) GetClipboardHistory(query string, limit int) ([]ClipboardEntry, error) {
+	var where []string
+	var args []any
+	for _, word := range strings.Fields(query) {
+		where = append(where, `content LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(word)+"%")
+	}
+	sqlQuery := clipboardSelect
+	if len(where) > 0 {
+		sqlQuery += " WHERE " + strings.Join(where, " AND ")
+	}
+	sqlQuery += " ORDER BY pinned DESC, last_copied_at DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := d.db.Query(sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []ClipboardEntry{}
+	for rows.Next() {
+		entry, err := scanClipboardEntry(rows)
+		if err != nil {
+			log.Println("Error scanning clipboard entry:", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (d *Database) GetClipboardEntry(id int) (*ClipboardEntry, error) {
+	entry, err := scanClipboardEntry(d.db.QueryRow(clipboardSelect+" WHERE id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// TouchClipboardEntry marca un texto del historial como recién copiado, para que vuelva al
+// principio.
+func (d *Database) TouchClipboardEntry(id int) error {
+	_, err := d.db.Exec("UPDATE clipboard_history SET last_copied_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = ?", id)
+	return err
+}
+
+// SetClipboardPinned fija o libera un texto del historial.
+func (d *Database) SetClipboardPinned(id int, pinned bool) error {
+	_, err := d.db.Exec("UPDATE clipboard_history SET pinned = ? WHERE id = ?", pinned, id)
+	return err
+}
+
+func (d *Database) DeleteClipboardEntry(id int) error {
+	_, err := d.db.Exec("DELETE FROM clipboard_history WHERE id = ?", id)
+	return err
+}
+
+// ClearClipboardHistory elimina todos los textos del historial salvo los fijados.
+func (d *Database) ClearClipboardHistory() error {
+	_, err := d.db.Exec("DELETE FROM clipboard_history WHERE pinned = 0")
+	return err
+}
+
+// escapeLike escapa los comodines de LIKE para usarlos con ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // ============ Métodos para Estadísticas ============
 
 // LogUsage registra el uso de una herramienta (links o transcription).
@@ -342,6 +1249,119 @@ func (d *Database) LogUsage(toolType string, dayOfWeek string) error {
 	return err
 }
 
+// RecordLaunch registra la apertura de un link y actualiza su frecencia: el valor anterior
+// se decae según el tiempo transcurrido (ver decayFrecency) y se suma 1 por esta apertura.
+func (d *Database) RecordLaunch(linkID int) error {
+	now := time.Now()
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("INSERT INTO link_launches (link_id) VALUES (?)", linkID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var frecency float64
+	var lastLaunched int64
+	err = tx.QueryRow("SELECT frecency, last_launched_at FROM link_stats WHERE link_id = ?", linkID).Scan(&frecency, &lastLaunched)
+	if err != nil && err != sql.ErrNoRows {
+		tx.Rollback()
+		return err
+	}
+
+	frecency = decayFrecency(frecency, lastLaunched, now) + 1
+	_, err = tx.Exec(`INSERT INTO link_stats (link_id, launch_count, frecency, last_launched_at) VALUES (?, 1, ?, ?)
+		ON CONFLICT(link_id) DO UPDATE SET launch_count = launch_count + 1, frecency = excluded.frecency, last_launched_at = excluded.last_launched_at`,
+		linkID, frecency, now.Unix())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetLinkFrecencies devuelve la frecencia actual (ya decaída) de cada link abierto alguna vez.
+func (d *Database) GetLinkFrecencies(ctx context.Context) (map[int]float64, error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT link_id, frecency, last_launched_at FROM link_stats")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	frecencies := make(map[int]float64)
+	for rows.Next() {
+		var linkID int
+		var frecency float64
+		var lastLaunched int64
+		if err := rows.Scan(&linkID, &frecency, &lastLaunched); err != nil {
+			log.Println("Error scanning link stat:", err)
+			continue
+		}
+		frecencies[linkID] = decayFrecency(frecency, lastLaunched, now)
+	}
+
+	return frecencies, rows.Err()
+}
+
+// RecordQueryPick recuerda que para la consulta se eligió el link indicado. Se guardan
+// todos los prefijos de la consulta, así elegir Jira escribiendo "jir" enseña también "j".
+func (d *Database) RecordQueryPick(query string, linkID int) error {
+	prefixes := queryPrefixes(query)
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range prefixes {
+		_, err := tx.Exec(`INSERT INTO query_picks (prefix, link_id, pick_count) VALUES (?, ?, 1)
+			ON CONFLICT(prefix, link_id) DO UPDATE SET pick_count = pick_count + 1, last_picked_at = CURRENT_TIMESTAMP`,
+			prefix, linkID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetQueryPicks devuelve cuántas veces se eligió cada link para exactamente esta consulta.
+// Las consultas más largas que maxLearnedPrefix se buscan por su prefijo guardado.
+func (d *Database) GetQueryPicks(ctx context.Context, query string) (map[int]int, error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT link_id, pick_count FROM query_picks WHERE prefix = ?", queryPickKey(query))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	picks := make(map[int]int)
+	for rows.Next() {
+		var linkID, count int
+		if err := rows.Scan(&linkID, &count); err != nil {
+			log.Println("Error scanning query pick:", err)
+			continue
+		}
+		picks[linkID] = count
+	}
+
+	return picks, rows.Err()
+}
+
+// ResetQueryPicks olvida todas las consultas aprendidas para un link.
+func (d *Database) ResetQueryPicks(linkID int) error {
+	_, err := d.db.Exec("DELETE FROM query_picks WHERE link_id = ?", linkID)
+	return err
+}
+
 // GetUsageStats recupera el conteo de uso agrupado por día y herramienta.
 func (d *Database) GetUsageStats() ([]UsageLog, error) {
 	rows, err := d.db.Query(`
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDatabase abre una base de datos nueva, con todas las migraciones, en un
+// directorio temporal.
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+	return openTestDatabase(t, filepath.Join(t.TempDir(), "vallet.db"))
+}
+
+// openTestDatabase abre la base de datos de path y la cierra al terminar el test.
+func openTestDatabase(t *testing.T, path string) *Database {
+	t.Helper()
+	d, err := openDatabase(path)
+	if err != nil {
+		t.Fatalf("openDatabase: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// execSQL ejecuta sentencias directamente sobre el archivo de path, sin migraciones.
+func execSQL(t *testing.T, path string, queries ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+}
+
+func TestMigrateAppliesAllVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vallet.db")
+	d := openTestDatabase(t, path)
+
+	version, err := d.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latestSchemaVersion() {
+		t.Errorf("SchemaVersion() = %d, se esperaba %d", version, latestSchemaVersion())
+	}
+	var recorded int
+	if err := d.db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&recorded); err != nil {
+		t.Fatal(err)
+	}
+	if recorded != len(migrations) {
+		t.Errorf("schema_migrations tiene %d filas, se esperaban %d", recorded, len(migrations))
+	}
+
+	// Abrirla de nuevo no vuelve a aplicar nada.
+	d.Close()
+	d = openTestDatabase(t, path)
+	if err := d.db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&recorded); err != nil {
+		t.Fatal(err)
+	}
+	if recorded != len(migrations) {
+		t.Errorf("tras reabrir, schema_migrations tiene %d filas", recorded)
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vallet.db")
+	execSQL(t, path, fmt.Sprintf("PRAGMA user_version = %d", latestSchemaVersion()+1))
+
+	d, err := openDatabase(path)
+	if err == nil {
+		d.Close()
+		t.Fatal("openDatabase abrió una base de datos de una versión más nueva")
+	}
+	if !strings.Contains(err.Error(), "versión de esquema") {
+		t.Errorf("openDatabase() error = %v", err)
+	}
+}
+
+func TestMigrateLegacyCategoriesToFolderIDs(t *testing.T) {
+	// Base de datos anterior al sistema de migraciones: links.category guarda el nombre de
+	// la carpeta y 'General' no es la primera carpeta.
+	path := filepath.Join(t.TempDir(), "vallet.db")
+	execSQL(t, path,
+		`CREATE TABLE links (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, url TEXT NOT NULL,
+			description TEXT, category TEXT, created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT)`,
+		`CREATE TABLE folders (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE,
+			description TEXT, created_at DATETIME DEFAULT CURRENT_TIMESTAMP)`,
+		`INSERT INTO folders (id, name) VALUES (1, 'Trabajo'), (2, 'General'), (3, 'Personal')`,
+		`INSERT INTO links (id, name, url, category) VALUES
+			(1, 'jira', 'https://jira.example.com', 'Trabajo'),
+			(2, 'gh', 'https://github.com', 'general'),
+			(3, 'yt', 'https://youtube.com', ' Personal '),
+			(4, 'mail', 'https://mail.example.com', ''),
+			(5, 'wiki', 'https://wiki.example.com', NULL),
+			(6, 'banco', 'https://banco.example.com', 'Finanzas')`,
+	)
+
+	d := openTestDatabase(t, path)
+
+	generalID, err := d.defaultFolderID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generalID != 2 {
+		t.Errorf("default_folder_id = %d, se esperaba el id original de General (2)", generalID)
+	}
+
+	var financesID int
+	if err := d.db.QueryRow("SELECT id FROM folders WHERE name = 'Finanzas'").Scan(&financesID); err != nil {
+		t.Fatalf("no se creó la carpeta de la categoría huérfana: %v", err)
+	}
+	want := map[int]int{1: 1, 2: 2, 3: 3, 4: 2, 5: 2, 6: financesID}
+	for id, folderID := range want {
+		link, err := d.GetLinkByID(id)
+		if err != nil {
+			t.Fatalf("GetLinkByID(%d): %v", id, err)
+		}
+		if link.FolderID != folderID {
+			t.Errorf("link %s: folder_id = %d, se esperaba %d", link.Name, link.FolderID, folderID)
+		}
+	}
+
+	// Los links nuevos sin carpeta van a General.
+	id, err := d.CreateLink(Link{Name: "nuevo", URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link, _ := d.GetLinkByID(int(id)); link == nil || link.FolderID != generalID {
+		t.Errorf("link nuevo = %+v, se esperaba la carpeta General", link)
+	}
+}
+
+// createFolder crea una carpeta y devuelve su id.
+func createFolder(t *testing.T, d *Database, name string, parentID int) int {
+	t.Helper()
+	id, err := d.CreateFolder(Folder{Name: name, ParentID: parentID})
+	if err != nil {
+		t.Fatalf("CreateFolder(%s): %v", name, err)
+	}
+	return int(id)
+}
+
+// createLink crea un link en la carpeta indicada y devuelve su id.
+func createLink(t *testing.T, d *Database, link Link) int {
+	t.Helper()
+	id, err := d.CreateLink(link)
+	if err != nil {
+		t.Fatalf("CreateLink(%s): %v", link.Name, err)
+	}
+	return int(id)
+}
+
+func TestMoveFolder(t *testing.T) {
+	d := newTestDatabase(t)
+	work := createFolder(t, d, "Trabajo", 0)
+	clients := createFolder(t, d, "Clientes", work)
+	acme := createFolder(t, d, "Acme", clients)
+	personal := createFolder(t, d, "Personal", 0)
+
+	for _, tt := range []struct {
+		id, parentID int
+		reason       string
+	}{
+		{work, work, "dentro de sí misma"},
+		{work, clients, "dentro de su hija"},
+		{work, acme, "dentro de su nieta"},
+		{clients, 999, "a una carpeta que no existe"},
+		{999, personal, "una carpeta que no existe"},
+		{999, 0, "una carpeta que no existe al primer nivel"},
+	} {
+		if err := d.MoveFolder(tt.id, tt.parentID); err == nil {
+			t.Errorf("MoveFolder(%d, %d) se permitió mover %s", tt.id, tt.parentID, tt.reason)
+		}
+	}
+
+	if err := d.MoveFolder(clients, personal); err != nil {
+		t.Fatalf("MoveFolder: %v", err)
+	}
+	if err := d.MoveFolder(work, acme); err != nil {
+		t.Errorf("MoveFolder a una carpeta que ya no es descendiente: %v", err)
+	}
+	if err := d.MoveFolder(work, 0); err != nil {
+		t.Errorf("MoveFolder al primer nivel: %v", err)
+	}
+
+	var path string
+	if err := d.db.QueryRow("SELECT path FROM folder_paths WHERE id = ?", acme).Scan(&path); err != nil {
+		t.Fatal(err)
+	}
+	if path != "Personal/Clientes/Acme" {
+		t.Errorf("ruta de Acme = %q, se esperaba Personal/Clientes/Acme", path)
+	}
+}
+
+func TestDeleteFolder(t *testing.T) {
+	d := newTestDatabase(t)
+	generalID, err := d.defaultFolderID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := createFolder(t, d, "Trabajo", 0)
+	clients := createFolder(t, d, "Clientes", work)
+	acme := createFolder(t, d, "Acme", clients)
+	personal := createFolder(t, d, "Personal", 0)
+
+	inClients := createLink(t, d, Link{Name: "crm", URL: "https://crm.example.com", FolderID: clients})
+	inAcme := createLink(t, d, Link{Name: "acme", URL: "https://acme.example.com", FolderID: acme})
+	inPersonal := createLink(t, d, Link{Name: "yt", URL: "https://youtube.com", FolderID: personal})
+
+	// Una subcarpeta: toda su rama pasa a la carpeta padre.
+	if err := d.DeleteFolder(clients); err != nil {
+		t.Fatalf("DeleteFolder(Clientes): %v", err)
+	}
+	// Una carpeta de primer nivel: sus links van a General.
+	if err := d.DeleteFolder(personal); err != nil {
+		t.Fatalf("DeleteFolder(Personal): %v", err)
+	}
+
+	for id, want := range map[int]int{inClients: work, inAcme: work, inPersonal: generalID} {
+		link, err := d.GetLinkByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.FolderID != want {
+			t.Errorf("link %s: folder_id = %d, se esperaba %d", link.Name, link.FolderID, want)
+		}
+	}
+
+	var remaining int
+	if err := d.db.QueryRow("SELECT COUNT(*) FROM folders WHERE id IN (?, ?, ?)", clients, acme, personal).Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Errorf("quedan %d carpetas borradas", remaining)
+	}
+
+	if err := d.DeleteFolder(acme); err == nil || !strings.Contains(err.Error(), "no encontrada") {
+		t.Errorf("DeleteFolder de una carpeta borrada = %v, se esperaba 'carpeta no encontrada'", err)
+	}
+}
+
+func TestDeleteFolderKeepsGeneral(t *testing.T) {
+	d := newTestDatabase(t)
+	generalID, err := d.defaultFolderID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DeleteFolder(generalID); err == nil {
+		t.Error("se permitió borrar la carpeta General")
+	}
+
+	// Tampoco se puede borrar como parte de otra rama.
+	archive := createFolder(t, d, "Archivo", 0)
+	if err := d.MoveFolder(generalID, archive); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DeleteFolder(archive); err == nil {
+		t.Error("se permitió borrar una carpeta que contiene a General")
+	}
+	var exists bool
+	d.db.QueryRow("SELECT EXISTS(SELECT 1 FROM folders WHERE id = ?)", archive).Scan(&exists)
+	if !exists {
+		t.Error("el intento fallido borró la carpeta Archivo")
+	}
+}
+
+// linkNames devuelve los nombres de los links, en orden.
+func linkNames(links []Link) []string {
+	names := make([]string, len(links))
+	for i, link := range links {
+		names[i] = link.Name
+	}
+	return names
+}
+
+func TestSearchLinksTextFTSRanking(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	if !d.fts {
+		t.Skip("SQLite sin FTS5")
+	}
+	createLink(t, d, Link{Name: "notas", URL: "https://notas.example.com", Description: "Documentación del equipo"})
+	createLink(t, d, Link{Name: "docs", URL: "https://intranet.example.com", Description: "Wiki"})
+	createLink(t, d, Link{Name: "api", URL: "https://docs.example.com/api", Description: "Referencia"})
+	createLink(t, d, Link{Name: "correo", URL: "https://mail.example.com", Description: "Bandeja de entrada"})
+
+	links, err := d.searchLinksText(ctx, "doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// BM25 pondera el nombre (10) sobre la URL (2) y ésta sobre la descripción (1).
+	if got, want := strings.Join(linkNames(links), ","), "docs,api,notas"; got != want {
+		t.Errorf("searchLinksText(\"doc\") = %s, se esperaba %s", got, want)
+	}
+
+	// Sin acentos ni distinción de mayúsculas.
+	links, err = d.searchLinksText(ctx, "DOCUMENTACION")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := linkNames(links); len(got) != 1 || got[0] != "notas" {
+		t.Errorf("searchLinksText(\"DOCUMENTACION\") = %v, se esperaba [notas]", got)
+	}
+}
+
+func TestSearchLinksTextLikeFallback(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	createLink(t, d, Link{Name: "GitHub", URL: "https://github.com"})
+	createLink(t, d, Link{Name: "cpp", URL: "https://cppreference.com", Description: "Referencia de C++"})
+
+	// FTS solo busca prefijos de palabra: "hub" no encuentra "GitHub".
+	if d.fts {
+		if links, _ := d.searchLinksText(ctx, "hub"); len(links) != 0 {
+			t.Errorf("FTS: searchLinksText(\"hub\") = %v", linkNames(links))
+		}
+	}
+	// Una consulta sin letras ni dígitos no se puede expresar en FTS y se busca con LIKE.
+	if links, err := d.searchLinksText(ctx, "++"); err != nil || len(links) != 1 || links[0].Name != "cpp" {
+		t.Errorf("searchLinksText(\"++\") = %v, %v; se esperaba [cpp]", linkNames(links), err)
+	}
+
+	d.fts = false
+	if links, err := d.searchLinksText(ctx, "hub"); err != nil || len(links) != 1 || links[0].Name != "GitHub" {
+		t.Errorf("LIKE: searchLinksText(\"hub\") = %v, %v; se esperaba [GitHub]", linkNames(links), err)
+	}
+}
+
+func TestLinksFTSCreatedOnStartup(t *testing.T) {
+	ctx := context.Background()
+	// Simula una base de datos migrada con un SQLite sin FTS5: la migración 5 quedó
+	// registrada sin crear links_fts.
+	path := filepath.Join(t.TempDir(), "vallet.db")
+	d := openTestDatabase(t, path)
+	createLink(t, d, Link{Name: "jira", URL: "https://jira.example.com"})
+	d.Close()
+	execSQL(t, path,
+		"DROP TRIGGER links_fts_ai", "DROP TRIGGER links_fts_ad", "DROP TRIGGER links_fts_au",
+		"DROP TABLE links_fts",
+	)
+
+	d = openTestDatabase(t, path)
+	if !d.fts {
+		t.Fatal("no se creó links_fts al abrir la base de datos")
+	}
+	if links, err := d.searchLinksFTS(ctx, ftsMatchQuery("jir")); err != nil || len(links) != 1 {
+		t.Errorf("searchLinksFTS tras crear el índice = %v, %v; se esperaba el link existente", linkNames(links), err)
+	}
+	createLink(t, d, Link{Name: "jenkins", URL: "https://ci.example.com"})
+	if links, err := d.searchLinksFTS(ctx, ftsMatchQuery("jenk")); err != nil || len(links) != 1 {
+		t.Errorf("los triggers no indexaron un link nuevo: %v, %v", linkNames(links), err)
+	}
+}
+
+func TestRecordLaunchDecaysFrecency(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDatabase(t)
+	jira := createLink(t, d, Link{Name: "jira", URL: "https://jira.example.com"})
+	wiki := createLink(t, d, Link{Name: "wiki", URL: "https://wiki.example.com"})
+
+	for range 2 {
+		if err := d.RecordLaunch(jira); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frecencies, err := d.GetLinkFrecencies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := frecencies[jira]; math.Abs(got-2) > 0.01 {
+		t.Errorf("frecencia tras dos aperturas seguidas = %.3f, se esperaba 2", got)
+	}
+	if _, ok := frecencies[wiki]; ok {
+		t.Error("un link nunca abierto tiene frecencia")
+	}
+
+	// Una vida media después, lo acumulado vale la mitad.
+	backdate := func(id int, age time.Duration) {
+		t.Helper()
+		if _, err := d.db.Exec("UPDATE link_stats SET last_launched_at = last_launched_at - ? WHERE link_id = ?", int64(age.Seconds()), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backdate(jira, frecencyHalfLife)
+	frecencies, _ = d.GetLinkFrecencies(ctx)
+	if got := frecencies[jira]; math.Abs(got-1) > 0.01 {
+		t.Errorf("frecencia una vida media después = %.3f, se esperaba 1", got)
+	}
+
+	// La nueva apertura se suma a la frecencia ya decaída.
+	if err := d.RecordLaunch(jira); err != nil {
+		t.Fatal(err)
+	}
+	frecencies, _ = d.GetLinkFrecencies(ctx)
+	if got := frecencies[jira]; math.Abs(got-2) > 0.01 {
+		t.Errorf("frecencia tras abrirlo otra vez = %.3f, se esperaba 1 + 1", got)
+	}
+
+	var launches, count int
+	d.db.QueryRow("SELECT COUNT(*) FROM link_launches WHERE link_id = ?", jira).Scan(&launches)
+	d.db.QueryRow("SELECT launch_count FROM link_stats WHERE link_id = ?", jira).Scan(&count)
+	if launches != 3 || count != 3 {
+		t.Errorf("aperturas registradas = %d, launch_count = %d; se esperaban 3", launches, count)
+	}
+
+	// Un link abierto hace mucho pierde frente a uno abierto hoy.
+	if err := d.RecordLaunch(wiki); err != nil {
+		t.Fatal(err)
+	}
+	backdate(jira, 10*frecencyHalfLife)
+	frecencies, _ = d.GetLinkFrecencies(ctx)
+	if frecencies[jira] >= frecencies[wiki] {
+		t.Errorf("frecencias jira = %.3f, wiki = %.3f; se esperaba que wiki ganara", frecencies[jira], frecencies[wiki])
+	}
+}
+
+func TestSearchLinksScoredCanceled(t *testing.T) {
+	d := newTestDatabase(t)
+	createLink(t, d, Link{Name: "jira", URL: "https://jira.example.com"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if scored, err := d.searchLinksScored(ctx, "jir"); err != nil || len(scored) != 1 {
+		t.Fatalf("searchLinksScored(\"jir\") = %v, %v; se esperaba un resultado", scored, err)
+	}
+	cancel()
+	if _, err := d.searchLinksScored(ctx, "jir"); !errors.Is(err, context.Canceled) {
+		t.Errorf("searchLinksScored con el contexto cancelado: error = %v, se esperaba context.Canceled", err)
+	}
+}
+
+func TestTouchClipboardEntryMovesItFirst(t *testing.T) {
+	d := newTestDatabase(t)
+	for _, content := range []string{"primero", "segundo"} {
+		if err := d.AddClipboardEntry(content, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Fechas fijas en el pasado: dos textos copiados en el mismo milisegundo empatarían.
+	if _, err := d.db.Exec(`UPDATE clipboard_history SET last_copied_at = CASE content
+		WHEN 'primero' THEN '2020-01-01 00:00:00.000' ELSE '2020-01-02 00:00:00.000' END`); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := d.GetClipboardHistory("", 10)
+	if err != nil || len(entries) != 2 || entries[0].Content != "segundo" {
+		t.Fatalf("GetClipboardHistory = %v, %v; se esperaba \"segundo\" primero", entries, err)
+	}
+
+	if err := d.TouchClipboardEntry(entries[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = d.GetClipboardHistory("", 10)
+	if entries[0].Content != "primero" || entries[0].CopyCount != 1 {
+		t.Errorf("tras TouchClipboardEntry el primero es %q (copy_count %d), se esperaba \"primero\" (1)", entries[0].Content, entries[0].CopyCount)
+	}
+}
+
+func TestMigrateWhisperThreadsAuto(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vallet.db")
+	d := openTestDatabase(t, path)
+	if got, _ := d.GetSetting("whisper_threads"); got != "0" {
+		t.Errorf("whisper_threads en una base nueva = %q, se esperaba \"0\"", got)
+	}
+	d.Close()
+
+	// Una base anterior con los núcleos fijados al instalar pasa a usar todos los núcleos;
+	// un valor elegido por el usuario se conserva.
+	for value, want := range map[string]string{
+		strconv.Itoa(runtime.NumCPU()):     "0",
+		strconv.Itoa(runtime.NumCPU() + 4): "0",
+		"":                                 "0",
+		"1":                                "1",
+	} {
+		if value == "1" && runtime.NumCPU() == 1 {
+			continue
+		}
+		execSQL(t, path,
+			fmt.Sprintf("UPDATE settings SET value = '%s' WHERE key = 'whisper_threads'", value),
+			"DELETE FROM schema_migrations WHERE version = 20",
+			"PRAGMA user_version = 19",
+		)
+		d := openTestDatabase(t, path)
+		if got, _ := d.GetSetting("whisper_threads"); got != want {
+			t.Errorf("whisper_threads %q tras migrar = %q, se esperaba %q", value, got, want)
+		}
+		d.Close()
+	}
+}
@@ -0,0 +1,96 @@
+package desktop
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DataDirs devuelve los directorios de datos XDG en orden de prioridad:
+// $XDG_DATA_HOME (por defecto ~/.local/share) y luego $XDG_DATA_DIRS
+// (por defecto /usr/local/share:/usr/share).
+func DataDirs() []string {
+	var dirs []string
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	if dataHome != "" {
+		dirs = append(dirs, dataHome)
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// ApplicationDirs devuelve los directorios "applications" de cada directorio de datos XDG.
+func ApplicationDirs() []string {
+	var dirs []string
+	for _, dir := range DataDirs() {
+		dirs = append(dirs, filepath.Join(dir, "applications"))
+	}
+	return dirs
+}
+
+// Find localiza una aplicación por su ruta o por su identificador de escritorio
+// ("firefox.desktop"). Según la especificación, los guiones del identificador pueden
+// corresponder a subdirectorios ("kde-konsole.desktop" -> applications/kde/konsole.desktop).
+func Find(idOrPath string) (*Entry, error) {
+	if filepath.IsAbs(idOrPath) || strings.ContainsRune(idOrPath, filepath.Separator) {
+		return Load(idOrPath)
+	}
+
+	id := idOrPath
+	if !strings.HasSuffix(id, ".desktop") {
+		id += ".desktop"
+	}
+
+	for _, dir := range ApplicationDirs() {
+		for _, candidate := range idPaths(dir, id) {
+			if _, err := os.Stat(candidate); err == nil {
+				entry, err := Load(candidate)
+				if err != nil {
+					return nil, err
+				}
+				entry.ID = id
+				return entry, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no se encontró la aplicación %s", id)
+}
+
+// idPaths devuelve las rutas posibles de un identificador dentro de un directorio.
+func idPaths(dir, id string) []string {
+	paths := []string{filepath.Join(dir, id)}
+	parts := strings.Split(id, "-")
+	for i := 1; i < len(parts); i++ {
+		sub := filepath.Join(append([]string{dir}, parts[:i]...)...)
+		paths = append(paths, filepath.Join(sub, strings.Join(parts[i:], "-")))
+	}
+	return paths
+}
+
+// FileID calcula el identificador de escritorio de un archivo dentro de un directorio
+// "applications" (las subcarpetas se unen con '-').
+func FileID(appDir, path string) string {
+	rel, err := filepath.Rel(appDir, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+}
@@ -0,0 +1,214 @@
+package desktop
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Entry es un archivo .desktop (especificación freedesktop.org Desktop Entry) del que se
+// guardan las claves del grupo [Desktop Entry], incluidas las localizadas (Name[es]).
+type Entry struct {
+	Path string            // Ruta del archivo .desktop.
+	ID   string            // Identificador (ej: "org.gnome.Nautilus.desktop").
+	keys map[string]string // Claves sin procesar del grupo [Desktop Entry].
+}
+
+// Load lee y analiza un archivo .desktop.
+func Load(path string) (*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entry := &Entry{Path: path, ID: filepath.Base(path), keys: make(map[string]string)}
+	inMainGroup := false
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inMainGroup = line == "[Desktop Entry]"
+			continue
+		}
+		if !inMainGroup {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, exists := entry.keys[key]; !exists {
+			entry.keys[key] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(entry.keys) == 0 {
+		return nil, fmt.Errorf("%s no tiene grupo [Desktop Entry]", path)
+	}
+	return entry, nil
+}
+
+// Raw devuelve el valor sin procesar de una clave.
+func (e *Entry) Raw(key string) string {
+	return e.keys[key]
+}
+
+// String devuelve el valor de una clave de tipo string con los escapes (\s, \n, \t, \r, \\) resueltos.
+func (e *Entry) String(key string) string {
+	return unescapeValue(e.keys[key])
+}
+
+// LocaleString devuelve el valor localizado de una clave según el idioma del sistema
+// (LC_ALL, LC_MESSAGES o LANG), o el valor sin localizar si no hay traducción.
+func (e *Entry) LocaleString(key string) string {
+	for _, locale := range localeVariants() {
+		if value, ok := e.keys[key+"["+locale+"]"]; ok {
+			return unescapeValue(value)
+		}
+	}
+	return e.String(key)
+}
+
+// LocaleStrings devuelve una lista localizada separada por ';' (ej: Keywords).
+func (e *Entry) LocaleStrings(key string) []string {
+	var values []string
+	for _, v := range splitList(e.LocaleString(key)) {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Bool devuelve el valor de una clave booleana ("true"/"false").
+func (e *Entry) Bool(key string) bool {
+	return e.keys[key] == "true"
+}
+
+// Name devuelve el nombre localizado de la aplicación.
+func (e *Entry) Name() string {
+	return e.LocaleString("Name")
+}
+
+// Terminal indica si la aplicación debe ejecutarse dentro de una terminal.
+func (e *Entry) Terminal() bool {
+	return e.Bool("Terminal")
+}
+
+// WorkingDir devuelve el directorio de trabajo (clave Path), si lo hay.
+func (e *Entry) WorkingDir() string {
+	return e.String("Path")
+}
+
+// IsApplication indica si la entrada es una aplicación lanzable y visible en menús.
+func (e *Entry) IsApplication() bool {
+	if e.keys["Type"] != "Application" || e.keys["Exec"] == "" {
+		return false
+	}
+	return !e.Bool("Hidden")
+}
+
+// Locale devuelve la variante de idioma más específica usada para las claves localizadas
+// (ej: "es_ES"), o "" si el sistema no define idioma.
+func Locale() string {
+	if variants := localeVariants(); len(variants) > 0 {
+		return variants[0]
+	}
+	return ""
+}
+
+// localeVariants devuelve las variantes de idioma a probar, de la más específica a la
+// más general: "es_ES@euro" -> es_ES@euro, es_ES, es@euro, es.
+func localeVariants() []string {
+	locale := ""
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale = os.Getenv(env); locale != "" {
+			break
+		}
+	}
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+
+	locale, modifier, _ := strings.Cut(locale, "@")
+	locale, _, _ = strings.Cut(locale, ".")
+	lang, country, hasCountry := strings.Cut(locale, "_")
+
+	var variants []string
+	if hasCountry {
+		if modifier != "" {
+			variants = append(variants, lang+"_"+country+"@"+modifier)
+		}
+		variants = append(variants, lang+"_"+country)
+	}
+	if modifier != "" {
+		variants = append(variants, lang+"@"+modifier)
+	}
+	return append(variants, lang)
+}
+
+// unescapeValue resuelve los escapes de los valores de tipo string.
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			// Escapes desconocidos (como "\;" en listas) se conservan para el llamador.
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// splitList separa una lista por ';' respetando el escape "\;".
+func splitList(value string) []string {
+	var items []string
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == ';':
+			b.WriteByte(';')
+			i++
+		case value[i] == ';':
+			items = append(items, strings.TrimSpace(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	if rest := strings.TrimSpace(b.String()); rest != "" {
+		items = append(items, rest)
+	}
+	return items
+}
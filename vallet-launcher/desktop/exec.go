@@ -0,0 +1,178 @@
+package desktop
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Command construye la línea de comandos de la aplicación a partir de la clave Exec,
+// expandiendo los códigos de campo con los archivos o URLs recibidos:
+//
+//	%f  un archivo        %F  lista de archivos
+//	%u  una URL           %U  lista de URLs
+//	%i  --icon <Icon>     %c  nombre localizado
+//	%k  ruta del .desktop %%  un '%' literal
+//
+// Los códigos obsoletos (%d, %D, %n, %N, %v, %m) se eliminan.
+func (e *Entry) Command(targets []string) ([]string, error) {
+	args, err := splitExec(e.String("Exec"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", e.ID, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s: la clave Exec está vacía", e.ID)
+	}
+
+	var argv []string
+	for _, arg := range args {
+		switch arg {
+		case "%F", "%U":
+			argv = append(argv, targets...)
+			continue
+		case "%f", "%u":
+			if len(targets) > 0 {
+				argv = append(argv, targets[0])
+			}
+			continue
+		case "%i":
+			if icon := e.String("Icon"); icon != "" {
+				argv = append(argv, "--icon", icon)
+			}
+			continue
+		}
+
+		expanded := e.expandFieldCodes(arg, targets)
+		if expanded != "" || !strings.Contains(arg, "%") {
+			argv = append(argv, expanded)
+		}
+	}
+
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("%s: la clave Exec no contiene un programa", e.ID)
+	}
+	return argv, nil
+}
+
+// expandFieldCodes reemplaza los códigos de campo que aparecen dentro de un argumento
+// (ej: "--file=%f"). Los códigos de lista se reducen al primer valor.
+func (e *Entry) expandFieldCodes(arg string, targets []string) string {
+	var b strings.Builder
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '%' || i+1 == len(arg) {
+			b.WriteByte(arg[i])
+			continue
+		}
+		i++
+		switch arg[i] {
+		case '%':
+			b.WriteByte('%')
+		case 'f', 'F', 'u', 'U':
+			if len(targets) > 0 {
+				b.WriteString(targets[0])
+			}
+		case 'c':
+			b.WriteString(e.Name())
+		case 'k':
+			b.WriteString(e.Path)
+		case 'i':
+			b.WriteString(e.String("Icon"))
+		}
+		// Cualquier otro código (incluidos los obsoletos) se descarta.
+	}
+	return b.String()
+}
+
+// splitExec separa la clave Exec en argumentos. Los argumentos pueden ir entre comillas
+// dobles, dentro de las cuales \" \` \$ y \\ son escapes.
+func splitExec(value string) ([]string, error) {
+	var args []string
+	var b strings.Builder
+	inArg, inQuotes := false, false
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case inQuotes && c == '\\' && i+1 < len(value) && strings.ContainsRune("\"`$\\", rune(value[i+1])):
+			i++
+			b.WriteByte(value[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			inArg = true
+		case !inQuotes && (c == ' ' || c == '\t'):
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("comillas sin cerrar en Exec: %s", value)
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args, nil
+}
+
+// terminals son los emuladores de terminal conocidos y el argumento que precede al comando.
+var terminals = []struct {
+	name string
+	flag string
+}{
+	{"x-terminal-emulator", "-e"},
+	{"gnome-terminal", "--"},
+	{"konsole", "-e"},
+	{"xfce4-terminal", "-x"},
+	{"kitty", ""},
+	{"alacritty", "-e"},
+	{"foot", ""},
+	{"wezterm", "start --"},
+	{"tilix", "-e"},
+	{"xterm", "-e"},
+}
+
+// TerminalCommand envuelve argv para ejecutarlo dentro de un emulador de terminal
+// (Terminal=true). Se respeta $TERMINAL si está definido.
+func TerminalCommand(argv []string) ([]string, error) {
+	if term := os.Getenv("TERMINAL"); term != "" {
+		if path, err := exec.LookPath(term); err == nil {
+			return append([]string{path, "-e"}, argv...), nil
+		}
+	}
+
+	for _, t := range terminals {
+		path, err := exec.LookPath(t.name)
+		if err != nil {
+			continue
+		}
+		cmd := []string{path}
+		if t.flag != "" {
+			cmd = append(cmd, strings.Fields(t.flag)...)
+		}
+		return append(cmd, argv...), nil
+	}
+
+	return nil, fmt.Errorf("no se encontró un emulador de terminal para ejecutar %s", argv[0])
+}
+
+// Available indica si el programa indicado en TryExec existe. Las entradas sin TryExec
+// se consideran disponibles.
+func (e *Entry) Available() bool {
+	return TryExecAvailable(e.String("TryExec"))
+}
+
+// TryExecAvailable indica si existe el programa de un valor TryExec (ruta absoluta o
+// nombre a buscar en el PATH). Un valor vacío se considera disponible.
+func TryExecAvailable(tryExec string) bool {
+	if tryExec == "" {
+		return true
+	}
+	_, err := exec.LookPath(tryExec)
+	return err == nil
+}
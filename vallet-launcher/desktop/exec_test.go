@@ -0,0 +1,133 @@
+package desktop
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newEntry crea una entrada con las claves sin procesar indicadas, como si se hubiera
+// leído de /usr/share/applications/app.desktop.
+func newEntry(keys map[string]string) *Entry {
+	return &Entry{Path: "/usr/share/applications/app.desktop", ID: "app.desktop", keys: keys}
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		exec    string
+		targets []string
+		want    []string
+	}{
+		{"firefox %u", []string{"https://example.com"}, []string{"firefox", "https://example.com"}},
+		{"firefox %u", nil, []string{"firefox"}},
+		{"gimp %F", []string{"a.png", "b.png"}, []string{"gimp", "a.png", "b.png"}},
+		{"vlc %U --fullscreen", nil, []string{"vlc", "--fullscreen"}},
+		{"gedit %f", []string{"a.txt", "b.txt"}, []string{"gedit", "a.txt"}}, // %f recibe solo uno.
+		{"app --file=%f", []string{"a.txt"}, []string{"app", "--file=a.txt"}},
+		{"app --files=%F", []string{"a", "b"}, []string{"app", "--files=a"}}, // Dentro de un argumento, el primero.
+		{"app 100%% %%", nil, []string{"app", "100%", "%"}},
+		{"app %d %D %n %N %v %m file", nil, []string{"app", "file"}}, // Códigos obsoletos.
+		{"app %i %c %k", nil, []string{"app", "--icon", "app-icon", "Aplicación", "/usr/share/applications/app.desktop"}},
+		{"app --name=%c", nil, []string{"app", "--name=Aplicación"}},
+		// Comillas: agrupan espacios y admiten los escapes \" \` \$ y \\.
+		{`"/opt/Mi App/bin/app" --title "Hola mundo"`, nil, []string{"/opt/Mi App/bin/app", "--title", "Hola mundo"}},
+		{`sh -c "echo \\"hola\\" \\$HOME"`, nil, []string{"sh", "-c", `echo "hola" $HOME`}},
+		{`app ""`, nil, []string{"app", ""}},
+		{`app "%f"`, []string{"a b.txt"}, []string{"app", "a b.txt"}},
+		{"app\t  --flag", nil, []string{"app", "--flag"}},
+	}
+	for _, tt := range tests {
+		entry := newEntry(map[string]string{"Exec": tt.exec, "Name": "Aplicación", "Icon": "app-icon"})
+		got, err := entry.Command(tt.targets)
+		if err != nil {
+			t.Errorf("Command(%q, %q): %v", tt.exec, tt.targets, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Command(%q, %q) = %q, se esperaba %q", tt.exec, tt.targets, got, tt.want)
+		}
+	}
+}
+
+func TestCommandWithoutIcon(t *testing.T) {
+	got, err := newEntry(map[string]string{"Exec": "app %i"}).Command(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"app"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Command() = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	for _, exec := range []string{
+		"",
+		"   ",
+		`app "sin cerrar`,
+		"%f", // Sin archivos no queda programa.
+	} {
+		if got, err := newEntry(map[string]string{"Exec": exec}).Command(nil); err == nil {
+			t.Errorf("Command() con Exec=%q = %q, se esperaba un error", exec, got)
+		}
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	if !newEntry(map[string]string{"Terminal": "true"}).Terminal() {
+		t.Error("Terminal=true no se reconoció")
+	}
+	if newEntry(map[string]string{"Terminal": "false"}).Terminal() || newEntry(nil).Terminal() {
+		t.Error("Terminal sin valor true se reconoció como terminal")
+	}
+}
+
+// fakePath deja en PATH solo un directorio con los ejecutables indicados.
+func fakePath(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("TERMINAL", "")
+	return dir
+}
+
+func TestTerminalCommand(t *testing.T) {
+	argv := []string{"htop", "-d", "10"}
+	tests := []struct {
+		installed []string
+		terminal  string // $TERMINAL
+		want      []string
+	}{
+		{[]string{"xterm"}, "", []string{"xterm", "-e", "htop", "-d", "10"}},
+		{[]string{"gnome-terminal", "xterm"}, "", []string{"gnome-terminal", "--", "htop", "-d", "10"}},
+		{[]string{"kitty"}, "", []string{"kitty", "htop", "-d", "10"}},
+		{[]string{"wezterm"}, "", []string{"wezterm", "start", "--", "htop", "-d", "10"}},
+		{[]string{"mi-terminal", "xterm"}, "mi-terminal", []string{"mi-terminal", "-e", "htop", "-d", "10"}},
+		{[]string{"xterm"}, "no-instalada", []string{"xterm", "-e", "htop", "-d", "10"}},
+	}
+	for _, tt := range tests {
+		dir := fakePath(t, tt.installed...)
+		t.Setenv("TERMINAL", tt.terminal)
+		got, err := TerminalCommand(argv)
+		if err != nil {
+			t.Errorf("TerminalCommand() con %v: %v", tt.installed, err)
+			continue
+		}
+		want := append([]string{filepath.Join(dir, tt.want[0])}, tt.want[1:]...)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("TerminalCommand() con %v = %q, se esperaba %q", tt.installed, got, want)
+		}
+	}
+}
+
+func TestTerminalCommandWithoutTerminal(t *testing.T) {
+	fakePath(t)
+	if got, err := TerminalCommand([]string{"htop"}); err == nil {
+		t.Errorf("TerminalCommand() = %q, se esperaba un error", got)
+	}
+}
@@ -0,0 +1,54 @@
+package desktop
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// File es un archivo .desktop encontrado al recorrer los directorios de aplicaciones.
+type File struct {
+	ID      string    // Identificador de escritorio (ej: "org.gnome.Nautilus.desktop").
+	Path    string    // Ruta completa del archivo.
+	ModTime time.Time // Fecha de modificación, para detectar cambios.
+	Size    int64     // Tamaño en bytes, para detectar cambios.
+}
+
+// Scan recorre los directorios de aplicaciones XDG y devuelve un archivo por
+// identificador. Si el mismo identificador aparece en varios directorios, gana el de
+// mayor prioridad (así ~/.local/share/applications sobrescribe a /usr/share/applications).
+func Scan() []File {
+	seen := make(map[string]bool)
+	var files []File
+
+	for _, appDir := range ApplicationDirs() {
+		filepath.WalkDir(appDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Directorio inexistente o sin permisos: se ignora y se sigue con el resto.
+				if d != nil && d.IsDir() && path != appDir {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !strings.HasSuffix(d.Name(), ".desktop") {
+				return nil
+			}
+
+			id := FileID(appDir, path)
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			files = append(files, File{ID: id, Path: path, ModTime: info.ModTime(), Size: info.Size()})
+			return nil
+		})
+	}
+
+	return files
+}
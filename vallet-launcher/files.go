@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"vallet-launcher/filesearch"
+	"vallet-launcher/utils"
+)
+
+const (
+	// fileRescanInterval es cada cuánto se vuelve a recorrer las raíces configuradas.
+	fileRescanInterval = 15 * time.Minute
+	// maxIndexedFiles evita que una raíz enorme (ej: todo el disco) llene la base de datos.
+	maxIndexedFiles = 50000
+	// defaultFileSearchDepth se usa si file_search_max_depth no es un número válido.
+	defaultFileSearchDepth = 4
+)
+
+// startFileIndexer indexa los archivos al arrancar y vuelve a hacerlo periódicamente o
+// cuando cambia la configuración, hasta que se cancela el contexto.
+func (a *App) startFileIndexer(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(fileRescanInterval)
+		defer ticker.Stop()
+
+		for {
+			a.indexFiles(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-a.reindexFiles:
+			}
+		}
+	}()
+}
+
+// indexFiles actualiza el índice de archivos con la configuración actual.
+func (a *App) indexFiles(ctx context.Context) {
+	if enabled, _ := a.db.GetSetting("file_search_enabled"); enabled == "false" {
+		if err := a.db.ClearFileIndex(); err != nil {
+			fmt.Printf("❌ Error limpiando índice de archivos: %v\n", err)
+		}
+		return
+	}
+
+	start := time.Now()
+	changes, err := a.db.SyncFiles(ctx, a.fileSearchOptions())
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Printf("❌ Error indexando archivos: %v\n", err)
+		}
+		return
+	}
+	if changes > 0 {
+		fmt.Printf("🗂️ Índice de archivos actualizado (%d cambios en %s).\n", changes, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// fileSearchOptions lee la configuración de la búsqueda de archivos.
+func (a *App) fileSearchOptions() filesearch.Options {
+	roots, _ := a.db.GetSetting("file_search_roots")
+	include, _ := a.db.GetSetting("file_search_include")
+	exclude, _ := a.db.GetSetting("file_search_exclude")
+	depthSetting, _ := a.db.GetSetting("file_search_max_depth")
+
+	depth, err := strconv.Atoi(strings.TrimSpace(depthSetting))
+	if err != nil || depth < 0 {
+		depth = defaultFileSearchDepth
+	}
+
+	opts := filesearch.Options{
+		Include:  filesearch.ParseList(include),
+		Exclude:  filesearch.ParseList(exclude),
+		MaxDepth: depth,
+		MaxFiles: maxIndexedFiles,
+	}
+	for _, root := range filesearch.ParseList(roots) {
+		opts.Roots = append(opts.Roots, expandPath(root))
+	}
+	return opts
+}
+
+// RefreshFileIndex pide volver a indexar los archivos en segundo plano.
+func (a *App) RefreshFileIndex() {
+	select {
+	case a.reindexFiles <- struct{}{}:
+	default:
+		// Ya hay una reindexación pendiente.
+	}
+}
+
+// maxFileResults limita cuántos archivos se mezclan con el resto de resultados para no
+// tapar los links y aplicaciones.
+const maxFileResults = 8
+
+// fileProvider busca en el índice de archivos y carpetas.
+type fileProvider struct {
+	app *App
+}
+
+func (p *fileProvider) Name() string { return SourceFile }
+
+func (p *fileProvider) Search(ctx context.Context, query string) []Result {
+	if enabled, _ := p.app.db.GetSetting("file_search_enabled"); enabled == "false" {
+		return nil
+	}
+
+	files, err := p.app.db.SearchFiles(query)
+	if err != nil {
+		log.Println("Error buscando archivos:", err)
+		return nil
+	}
+
+	scored := scoreLinks(query, files, rankSignals{})
+	results := make([]Result, 0, min(len(scored), maxFileResults))
+	for _, s := range scored[:min(len(scored), maxFileResults)] {
+		results = append(results, linkResult(s, s.link.URL))
+	}
+	return results
+}
+
+// Execute abre el archivo o carpeta con el programa predeterminado del sistema.
+func (p *fileProvider) Execute(result Result) error {
+	file, err := p.app.db.GetIndexedFileByID(result.ID)
+	if err != nil {
+		return err
+	}
+	action, err := linkAction(*file, result.Query)
+	if err != nil {
+		return err
+	}
+	return p.app.runAction(action, result.Query)
+}
+
+// RevealFile muestra un archivo indexado seleccionado en el gestor de archivos.
+func (a *App) RevealFile(id int) error {
+	file, err := a.db.GetIndexedFileByID(id)
+	if err != nil {
+		return err
+	}
+	a.HideWindow()
+	return utils.RevealPath(file.URL)
+}
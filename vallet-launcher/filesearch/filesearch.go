@@ -0,0 +1,128 @@
+package filesearch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Options configura qué se indexa.
+type Options struct {
+	Roots    []string // Directorios raíz a recorrer.
+	Include  []string // Globs de archivos a incluir (vacío = todos). Las carpetas siempre se incluyen.
+	Exclude  []string // Globs de archivos o carpetas a excluir (las carpetas excluidas no se recorren).
+	MaxDepth int      // Profundidad máxima bajo cada raíz (0 = sin límite).
+	MaxFiles int      // Máximo de entradas a indexar (0 = sin límite).
+}
+
+// Entry es un archivo o carpeta encontrado durante el recorrido.
+type Entry struct {
+	Root    string    // Raíz bajo la que se encontró.
+	Path    string    // Ruta completa.
+	Name    string    // Nombre del archivo o carpeta.
+	IsDir   bool      // Si es una carpeta.
+	ModTime time.Time // Fecha de modificación.
+	Size    int64     // Tamaño en bytes (el que informe el sistema para las carpetas).
+}
+
+// Walk recorre las raíces aplicando los filtros y llama a fn por cada entrada. Se detiene
+// si el contexto se cancela (devolviendo ctx.Err()) o si se alcanza MaxFiles.
+func Walk(ctx context.Context, opts Options, fn func(Entry) error) error {
+	count := 0
+
+	for _, root := range opts.Roots {
+		root = filepath.Clean(root)
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			continue
+		}
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				// Sin permisos u otro error: se omite la entrada y se sigue.
+				if d != nil && d.IsDir() && path != root {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if path == root {
+				return nil
+			}
+
+			rel, _ := filepath.Rel(root, path)
+			rel = filepath.ToSlash(rel)
+
+			if MatchAny(opts.Exclude, d.Name(), rel) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !d.IsDir() && len(opts.Include) > 0 && !MatchAny(opts.Include, d.Name(), rel) {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if err := fn(Entry{Root: root, Path: path, Name: d.Name(), IsDir: d.IsDir(), ModTime: info.ModTime(), Size: info.Size()}); err != nil {
+				return err
+			}
+
+			count++
+			if opts.MaxFiles > 0 && count >= opts.MaxFiles {
+				return fs.SkipAll
+			}
+
+			// No bajar más allá de la profundidad máxima.
+			if d.IsDir() && opts.MaxDepth > 0 && strings.Count(rel, "/")+1 >= opts.MaxDepth {
+				return fs.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if opts.MaxFiles > 0 && count >= opts.MaxFiles {
+			break
+		}
+	}
+
+	return nil
+}
+
+// MatchAny indica si alguno de los globs coincide. Los patrones sin '/' se comparan con el
+// nombre ("*.pdf", "node_modules") y los que tienen '/' con la ruta relativa a la raíz
+// ("build/*"). Se ignoran mayúsculas y minúsculas.
+func MatchAny(patterns []string, name, rel string) bool {
+	name, rel = strings.ToLower(name), strings.ToLower(rel)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		if ok, _ := filepath.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseList separa una lista de globs o rutas escrita en la configuración (una por línea
+// o separadas por comas).
+func ParseList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == '\n' || r == ',' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
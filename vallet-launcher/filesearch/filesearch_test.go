@@ -0,0 +1,197 @@
+package filesearch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTree crea en un directorio temporal los archivos indicados (las rutas que terminan
+// en '/' son carpetas) y devuelve la raíz.
+func newTree(t *testing.T, paths ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, p := range paths {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if strings.HasSuffix(p, "/") {
+			if err := os.MkdirAll(full, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(p), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// walkPaths devuelve las rutas relativas (con '/') que visita Walk, ordenadas.
+func walkPaths(t *testing.T, opts Options) []string {
+	t.Helper()
+	var paths []string
+	err := Walk(context.Background(), opts, func(e Entry) error {
+		rel, err := filepath.Rel(e.Root, e.Path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if e.IsDir {
+			rel += "/"
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() = %v", err)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+var testTree = []string{
+	"notas.txt",
+	"Informe.PDF",
+	"docs/manual.pdf",
+	"docs/borrador.txt",
+	"docs/viejo/2019.pdf",
+	"node_modules/lib/index.js",
+	"build/app.exe",
+	"vacia/",
+}
+
+func TestWalk(t *testing.T) {
+	root := newTree(t, testTree...)
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "todo",
+			want: []string{"Informe.PDF", "build/", "build/app.exe", "docs/", "docs/borrador.txt", "docs/manual.pdf",
+				"docs/viejo/", "docs/viejo/2019.pdf", "node_modules/", "node_modules/lib/", "node_modules/lib/index.js",
+				"notas.txt", "vacia/"},
+		},
+		{
+			name: "incluir pdf",
+			opts: Options{Include: []string{"*.pdf"}, Exclude: []string{"node_modules", "build", "vacia"}},
+			want: []string{"Informe.PDF", "docs/", "docs/manual.pdf", "docs/viejo/", "docs/viejo/2019.pdf"},
+		},
+		{
+			name: "excluir por ruta",
+			opts: Options{Include: []string{"*.pdf", "*.txt"}, Exclude: []string{"docs/viejo", "node_modules", "build/*"}},
+			want: []string{"Informe.PDF", "build/", "docs/", "docs/borrador.txt", "docs/manual.pdf", "notas.txt", "vacia/"},
+		},
+		{
+			name: "profundidad 1",
+			opts: Options{MaxDepth: 1},
+			want: []string{"Informe.PDF", "build/", "docs/", "node_modules/", "notas.txt", "vacia/"},
+		},
+		{
+			name: "profundidad 2",
+			opts: Options{MaxDepth: 2, Exclude: []string{"node_modules", "build"}},
+			want: []string{"Informe.PDF", "docs/", "docs/borrador.txt", "docs/manual.pdf", "docs/viejo/", "notas.txt", "vacia/"},
+		},
+	}
+	for _, tt := range tests {
+		tt.opts.Roots = []string{root}
+		if got := walkPaths(t, tt.opts); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Walk() = %q, se esperaba %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWalkMaxFiles(t *testing.T) {
+	first := newTree(t, "a.txt", "b.txt", "c.txt")
+	second := newTree(t, "d.txt", "e.txt")
+	missing := filepath.Join(first, "no-existe")
+
+	opts := Options{Roots: []string{missing, first, second}}
+	if got := walkPaths(t, opts); len(got) != 5 {
+		t.Errorf("Walk() sin límite = %q, se esperaban 5 entradas", got)
+	}
+	for _, max := range []int{1, 3, 4} {
+		opts.MaxFiles = max
+		if got := walkPaths(t, opts); len(got) != max {
+			t.Errorf("Walk() con MaxFiles %d = %q", max, got)
+		}
+	}
+}
+
+func TestWalkStops(t *testing.T) {
+	root := newTree(t, testTree...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Walk(ctx, Options{Roots: []string{root}}, func(Entry) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Walk() con el contexto cancelado = %v, se esperaba context.Canceled", err)
+	}
+
+	stop := errors.New("basta")
+	calls := 0
+	err := Walk(context.Background(), Options{Roots: []string{root}}, func(Entry) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Walk() con error en fn = %v tras %d llamadas, se esperaba %v tras 1", err, calls, stop)
+	}
+}
+
+func TestWalkSkipsUnreadableDirs(t *testing.T) {
+	root := newTree(t, "publico/a.txt", "privado/secreto.txt", "z.txt")
+	private := filepath.Join(root, "privado")
+	if err := os.Chmod(private, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(private, 0o755) })
+	if _, err := os.ReadDir(private); err == nil {
+		t.Skip("el sistema permite leer carpetas sin permisos (¿root?)")
+	}
+
+	got := walkPaths(t, Options{Roots: []string{root}})
+	want := []string{"privado/", "publico/", "publico/a.txt", "z.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Walk() = %q, se esperaba %q", got, want)
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		patterns  []string
+		name, rel string
+		want      bool
+	}{
+		{[]string{"*.pdf"}, "Informe.PDF", "Informe.PDF", true},
+		{[]string{"*.PDF"}, "manual.pdf", "docs/manual.pdf", true},
+		{[]string{"*.txt", "*.pdf"}, "a.pdf", "a.pdf", true},
+		{[]string{"node_modules"}, "node_modules", "web/node_modules", true},
+		{[]string{"build/*"}, "app.exe", "build/app.exe", true},
+		{[]string{"build/*"}, "app.exe", "web/build/app.exe", false}, // Con '/' se compara la ruta desde la raíz.
+		{[]string{"docs"}, "manual.pdf", "docs/manual.pdf", false},   // Sin '/' solo el nombre.
+		{[]string{"*.pdf"}, "pdf", "pdf", false},
+		{[]string{"[invalido"}, "[invalido", "[invalido", false},
+		{nil, "a.txt", "a.txt", false},
+	}
+	for _, tt := range tests {
+		if got := MatchAny(tt.patterns, tt.name, tt.rel); got != tt.want {
+			t.Errorf("MatchAny(%q, %q, %q) = %v, se esperaba %v", tt.patterns, tt.name, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	got := ParseList(" *.pdf, *.docx\n\n node_modules ,\r\n~/Documentos ")
+	want := []string{"*.pdf", "*.docx", "node_modules", "~/Documentos"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseList() = %q, se esperaba %q", got, want)
+	}
+}
@@ -1,8 +1,8 @@
 import { useState, useEffect, useRef } from 'react';
 import './App.css';
 import valletLogo from './assets/images/vallet-os-V.png';
-import { OpenSomething, HideWindow, GetAllLinks, CreateLink, UpdateLink, DeleteLink, SearchLinks, SetAdminSize, SetLauncherSize, SetLauncherExpandedSize, SetRecordingSize, GetSettingBackend, UpdateSettingBackend, QuitApp, ProcessAudio, GetAllFolders, CreateFolder, UpdateFolder, DeleteFolder, GetUsageStats } from "../wailsjs/go/main/App";
-import { main } from "../wailsjs/go/models";
+import { OpenSomething, Search, ExecuteResult, RevealFile, ToggleClipboardPin, ClearClipboardHistory, ResolveInput, HideWindow, GetAllLinks, CreateLink, UpdateLink, DeleteLink, ResetLinkLearning, SetAdminSize, SetLauncherSize, SetLauncherExpandedSize, SetRecordingSize, GetSettingBackend, UpdateSettingBackend, QuitApp, ProcessAudio, GetAllFolders, CreateFolder, UpdateFolder, DeleteFolder, GetUsageStats, GetWhisperSettings, SaveWhisperSettings, GetWhisperLanguages, GetModels, DownloadModel, CancelModelDownload, DeleteModel, SetActiveModel } from "../wailsjs/go/main/App";
+import { main, ai } from "../wailsjs/go/models";
 import { EventsOn } from "../wailsjs/runtime/runtime";
 import { BarChart, Bar, XAxis, YAxis, CartesianGrid, Tooltip, ResponsiveContainer, LineChart, Line, AreaChart, Area } from 'recharts';
 
@@ -13,7 +13,8 @@ function App() {
     const [showAdmin, setShowAdmin] = useState(false); // Controla si se muestra el panel de admin.
     const [links, setLinks] = useState<main.Link[]>([]); // Lista completa de links (para admin).
     const [editingLink, setEditingLink] = useState<main.Link | null>(null); // Link que se está editando.
-    const [searchResults, setSearchResults] = useState<main.Link[]>([]); // Resultados de búsqueda filtrados.
+    const [searchResults, setSearchResults] = useState<main.Result[]>([]); // Resultados de todos los proveedores.
+    const [resolvedAction, setResolvedAction] = useState<main.Action | null>(null); // Lo que hará Enter si no hay resultados.
     const [selectedIndex, setSelectedIndex] = useState(0); // Índice de la sugerencia seleccionada.
     const [activeTab, setActiveTab] = useState<'dashboard' | 'links' | 'folders' | 'settings' | 'docs'>('links'); // Pestaña activa en el panel de admin.
     const [folders, setFolders] = useState<main.Folder[]>([]); // Lista de carpetas.
@@ -29,6 +30,24 @@ function App() {
     const [isRecording, setIsRecording] = useState(false); // Estado de grabación activa.
     // Estado para controlar la reproducción de sonidos durante la transcripción.
     const [playAudioTranscription, setPlayAudioTranscription] = useState(true);
+    // Restaurar el portapapeles después de pegar una transcripción o un snippet.
+    const [restoreClipboard, setRestoreClipboard] = useState(true);
+    // Estrategia de pegado por defecto y reglas por aplicación ("patrón = estrategia").
+    const [pasteStrategy, setPasteStrategy] = useState('ctrl_v');
+    const [pasteRules, setPasteRules] = useState('');
+    // Backend de transcripción (whisper-cli, whisper-managed, whisper-server u openai) y sus opciones.
+    const [transcription, setTranscription] = useState({ backend: 'whisper-cli', serverUrl: '', apiUrl: '', apiKey: '', apiModel: '' });
+    // Parámetros de whisper.cpp (modelo, idioma, hilos, decodificación) y los idiomas disponibles.
+    const [whisper, setWhisper] = useState<main.WhisperSettings | null>(null);
+    const [whisperLanguages, setWhisperLanguages] = useState<ai.Language[]>([]);
+    // Modelos ggml (instalados y descargables), avance de las descargas y origen de descarga.
+    const [models, setModels] = useState<main.ModelStatus[]>([]);
+    const [modelProgress, setModelProgress] = useState<Record<string, ai.DownloadProgress>>({});
+    const [modelSource, setModelSource] = useState({ url: '', manifest: '', allowUnverified: false });
+    // Configuración de la búsqueda de archivos (raíces, globs y profundidad).
+    const [fileSearch, setFileSearch] = useState({ enabled: true, roots: '', include: '', exclude: '', depth: '4' });
+    // Configuración del historial del portapapeles (tamaño y reglas de exclusión).
+    const [clipboardHistory, setClipboardHistory] = useState({ enabled: true, size: '200', skipSensitive: true, exclude: '' });
     const inputRef = useRef<HTMLInputElement>(null); // Referencia al input del buscador.
     const [selectedFolderFilter, setSelectedFolderFilter] = useState('Todas'); // Carpeta seleccionada para filtrar links en admin.
     const [usageStats, setUsageStats] = useState<main.UsageLog[]>([]); // Estadísticas de uso de herramientas.
@@ -105,7 +124,7 @@ function App() {
                 binary += String.fromCharCode(uint8[i]);
             }
             const base64 = btoa(binary);
-            ProcessAudio(base64); // Invocación a Go.
+            ProcessAudio(base64).catch(error => console.error(error)); // Invocación a Go.
         }
     };
 
@@ -141,6 +160,28 @@ function App() {
         return buffer;
     };
 
+    useEffect(() => {
+        // Avance y final de las descargas de modelos.
+        const unsubsProgress = EventsOn("model-download-progress", (progress: ai.DownloadProgress) => {
+            setModelProgress(prev => ({ ...prev, [progress.name]: progress }));
+        });
+        const unsubsFinished = EventsOn("model-download-finished", (result: { name: string; error?: string }) => {
+            setModelProgress(prev => {
+                const next = { ...prev };
+                delete next[result.name];
+                return next;
+            });
+            if (result.error) alert(`Modelo ${result.name}: ${result.error}`);
+            loadModels();
+            GetWhisperSettings().then(setWhisper);
+        });
+
+        return () => {
+            unsubsProgress();
+            unsubsFinished();
+        };
+    }, []);
+
     useEffect(() => {
         // Escuchar eventos globales enviados desde Go.
         const unsubsStart = EventsOn("start-recording", () => {
@@ -188,7 +229,8 @@ function App() {
         name: '',
         url: '',
         description: '',
-        category: 'General'
+        type: '', // Vacío = se deduce de la URL al guardar.
+        folder_id: 0 // 0 = carpeta predeterminada ('General').
     });
 
     useEffect(() => {
@@ -205,6 +247,59 @@ function App() {
             setPlayAudioTranscription(val !== "false");
         });
 
+        GetSettingBackend("paste_restore_clipboard").then(val => {
+            setRestoreClipboard(val !== "false");
+        });
+
+        GetSettingBackend("paste_strategy").then(val => {
+            if (val) setPasteStrategy(val);
+        });
+
+        GetSettingBackend("paste_rules").then(val => {
+            setPasteRules(val || '');
+        });
+
+        Promise.all([
+            GetSettingBackend("transcriber_backend"),
+            GetSettingBackend("whisper_server_url"),
+            GetSettingBackend("transcription_api_url"),
+            GetSettingBackend("transcription_api_key"),
+            GetSettingBackend("transcription_api_model"),
+        ]).then(([backend, serverUrl, apiUrl, apiKey, apiModel]) => {
+            setTranscription({ backend: backend || 'whisper-cli', serverUrl, apiUrl, apiKey, apiModel });
+        });
+
+        GetWhisperSettings().then(setWhisper);
+        GetWhisperLanguages().then(setWhisperLanguages);
+        loadModels();
+
+        Promise.all([
+            GetSettingBackend("model_download_url"),
+            GetSettingBackend("model_manifest_url"),
+            GetSettingBackend("model_allow_unverified"),
+        ]).then(([url, manifest, allowUnverified]) => {
+            setModelSource({ url, manifest, allowUnverified: allowUnverified === "true" });
+        });
+
+        Promise.all([
+            GetSettingBackend("file_search_enabled"),
+            GetSettingBackend("file_search_roots"),
+            GetSettingBackend("file_search_include"),
+            GetSettingBackend("file_search_exclude"),
+            GetSettingBackend("file_search_max_depth"),
+        ]).then(([enabled, roots, include, exclude, depth]) => {
+            setFileSearch({ enabled: enabled !== "false", roots, include, exclude, depth: depth || '4' });
+        });
+
+        Promise.all([
+            GetSettingBackend("clipboard_history_enabled"),
+            GetSettingBackend("clipboard_history_size"),
+            GetSettingBackend("clipboard_history_skip_sensitive"),
+            GetSettingBackend("clipboard_history_exclude"),
+        ]).then(([enabled, size, skipSensitive, exclude]) => {
+            setClipboardHistory({ enabled: enabled !== "false", size: size || '200', skipSensitive: skipSensitive !== "false", exclude });
+        });
+
         loadUsageStats();
 
         // Asegurar que el input tenga el foco al cargar la ventana.
@@ -290,9 +385,15 @@ function App() {
     useEffect(() => {
         // Lógica de búsqueda reactiva.
         if (query.length > 0) {
-            SearchLinks(query).then(results => {
+            Search(query).then(results => {
                 setSearchResults(results || []);
                 setSelectedIndex(0); // Reiniciar selección al buscar.
+                // Sin resultados, mostrar qué hará Enter con el texto escrito.
+                if (!results || results.length === 0) {
+                    ResolveInput(query).then(setResolvedAction).catch(() => setResolvedAction(null));
+                } else {
+                    setResolvedAction(null);
+                }
                 // Expandir la ventana si hay resultados.
                 if (results && results.length > 0) {
                     SetLauncherExpandedSize();
@@ -307,6 +408,7 @@ function App() {
         } else {
             setSearchResults([]);
             setSelectedIndex(0);
+            setResolvedAction(null);
             if (!showAdmin) SetLauncherSize();
         }
     }, [query, showAdmin]);
@@ -351,12 +453,25 @@ function App() {
         }
     };
 
-    const handleLinkClick = (link: main.Link) => {
-        OpenSomething(link.url);
+    const handleLinkClick = (result: main.Result) => {
+        ExecuteResult(result);
+        setQuery('');
+        setSearchResults([]);
+    };
+
+    // Muestra un archivo del índice en el gestor de archivos (Shift+Enter o botón 📂).
+    const handleRevealFile = (result: main.Result) => {
+        RevealFile(result.id);
         setQuery('');
         setSearchResults([]);
     };
 
+    // Fija o libera un texto del historial del portapapeles y refresca los resultados.
+    const handleToggleClipboardPin = async (result: main.Result) => {
+        await ToggleClipboardPin(result.id);
+        setSearchResults(await Search(query) || []);
+    };
+
     const handleSubmitLink = async (e: React.FormEvent) => {
         e.preventDefault();
         try {
@@ -369,7 +484,9 @@ function App() {
                 await CreateLink({
                     id: 0,
                     ...formData,
-                    created_at: ''
+                    category: '',
+                    created_at: '',
+                    source: 'link'
                 });
             }
             resetForm();
@@ -385,10 +502,21 @@ function App() {
             name: link.name,
             url: link.url,
             description: link.description || '',
-            category: link.category || ''
+            type: link.type,
+            folder_id: link.folder_id
         });
     };
 
+    const handleResetLearning = async (id: number) => {
+        if (confirm('¿Olvidar las búsquedas aprendidas para este link?')) {
+            try {
+                await ResetLinkLearning(id);
+            } catch (error) {
+                console.error('Error resetting link learning:', error);
+            }
+        }
+    };
+
     const handleDelete = async (id: number) => {
         if (confirm('¿Estás seguro de eliminar este link?')) {
             try {
@@ -405,7 +533,8 @@ function App() {
             name: '',
             url: '',
             description: '',
-            category: 'General'
+            type: '',
+            folder_id: 0
         });
         setEditingLink(null);
     };
@@ -474,6 +603,99 @@ function App() {
         await UpdateSettingBackend("play_audio_transcription", checked ? "true" : "false");
     };
 
+    /**
+     * Alterna si se restaura el portapapeles después de pegar texto.
+     */
+    const toggleRestoreClipboard = async (checked: boolean) => {
+        setRestoreClipboard(checked);
+        await UpdateSettingBackend("paste_restore_clipboard", checked ? "true" : "false");
+    };
+
+    const handleTranscriberChange = async (backend: string) => {
+        setTranscription(prev => ({ ...prev, backend }));
+        await UpdateSettingBackend("transcriber_backend", backend);
+    };
+
+    // Guarda los parámetros de whisper; si el backend los rechaza se muestran los guardados.
+    const saveWhisper = async (settings: main.WhisperSettings) => {
+        setWhisper(settings);
+        try {
+            await SaveWhisperSettings(settings);
+        } catch (error) {
+            alert(error);
+            setWhisper(await GetWhisperSettings());
+        }
+    };
+
+    const loadModels = () => {
+        GetModels().then(list => setModels(list || [])).catch(error => console.error(error));
+    };
+
+    // Ejecuta una operación sobre un modelo y refresca la lista (y el modelo activo).
+    const runModelAction = async (action: () => Promise<void>) => {
+        try {
+            await action();
+        } catch (error) {
+            alert(error);
+        }
+        loadModels();
+        GetWhisperSettings().then(setWhisper);
+    };
+
+    const handleDeleteModel = (model: main.ModelStatus) => {
+        if (!confirm(`¿Borrar el modelo ${model.name}?`)) return;
+        runModelAction(() => DeleteModel(model.name));
+    };
+
+    // Texto del avance de una descarga ("45% · 210 de 466 MB").
+    const describeProgress = (progress?: ai.DownloadProgress) => {
+        if (!progress) return 'Descargando…';
+        if (progress.verifying) return 'Verificando…';
+        const mb = (bytes: number) => Math.round(bytes / (1024 * 1024));
+        if (!progress.total) return `${mb(progress.downloaded)} MB`;
+        return `${Math.floor(progress.downloaded * 100 / progress.total)}% · ${mb(progress.downloaded)} de ${mb(progress.total)} MB`;
+    };
+
+    const handlePasteStrategyChange = async (strategy: string) => {
+        setPasteStrategy(strategy);
+        try {
+            await UpdateSettingBackend("paste_strategy", strategy);
+        } catch (error) {
+            alert(error);
+            setPasteStrategy(await GetSettingBackend("paste_strategy") || "ctrl_v");
+        }
+    };
+
+    // Guarda las reglas de pegado; si el backend las rechaza se avisa y se conserva el texto
+    // para corregirlo.
+    const savePasteRules = async (rules: string) => {
+        try {
+            await UpdateSettingBackend("paste_rules", rules);
+        } catch (error) {
+            alert(error);
+        }
+    };
+
+    // Guarda un ajuste de la búsqueda de archivos; el backend reindexa al recibirlo.
+    const saveFileSearchSetting = async (key: string, value: string) => {
+        await UpdateSettingBackend(key, value);
+    };
+
+    const toggleFileSearch = async (checked: boolean) => {
+        setFileSearch(prev => ({ ...prev, enabled: checked }));
+        await saveFileSearchSetting("file_search_enabled", checked ? "true" : "false");
+    };
+
+    const saveClipboardSetting = async (key: string, value: string) => {
+        await UpdateSettingBackend(key, value);
+    };
+
+    const handleClearClipboardHistory = async () => {
+        if (confirm("¿Borrar el historial del portapapeles? Los textos fijados se conservan.")) {
+            await ClearClipboardHistory();
+        }
+    };
+
     const handleBrowserChange = async (browser: string) => {
         setDefaultBrowser(browser);
         setIsSaving(true);
@@ -772,23 +994,50 @@ function App() {
                                                     />
                                                 </div>
                                                 <div className="form-group flex-3">
-                                                    <input
-                                                        type="text"
-                                                        value={formData.url}
-                                                        onChange={(e) => setFormData({ ...formData, url: e.target.value })}
-                                                        required
-                                                        placeholder="URL o Comando (https://...)"
-                                                    />
+                                                    {formData.type === 'snippet' ? (
+                                                        <textarea
+                                                            value={formData.url}
+                                                            onChange={(e) => setFormData({ ...formData, url: e.target.value })}
+                                                            required
+                                                            rows={4}
+                                                            className="snippet-textarea"
+                                                            placeholder="Texto a pegar. Variables: {date} {time} {clipboard} {cursor}"
+                                                        />
+                                                    ) : (
+                                                        <input
+                                                            type="text"
+                                                            value={formData.url}
+                                                            onChange={(e) => setFormData({ ...formData, url: e.target.value })}
+                                                            required
+                                                            placeholder="URL o Comando (https://...)"
+                                                        />
+                                                    )}
                                                 </div>
                                                 <div className="form-group flex-2">
                                                     <select
-                                                        value={formData.category}
-                                                        onChange={(e) => setFormData({ ...formData, category: e.target.value })}
+                                                        value={formData.type}
+                                                        onChange={(e) => setFormData({ ...formData, type: e.target.value })}
+                                                        className="browser-select"
+                                                        style={{ height: '42px', width: '100%', minWidth: 'unset' }}
+                                                    >
+                                                        <option value="">Automático</option>
+                                                        <option value="url">URL</option>
+                                                        <option value="file">Archivo</option>
+                                                        <option value="folder">Carpeta</option>
+                                                        <option value="application">Aplicación</option>
+                                                        <option value="command">Comando</option>
+                                                        <option value="snippet">Texto</option>
+                                                    </select>
+                                                </div>
+                                                <div className="form-group flex-2">
+                                                    <select
+                                                        value={formData.folder_id || folders.find(f => f.name === 'General')?.id || 0}
+                                                        onChange={(e) => setFormData({ ...formData, folder_id: Number(e.target.value) })}
                                                         className="browser-select"
                                                         style={{ height: '42px', width: '100%', minWidth: 'unset' }}
                                                     >
                                                         {folders.map(f => (
-                                                            <option key={f.id} value={f.name}>{f.name}</option>
+                                                            <option key={f.id} value={f.id}>{f.path}</option>
                                                         ))}
                                                     </select>
                                                 </div>
@@ -851,6 +1100,9 @@ function App() {
                                                                     <button className="btn-table-action edit" onClick={() => handleEdit(link)} title="Editar">
                                                                         <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2.5"><path d="M11 4H4a2 2 0 0 0-2 2v14a2 2 0 0 0 2 2h14a2 2 0 0 0 2-2v-7" /><path d="M18.5 2.5a2.121 2.121 0 0 1 3 3L12 15l-4 1 1-4 9.5-9.5z" /></svg>
                                                                     </button>
+                                                                    <button className="btn-table-action edit" onClick={() => handleResetLearning(link.id)} title="Olvidar búsquedas aprendidas">
+                                                                        <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2.5"><polyline points="1 4 1 10 7 10" /><path d="M3.51 15a9 9 0 1 0 2.13-9.36L1 10" /></svg>
+                                                                    </button>
                                                                     <button className="btn-table-action delete" onClick={() => handleDelete(link.id)} title="Eliminar">
                                                                         <svg viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2.5"><polyline points="3 6 5 6 21 6" /><path d="M19 6v14a2 2 0 0 1-2 2H7a2 2 0 0 1-2-2V6m3 0V4a2 2 0 0 1 2-2h4a2 2 0 0 1 2 2v2" /><line x1="10" y1="11" x2="10" y2="17" /><line x1="14" y1="11" x2="14" y2="17" /></svg>
                                                                     </button>
@@ -994,6 +1246,303 @@ function App() {
                                             </label>
                                         </div>
 
+                                        <div className="settings-item settings-item-column">
+                                            <div className="settings-row">
+                                                <div className="settings-info">
+                                                    <span>Motor de transcripción</span>
+                                                    <p>Dónde se convierte la voz en texto.</p>
+                                                </div>
+                                                <select
+                                                    className="browser-select"
+                                                    value={transcription.backend}
+                                                    onChange={(e) => handleTranscriberChange(e.target.value)}
+                                                >
+                                                    <option value="whisper-cli">whisper.cpp (local)</option>
+                                                    <option value="whisper-managed">whisper.cpp (servidor persistente)</option>
+                                                    <option value="whisper-server">Servidor whisper.cpp</option>
+                                                    <option value="openai">API compatible con OpenAI</option>
+                                                </select>
+                                            </div>
+                                            {transcription.backend === 'whisper-server' && (
+                                                <div className="settings-fields">
+                                                    <label>
+                                                        URL del servidor
+                                                        <input
+                                                            className="settings-input"
+                                                            value={transcription.serverUrl}
+                                                            placeholder="http://127.0.0.1:8080"
+                                                            onChange={(e) => setTranscription({ ...transcription, serverUrl: e.target.value })}
+                                                            onBlur={(e) => UpdateSettingBackend("whisper_server_url", e.target.value)}
+                                                        />
+                                                    </label>
+                                                </div>
+                                            )}
+                                            {transcription.backend === 'openai' && (
+                                                <div className="settings-fields">
+                                                    <label>
+                                                        URL de la API
+                                                        <input
+                                                            className="settings-input"
+                                                            value={transcription.apiUrl}
+                                                            placeholder="https://api.openai.com/v1"
+                                                            onChange={(e) => setTranscription({ ...transcription, apiUrl: e.target.value })}
+                                                            onBlur={(e) => UpdateSettingBackend("transcription_api_url", e.target.value)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Modelo
+                                                        <input
+                                                            className="settings-input"
+                                                            value={transcription.apiModel}
+                                                            placeholder="whisper-1"
+                                                            onChange={(e) => setTranscription({ ...transcription, apiModel: e.target.value })}
+                                                            onBlur={(e) => UpdateSettingBackend("transcription_api_model", e.target.value)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Clave de la API
+                                                        <input
+                                                            className="settings-input"
+                                                            type="password"
+                                                            value={transcription.apiKey}
+                                                            placeholder="sk-..."
+                                                            onChange={(e) => setTranscription({ ...transcription, apiKey: e.target.value })}
+                                                            onBlur={(e) => UpdateSettingBackend("transcription_api_key", e.target.value)}
+                                                        />
+                                                    </label>
+                                                </div>
+                                            )}
+                                        </div>
+
+                                        {whisper && transcription.backend !== 'openai' && (
+                                            <div className="settings-item settings-item-column">
+                                                <div className="settings-info">
+                                                    <span>Parámetros de Whisper</span>
+                                                    <p>Modelo, idioma y decodificación. Más haces o candidatos mejoran la precisión a costa de velocidad.</p>
+                                                </div>
+                                                <div className="settings-fields">
+                                                    <label>
+                                                        Modelo
+                                                        <input
+                                                            className="settings-input"
+                                                            value={whisper.modelPath}
+                                                            placeholder="whisper/ggml-small.bin"
+                                                            onChange={(e) => setWhisper({ ...whisper, modelPath: e.target.value })}
+                                                            onBlur={() => saveWhisper(whisper)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Idioma
+                                                        <select
+                                                            className="browser-select"
+                                                            value={whisper.language}
+                                                            onChange={(e) => saveWhisper({ ...whisper, language: e.target.value })}
+                                                        >
+                                                            {whisperLanguages.map(lang => (
+                                                                <option key={lang.code} value={lang.code}>{lang.name}</option>
+                                                            ))}
+                                                        </select>
+                                                    </label>
+                                                    <label>
+                                                        Hilos (0 = todos los núcleos)
+                                                        <input
+                                                            className="settings-input"
+                                                            type="number"
+                                                            min={0}
+                                                            value={whisper.threads}
+                                                            onChange={(e) => setWhisper({ ...whisper, threads: Number(e.target.value) })}
+                                                            onBlur={() => saveWhisper(whisper)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Tamaño del haz (beam size)
+                                                        <input
+                                                            className="settings-input"
+                                                            type="number"
+                                                            min={1}
+                                                            max={8}
+                                                            value={whisper.beamSize}
+                                                            onChange={(e) => setWhisper({ ...whisper, beamSize: Number(e.target.value) })}
+                                                            onBlur={() => saveWhisper(whisper)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Candidatos (best of)
+                                                        <input
+                                                            className="settings-input"
+                                                            type="number"
+                                                            min={1}
+                                                            max={8}
+                                                            value={whisper.bestOf}
+                                                            onChange={(e) => setWhisper({ ...whisper, bestOf: Number(e.target.value) })}
+                                                            onBlur={() => saveWhisper(whisper)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Temperatura
+                                                        <input
+                                                            className="settings-input"
+                                                            type="number"
+                                                            min={0}
+                                                            max={1}
+                                                            step={0.1}
+                                                            value={whisper.temperature}
+                                                            onChange={(e) => setWhisper({ ...whisper, temperature: Number(e.target.value) })}
+                                                            onBlur={() => saveWhisper(whisper)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Prompt inicial
+                                                        <textarea
+                                                            className="settings-input"
+                                                            rows={2}
+                                                            value={whisper.initialPrompt}
+                                                            placeholder="Nombres propios o estilo de puntuación, ej: Vallet, Wails, SQLite."
+                                                            onChange={(e) => setWhisper({ ...whisper, initialPrompt: e.target.value })}
+                                                            onBlur={() => saveWhisper(whisper)}
+                                                        />
+                                                    </label>
+                                                    <label className="settings-checkbox">
+                                                        <input
+                                                            type="checkbox"
+                                                            checked={whisper.translate}
+                                                            onChange={(e) => saveWhisper({ ...whisper, translate: e.target.checked })}
+                                                        />
+                                                        Traducir al inglés
+                                                    </label>
+                                                </div>
+                                            </div>
+                                        )}
+
+                                        {(transcription.backend === 'whisper-cli' || transcription.backend === 'whisper-managed') && (
+                                            <div className="settings-item settings-item-column">
+                                                <div className="settings-info">
+                                                    <span>Modelos de Whisper</span>
+                                                    <p>Se guardan en ~/.vallet-os/models. Los modelos grandes son más precisos pero más lentos.</p>
+                                                </div>
+                                                <div className="model-list">
+                                                    {models.map(model => (
+                                                        <div key={model.file} className={`model-row ${model.active ? 'active' : ''}`}>
+                                                            <div className="model-info">
+                                                                <span>
+                                                                    {model.name}
+                                                                    {model.active && <em className="model-badge">En uso</em>}
+                                                                    {model.verified && <em className="model-badge" title="La descarga se verifica con SHA256">SHA256</em>}
+                                                                </span>
+                                                                <p>
+                                                                    {model.downloading
+                                                                        ? describeProgress(modelProgress[model.name])
+                                                                        : `${model.installed ? Math.round(model.size / (1024 * 1024)) : model.sizeMB} MB${model.description ? ` · ${model.description}` : ''}`}
+                                                                </p>
+                                                            </div>
+                                                            <div className="model-actions">
+                                                                {model.downloading && (
+                                                                    <button type="button" className="settings-button" onClick={() => CancelModelDownload(model.name)}>
+                                                                        Cancelar
+                                                                    </button>
+                                                                )}
+                                                                {!model.downloading && !model.installed && (
+                                                                    <button type="button" className="settings-button" onClick={() => runModelAction(() => DownloadModel(model.name))}>
+                                                                        Descargar
+                                                                    </button>
+                                                                )}
+                                                                {!model.downloading && model.installed && !model.active && (
+                                                                    <button type="button" className="settings-button" onClick={() => runModelAction(() => SetActiveModel(model.name))}>
+                                                                        Usar
+                                                                    </button>
+                                                                )}
+                                                                {!model.downloading && model.installed && (
+                                                                    <button type="button" className="settings-button" onClick={() => handleDeleteModel(model)}>
+                                                                        Borrar
+                                                                    </button>
+                                                                )}
+                                                            </div>
+                                                        </div>
+                                                    ))}
+                                                </div>
+                                                <div className="settings-fields">
+                                                    <label>
+                                                        Origen de descarga
+                                                        <input
+                                                            className="settings-input"
+                                                            value={modelSource.url}
+                                                            placeholder="https://huggingface.co/ggerganov/whisper.cpp/resolve/main"
+                                                            onChange={(e) => setModelSource({ ...modelSource, url: e.target.value })}
+                                                            onBlur={(e) => UpdateSettingBackend("model_download_url", e.target.value)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Manifiesto con SHA256 (opcional)
+                                                        <input
+                                                            className="settings-input"
+                                                            value={modelSource.manifest}
+                                                            placeholder="https://espejo.interno/whisper/manifest.json"
+                                                            onChange={(e) => setModelSource({ ...modelSource, manifest: e.target.value })}
+                                                            onBlur={(e) => UpdateSettingBackend("model_manifest_url", e.target.value).then(loadModels)}
+                                                        />
+                                                    </label>
+                                                    <label className="settings-checkbox">
+                                                        <input
+                                                            type="checkbox"
+                                                            checked={modelSource.allowUnverified}
+                                                            onChange={(e) => {
+                                                                setModelSource({ ...modelSource, allowUnverified: e.target.checked });
+                                                                UpdateSettingBackend("model_allow_unverified", e.target.checked ? "true" : "false");
+                                                            }}
+                                                        />
+                                                        Permitir modelos sin SHA256 verificado
+                                                    </label>
+                                                </div>
+                                            </div>
+                                        )}
+
+                                        <div className="settings-item">
+                                            <div className="settings-info">
+                                                <span>Conservar el portapapeles</span>
+                                                <p>Al pegar una transcripción o un snippet, recuperar después lo que habías copiado.</p>
+                                            </div>
+                                            <label className="switch">
+                                                <input
+                                                    type="checkbox"
+                                                    checked={restoreClipboard}
+                                                    onChange={(e) => toggleRestoreClipboard(e.target.checked)}
+                                                />
+                                                <span className="slider"></span>
+                                            </label>
+                                        </div>
+
+                                        <div className="settings-item settings-item-column">
+                                            <div className="settings-row">
+                                                <div className="settings-info">
+                                                    <span>Forma de pegar</span>
+                                                    <p>Cómo se introduce el texto dictado o de los snippets en la aplicación activa.</p>
+                                                </div>
+                                                <select
+                                                    className="browser-select"
+                                                    value={pasteStrategy}
+                                                    onChange={(e) => handlePasteStrategyChange(e.target.value)}
+                                                >
+                                                    <option value="ctrl_v">Ctrl+V</option>
+                                                    <option value="ctrl_shift_v">Ctrl+Shift+V</option>
+                                                    <option value="shift_insert">Shift+Insert</option>
+                                                    <option value="type">Escribir carácter a carácter</option>
+                                                </select>
+                                            </div>
+                                            <div className="settings-fields">
+                                                <label>
+                                                    Reglas por aplicación (clase de ventana o proceso = ctrl_v, ctrl_shift_v, shift_insert o type)
+                                                    <textarea
+                                                        className="settings-input"
+                                                        rows={4}
+                                                        value={pasteRules}
+                                                        placeholder={"putty = shift_insert\n*terminal* = ctrl_shift_v"}
+                                                        onChange={(e) => setPasteRules(e.target.value)}
+                                                        onBlur={(e) => savePasteRules(e.target.value)}
+                                                    />
+                                                </label>
+                                            </div>
+                                        </div>
+
                                         <div className="settings-item">
                                             <div className="settings-info">
                                                 <span>Navegador por defecto</span>
@@ -1010,8 +1559,131 @@ function App() {
                                                 <option value="edge">Microsoft Edge</option>
                                                 <option value="brave">Brave Browser</option>
                                                 <option value="opera">Opera</option>
+                                                <option value="chromium">Chromium</option>
+                                                <option value="vivaldi">Vivaldi</option>
                                             </select>
                                         </div>
+                                        <div className="settings-item settings-item-column">
+                                            <div className="settings-row">
+                                                <div className="settings-info">
+                                                    <span>Búsqueda de archivos</span>
+                                                    <p>Indexa carpetas y documentos para abrirlos desde el buscador.</p>
+                                                </div>
+                                                <label className="switch">
+                                                    <input
+                                                        type="checkbox"
+                                                        checked={fileSearch.enabled}
+                                                        onChange={(e) => toggleFileSearch(e.target.checked)}
+                                                    />
+                                                    <span className="slider"></span>
+                                                </label>
+                                            </div>
+                                            {fileSearch.enabled && (
+                                                <div className="settings-fields">
+                                                    <label>
+                                                        Carpetas raíz (separadas por comas)
+                                                        <input
+                                                            className="settings-input"
+                                                            value={fileSearch.roots}
+                                                            placeholder="~/Documents, ~/Projects"
+                                                            onChange={(e) => setFileSearch({ ...fileSearch, roots: e.target.value })}
+                                                            onBlur={(e) => saveFileSearchSetting("file_search_roots", e.target.value)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Incluir solo (globs, vacío = todo)
+                                                        <input
+                                                            className="settings-input"
+                                                            value={fileSearch.include}
+                                                            placeholder="*.pdf, *.docx"
+                                                            onChange={(e) => setFileSearch({ ...fileSearch, include: e.target.value })}
+                                                            onBlur={(e) => saveFileSearchSetting("file_search_include", e.target.value)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Excluir (globs)
+                                                        <input
+                                                            className="settings-input"
+                                                            value={fileSearch.exclude}
+                                                            placeholder=".*, node_modules"
+                                                            onChange={(e) => setFileSearch({ ...fileSearch, exclude: e.target.value })}
+                                                            onBlur={(e) => saveFileSearchSetting("file_search_exclude", e.target.value)}
+                                                        />
+                                                    </label>
+                                                    <label>
+                                                        Profundidad máxima
+                                                        <input
+                                                            className="settings-input"
+                                                            type="number"
+                                                            min={0}
+                                                            value={fileSearch.depth}
+                                                            onChange={(e) => setFileSearch({ ...fileSearch, depth: e.target.value })}
+                                                            onBlur={(e) => saveFileSearchSetting("file_search_max_depth", e.target.value)}
+                                                        />
+                                                    </label>
+                                                </div>
+                                            )}
+                                        </div>
+                                        <div className="settings-item settings-item-column">
+                                            <div className="settings-row">
+                                                <div className="settings-info">
+                                                    <span>Historial del portapapeles</span>
+                                                    <p>Recuerda los textos copiados. Escribe "cb" en el buscador para volver a pegarlos.</p>
+                                                </div>
+                                                <label className="switch">
+                                                    <input
+                                                        type="checkbox"
+                                                        checked={clipboardHistory.enabled}
+                                                        onChange={(e) => {
+                                                            setClipboardHistory({ ...clipboardHistory, enabled: e.target.checked });
+                                                            saveClipboardSetting("clipboard_history_enabled", e.target.checked ? "true" : "false");
+                                                        }}
+                                                    />
+                                                    <span className="slider"></span>
+                                                </label>
+                                            </div>
+                                            {clipboardHistory.enabled && (
+                                                <div className="settings-fields">
+                                                    <label>
+                                                        Textos a recordar (sin contar los fijados)
+                                                        <input
+                                                            className="settings-input"
+                                                            type="number"
+                                                            min={1}
+                                                            value={clipboardHistory.size}
+                                                            onChange={(e) => setClipboardHistory({ ...clipboardHistory, size: e.target.value })}
+                                                            onBlur={(e) => saveClipboardSetting("clipboard_history_size", e.target.value)}
+                                                        />
+                                                    </label>
+                                                    <label className="settings-checkbox">
+                                                        <input
+                                                            type="checkbox"
+                                                            checked={clipboardHistory.skipSensitive}
+                                                            onChange={(e) => {
+                                                                setClipboardHistory({ ...clipboardHistory, skipSensitive: e.target.checked });
+                                                                saveClipboardSetting("clipboard_history_skip_sensitive", e.target.checked ? "true" : "false");
+                                                            }}
+                                                        />
+                                                        Omitir lo que parezca una contraseña o un token
+                                                    </label>
+                                                    <label>
+                                                        Excluir (expresiones regulares, una por línea)
+                                                        <textarea
+                                                            className="settings-input"
+                                                            rows={3}
+                                                            value={clipboardHistory.exclude}
+                                                            placeholder={"^\\d{6}$\ncontraseña"}
+                                                            onChange={(e) => setClipboardHistory({ ...clipboardHistory, exclude: e.target.value })}
+                                                            onBlur={(e) => saveClipboardSetting("clipboard_history_exclude", e.target.value)}
+                                                        />
+                                                    </label>
+                                                    <button type="button" className="settings-button" onClick={handleClearClipboardHistory}>
+                                                        Borrar historial
+                                                    </button>
+                                                </div>
+                                            )}
+                                        </div>
+
                                         {isSaving && (
                                             <div className="progress-container">
                                                 <div
@@ -1207,14 +1879,24 @@ function App() {
                                 placeholder="Buscar aplicación o sitio web..."
                                 value={query}
                                 onChange={(e) => setQuery(e.target.value)}
+                                onKeyDown={(e) => {
+                                    const selected = searchResults[selectedIndex];
+                                    if (e.key === 'Enter' && e.shiftKey && selected?.provider === 'file') {
+                                        e.preventDefault();
+                                        handleRevealFile(selected);
+                                    }
+                                }}
                                 autoFocus
                             />
                         </div>
                     </form>
 
                     <div className="hint">
-                        Presiona <span className="key">Enter</span> para abrir ·
+                        {resolvedAction && searchResults.length === 0
+                            ? <><span className="key">Enter</span> {resolvedAction.label} · </>
+                            : <>Presiona <span className="key">Enter</span> para abrir · </>}
                         <span className="key">Esc</span> para ocultar ·
+                        <span className="key">cb</span> historial ·
                         <span className="key">Ctrl+Shift+C</span> para Mis Links
                     </div>
                 </div>
@@ -1222,14 +1904,32 @@ function App() {
                 {searchResults.length > 0 && (
                     <div className="results-box">
                         <div className="search-results" ref={resultsRef}>
-                            {searchResults.map((link, index) => (
+                            {searchResults.map((result, index) => (
                                 <div
-                                    key={link.id}
+                                    key={`${result.provider}-${result.id}`}
                                     className={`result-item ${index === selectedIndex ? 'selected' : ''}`}
-                                    onClick={() => handleLinkClick(link)}
+                                    onClick={() => handleLinkClick(result)}
                                 >
-                                    <div className="result-name">{link.name}</div>
-                                    <div className="result-url">{link.url}</div>
+                                    <div className="result-name">{result.title}</div>
+                                    <div className="result-url">{result.subtitle}</div>
+                                    {result.provider === 'file' && (
+                                        <button
+                                            className="result-reveal"
+                                            title="Mostrar en el gestor de archivos (Shift+Enter)"
+                                            onClick={(e) => { e.stopPropagation(); handleRevealFile(result); }}
+                                        >
+                                            📂
+                                        </button>
+                                    )}
+                                    {result.provider === 'clipboard' && (
+                                        <button
+                                            className="result-reveal"
+                                            title="Fijar o liberar (los textos fijados no se borran)"
+                                            onClick={(e) => { e.stopPropagation(); handleToggleClipboardPin(result); }}
+                                        >
+                                            📌
+                                        </button>
+                                    )}
                                 </div>
                             ))}
                         </div>
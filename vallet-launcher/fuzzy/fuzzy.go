@@ -0,0 +1,245 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Rangos de puntuación. Cada tipo de coincidencia ocupa su propia franja para que, por
+// ejemplo, un prefijo siempre gane a una subsecuencia dispersa.
+const (
+	ScoreExact       = 1000 // El texto coincide completo (sin distinguir mayúsculas ni acentos).
+	scorePrefix      = 900  // El texto empieza por la consulta.
+	scoreWordPrefix  = 700  // Alguna palabra del texto empieza por la consulta.
+	scoreSubsequence = 600  // Máximo para coincidencias por subsecuencia o acrónimo.
+	scoreTypo        = 90   // Máximo para coincidencias con errores de tipeo.
+
+	// ScoreWordMatch es la puntuación mínima de una coincidencia al inicio del texto o de
+	// alguna de sus palabras; por debajo solo quedan subsecuencias dispersas y errores de tipeo.
+	ScoreWordMatch = scoreSubsequence + 1
+)
+
+// Pesos del algoritmo de subsecuencia.
+const (
+	charMatch        = 16 // Cada carácter encontrado.
+	boundaryBonus    = 24 // El carácter inicia una palabra ("gcal" -> "Google Calendar").
+	camelBonus       = 16 // El carácter es una mayúscula tras una minúscula ("GitHub").
+	consecutiveBonus = 12 // El carácter sigue inmediatamente al anterior.
+	gapPenalty       = 2  // Por cada carácter saltado entre dos coincidencias.
+	leadingPenalty   = 1  // Por cada carácter antes de la primera coincidencia (máx. maxLeading).
+	maxLeading       = 15
+)
+
+// Score indica qué tan bien coincide query con target. Devuelve 0 si no hay coincidencia
+// y un valor mayor cuanto mejor sea: exacta > prefijo > prefijo de palabra > subsecuencia
+// (con bonus por inicio de palabra y caracteres consecutivos) > distancia de edición.
+func Score(query, target string) int {
+	q := fold(strings.TrimSpace(query))
+	if len(q) == 0 || target == "" {
+		return 0
+	}
+	orig := []rune(target)
+	t := fold(target)
+
+	qs, ts := string(q), string(t)
+	switch {
+	case ts == qs:
+		return ScoreExact
+	case strings.HasPrefix(ts, qs):
+		return max(scorePrefix-(len(t)-len(q)), scoreWordPrefix+1)
+	}
+
+	for i := 1; i < len(t); i++ {
+		if isBoundary(t, i) && hasRunePrefix(t[i:], q) {
+			return max(scoreWordPrefix-i, scoreSubsequence+1)
+		}
+	}
+
+	if s := subsequenceScore(q, t, orig); s > 0 {
+		return s
+	}
+
+	return typoScore(q, t)
+}
+
+// subsequenceScore busca la mejor alineación de q como subsecuencia de t mediante
+// programación dinámica en O(len(q)*len(t)). Devuelve 0 si q no es subsecuencia.
+func subsequenceScore(q, t, orig []rune) int {
+	const none = -1 << 30
+	m, n := len(q), len(t)
+	if m > n {
+		return 0
+	}
+
+	// prev[j] es la mejor puntuación con q[i-1] emparejado en t[j].
+	prev := make([]int, n)
+	curr := make([]int, n)
+	for j := 0; j < n; j++ {
+		prev[j] = none
+		if t[j] == q[0] {
+			prev[j] = charScore(orig, t, j) - min(j*leadingPenalty, maxLeading)
+		}
+	}
+
+	for i := 1; i < m; i++ {
+		// bestGap guarda max(prev[k] + gapPenalty*k) para k < j-1, lo que permite
+		// calcular la penalización lineal por hueco sin recorrer todos los k.
+		bestGap := none
+		for j := 0; j < n; j++ {
+			curr[j] = none
+			if j >= 2 && prev[j-2] != none {
+				bestGap = max(bestGap, prev[j-2]+gapPenalty*(j-2))
+			}
+			if t[j] != q[i] {
+				continue
+			}
+			best := none
+			if j >= 1 && prev[j-1] != none {
+				best = prev[j-1] + consecutiveBonus
+			}
+			if bestGap != none {
+				best = max(best, bestGap-gapPenalty*(j-1))
+			}
+			if best != none {
+				curr[j] = best + charScore(orig, t, j)
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	raw := none
+	for _, v := range prev {
+		raw = max(raw, v)
+	}
+	if raw == none {
+		return 0
+	}
+
+	// Normalizar a la franja (0, scoreSubsequence] según el máximo teórico para m caracteres.
+	ideal := m * (charMatch + boundaryBonus + consecutiveBonus)
+	score := scoreTypo + 10 + (scoreSubsequence-scoreTypo-10)*raw/ideal
+	return min(max(score, scoreTypo+10), scoreSubsequence)
+}
+
+// typoScore tolera errores de tipeo comparando la consulta con cada palabra del texto y
+// con su prefijo de la misma longitud. Se admite 1 error hasta 5 caracteres y 2 a partir de 6.
+func typoScore(q, t []rune) int {
+	if len(q) < 3 {
+		return 0
+	}
+	allowed := 1
+	if len(q) >= 6 {
+		allowed = 2
+	}
+
+	best := allowed + 1
+	candidates := strings.FieldsFunc(string(t), isSeparator)
+	if len(t) > len(q) {
+		candidates = append(candidates, string(t[:len(q)]))
+	}
+	for _, word := range candidates {
+		w := []rune(word)
+		if abs(len(w)-len(q)) > allowed {
+			continue
+		}
+		best = min(best, editDistance(q, w))
+	}
+
+	if best > allowed {
+		return 0
+	}
+	return scoreTypo - 30*best
+}
+
+// editDistance calcula la distancia de Damerau-Levenshtein (variante OSA): inserciones,
+// borrados, sustituciones y transposiciones de caracteres adyacentes.
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// charScore puntúa un carácter emparejado según su posición en la palabra.
+func charScore(orig, t []rune, j int) int {
+	score := charMatch
+	if isBoundary(t, j) {
+		score += boundaryBonus
+	} else if unicode.IsUpper(orig[j]) && unicode.IsLower(orig[j-1]) {
+		score += camelBonus
+	}
+	return score
+}
+
+// isBoundary indica si la posición i inicia una palabra.
+func isBoundary(t []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	return isSeparator(t[i-1]) && !isSeparator(t[i])
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// accents reemplaza las vocales acentuadas más comunes para que "codigo" encuentre "código".
+var accents = map[rune]rune{
+	'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a',
+	'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+	'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+	'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o',
+	'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// fold pasa el texto a minúsculas y sin acentos, conservando un rune por cada rune original
+// para que las posiciones coincidan con el texto sin normalizar.
+func fold(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		r = unicode.ToLower(r)
+		if plain, ok := accents[r]; ok {
+			r = plain
+		}
+		runes[i] = r
+	}
+	return runes
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,94 @@
+package fuzzy
+
+import "testing"
+
+// band es la franja de puntuación esperada para un tipo de coincidencia.
+type band struct {
+	name     string
+	min, max int
+}
+
+var (
+	exact       = band{"exacta", ScoreExact, ScoreExact}
+	prefix      = band{"prefijo", scoreWordPrefix + 1, scorePrefix}
+	wordPrefix  = band{"prefijo de palabra", ScoreWordMatch, scoreWordPrefix}
+	subsequence = band{"subsecuencia", scoreTypo + 10, scoreSubsequence}
+	typo        = band{"error de tipeo", 1, scoreTypo}
+	noMatch     = band{"sin coincidencia", 0, 0}
+)
+
+func TestScoreBands(t *testing.T) {
+	tests := []struct {
+		query, target string
+		want          band
+	}{
+		{"google", "Google", exact},
+		{"  GOOGLE ", "google", exact},
+		{"codigo", "Código", exact}, // Sin acentos.
+		{"goo", "Google", prefix},
+		{"codigo", "Código fuente", prefix},
+		{"cal", "Google Calendar", wordPrefix},
+		{"clo", "Jira Cloud", wordPrefix},
+		{"repo", "mi-repo", wordPrefix},
+		{"gcal", "Google Calendar", subsequence}, // Acrónimo más prefijo.
+		{"gh", "GitHub", subsequence},            // Mayúscula interna.
+		{"jrc", "Jira Cloud", subsequence},
+		{"mail", "Gmail", subsequence}, // Dentro de una palabra: no es prefijo de palabra.
+		{"githbu", "GitHub", typo},     // Transposición.
+		{"gooogle", "Google", typo},    // Carácter de más.
+		{"calnedar", "Google Calendar", typo},
+		{"xyz", "Google", noMatch},
+		{"ab", "ba", noMatch}, // Con menos de 3 caracteres no se toleran errores.
+		{"", "Google", noMatch},
+		{"   ", "Google", noMatch},
+		{"google", "", noMatch},
+	}
+	for _, tt := range tests {
+		got := Score(tt.query, tt.target)
+		if got < tt.want.min || got > tt.want.max {
+			t.Errorf("Score(%q, %q) = %d, se esperaba %s (%d-%d)", tt.query, tt.target, got, tt.want.name, tt.want.min, tt.want.max)
+		}
+	}
+}
+
+func TestScoreOrdering(t *testing.T) {
+	tests := []struct {
+		query         string
+		better, worse string
+	}{
+		{"cal", "cal", "Calendar"},                        // Exacta antes que prefijo.
+		{"cal", "Calendar", "Google Calendar"},            // Prefijo antes que prefijo de palabra.
+		{"cal", "Calendario", "Calendario de la empresa"}, // El prefijo más corto primero.
+		{"cal", "Google Calendar", "Local"},               // Prefijo de palabra antes que subsecuencia.
+		{"gcal", "Google Calendar", "Gmail Local"},        // Inicios de palabra antes que letras dispersas.
+		{"gh", "GitHub", "Graph"},                         // Mayúscula interna antes que letras sueltas.
+		{"gle", "Google", "Agile Leaders"},                // Consecutivas antes que separadas.
+		{"calendr", "Google Calendar", "Google Calnedar"}, // Subsecuencia antes que error de tipeo.
+	}
+	for _, tt := range tests {
+		better, worse := Score(tt.query, tt.better), Score(tt.query, tt.worse)
+		if better <= worse {
+			t.Errorf("Score(%q): %q = %d debería superar a %q = %d", tt.query, tt.better, better, tt.worse, worse)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"google", "google", 0},
+		{"gogle", "google", 1},   // Borrado.
+		{"gooogle", "google", 1}, // Inserción.
+		{"goagle", "google", 1},  // Sustitución.
+		{"googel", "google", 1},  // Transposición.
+		{"", "abc", 3},
+		{"jira", "jitaa", 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, se esperaba %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
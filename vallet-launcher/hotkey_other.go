@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// setupHotkeys no registra atajos globales fuera de Windows, donde dependen de la API Win32.
+func (a *App) setupHotkeys(ctx context.Context) {
+	fmt.Println("ℹ️ Hotkeys globales no disponibles en este sistema; configúralos en el entorno de escritorio.")
+}
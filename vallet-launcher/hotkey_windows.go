@@ -1,3 +1,5 @@
+//go:build windows
+
 package main
 
 import (
@@ -40,11 +42,6 @@ type MSG struct {
 
 // setupHotkeys registra y escucha los atajos de teclado globales (Ctrl+Shift+Espacio y Ctrl+Alt+Espacio).
 func (a *App) setupHotkeys(ctx context.Context) {
-	// Solo disponible en Windows por el uso de la API Win32.
-	if runtime.GOOS != "windows" {
-		return
-	}
-
 	recording := false
 
 	// Se lanza en una goroutine para no bloquear el hilo principal de la UI.
@@ -0,0 +1,666 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// migration describe un cambio de esquema numerado que se aplica una única vez.
+type migration struct {
+	version int                    // Número de versión (consecutivo, empezando en 1).
+	name    string                 // Nombre descriptivo que se guarda en schema_migrations.
+	up      func(tx *sql.Tx) error // Cambios a aplicar dentro de la transacción.
+
+	// disableForeignKeys desactiva las claves foráneas mientras se aplica la migración.
+	// Es necesario para reconstruir tablas referenciadas (DROP + RENAME) sin disparar
+	// las acciones ON DELETE. Al terminar se verifica la integridad con foreign_key_check.
+	disableForeignKeys bool
+}
+
+// migrations contiene todas las migraciones conocidas por esta compilación, en orden.
+// Nunca se debe modificar una migración ya publicada: los cambios nuevos van al final.
+var migrations = []migration{
+	{version: 1, name: "esquema_inicial", up: migrateInitialSchema},
+	{version: 2, name: "valores_por_defecto", up: migrateDefaults},
+	{version: 3, name: "links_folder_id", up: migrateLinkFolderFK, disableForeignKeys: true},
+	{version: 4, name: "carpetas_anidadas", up: migrateNestedFolders, disableForeignKeys: true},
+	{version: 5, name: "links_fts", up: migrateLinksFTS},
+	{version: 6, name: "frecencia_links", up: migrateLinkFrecency},
+	{version: 7, name: "aprendizaje_consultas", up: migrateQueryPicks},
+	{version: 8, name: "motores_busqueda", up: migrateSearchEngines},
+	{version: 9, name: "tipo_accion_links", up: migrateLinkActionType},
+	{version: 10, name: "indice_aplicaciones", up: migrateApplications},
+	{version: 11, name: "indice_archivos", up: migrateFileIndex},
+	{version: 12, name: "historial_portapapeles", up: migrateClipboardHistory},
+	{version: 13, name: "restaurar_portapapeles", up: migratePasteRestore},
+	{version: 14, name: "estrategias_pegado", up: migratePasteStrategies},
+	{version: 15, name: "backend_transcripcion", up: migrateTranscriberBackend},
+	{version: 16, name: "opciones_whisper", up: migrateWhisperOptions},
+	{version: 17, name: "descarga_modelos", up: migrateModelDownloads},
+	{version: 18, name: "modelos_sin_verificar", up: migrateUnverifiedModels},
+	{version: 19, name: "aplicaciones_tryexec", up: migrateApplicationTryExec},
+	{version: 20, name: "hilos_whisper_automaticos", up: migrateWhisperThreadsAuto},
+}
+
+// latestSchemaVersion devuelve la versión de esquema más reciente que conoce esta compilación.
+func latestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+// migrate lleva la base de datos a la última versión de esquema.
+// Cada migración pendiente se ejecuta en su propia transacción junto con el registro en
+// schema_migrations y la actualización de PRAGMA user_version, de forma que un fallo
+// deja la base de datos en la última versión completa.
+func (d *Database) migrate() error {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return fmt.Errorf("migración %q con versión %d fuera de orden (se esperaba %d)", m.name, m.version, i+1)
+		}
+	}
+
+	_, err := d.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`)
+	if err != nil {
+		return err
+	}
+
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	// No abrir bases de datos escritas por una versión más nueva de Vallet OS.
+	latest := latestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("la base de datos tiene la versión de esquema %d, pero esta versión de Vallet OS solo soporta hasta la %d", current, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := d.applyMigration(m); err != nil {
+			return fmt.Errorf("error aplicando migración %d (%s): %w", m.version, m.name, err)
+		}
+		log.Printf("✅ Migración %d (%s) aplicada", m.version, m.name)
+	}
+
+	return nil
+}
+
+// applyMigration ejecuta una migración y registra su versión dentro de la misma transacción.
+// Se usa una conexión dedicada porque PRAGMA foreign_keys es por conexión y no tiene
+// efecto dentro de una transacción.
+func (d *Database) applyMigration(m migration) error {
+	ctx := context.Background()
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.disableForeignKeys {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := m.up(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if m.disableForeignKeys {
+		if err := checkForeignKeys(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.version, m.name); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// PRAGMA no admite parámetros, pero la versión es un entero controlado por nosotros.
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", m.version)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkForeignKeys falla si alguna fila viola una clave foránea tras una migración.
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return err
+		}
+		return fmt.Errorf("violación de clave foránea en la tabla %s (fila %d) hacia %s", table, rowID.Int64, parent)
+	}
+	return rows.Err()
+}
+
+// SchemaVersion devuelve la versión de esquema guardada en PRAGMA user_version.
+func (d *Database) SchemaVersion() (int, error) {
+	var version int
+	err := d.db.QueryRow("PRAGMA user_version").Scan(&version)
+	return version, err
+}
+
+// execAll ejecuta una lista de sentencias dentro de la transacción, deteniéndose en el primer error.
+func execAll(tx *sql.Tx, queries ...string) error {
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ============ Migraciones ============
+
+// migrateInitialSchema crea las tablas originales ('links', 'settings', 'folders', 'usage_stats').
+// Usa IF NOT EXISTS para adoptar bases de datos creadas antes del sistema de migraciones.
+func migrateInitialSchema(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE IF NOT EXISTS links (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			description TEXT,
+			category TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS settings (
+			key TEXT PRIMARY KEY,
+			value TEXT
+		);`,
+		`CREATE TABLE IF NOT EXISTS folders (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS usage_stats (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			tool_type TEXT NOT NULL,
+			day_of_week TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+	)
+}
+
+// migrateDefaults inserta las configuraciones y la carpeta 'General' por defecto,
+// y normaliza los links de la categoría 'general' para que coincidan con la carpeta.
+func migrateDefaults(tx *sql.Tx) error {
+	return execAll(tx,
+		"INSERT OR IGNORE INTO settings (key, value) VALUES ('run_in_background', 'false')",
+		"INSERT OR IGNORE INTO settings (key, value) VALUES ('default_browser', 'system')",
+		"INSERT OR IGNORE INTO settings (key, value) VALUES ('play_audio_transcription', 'true')",
+		"INSERT OR IGNORE INTO folders (name, description) VALUES ('General', 'Carpeta predeterminada para todos los links')",
+		"UPDATE links SET category = 'General' WHERE LOWER(category) = 'general'",
+	)
+}
+
+// migrateLinkFolderFK reemplaza la columna de texto links.category por links.folder_id,
+// una clave foránea hacia folders(id). Las categorías que no tienen carpeta se convierten
+// en carpetas nuevas y los links sin categoría van a 'General'.
+func migrateLinkFolderFK(tx *sql.Tx) error {
+	// Crear carpetas para las categorías huérfanas.
+	_, err := tx.Exec(`INSERT INTO folders (name)
+		SELECT DISTINCT TRIM(category) FROM links
+		WHERE TRIM(COALESCE(category, '')) <> ''
+		AND TRIM(category) NOT IN (SELECT name FROM folders)`)
+	if err != nil {
+		return err
+	}
+
+	var generalID int
+	if err := tx.QueryRow("SELECT id FROM folders WHERE name = 'General'").Scan(&generalID); err != nil {
+		return fmt.Errorf("no se encontró la carpeta 'General': %w", err)
+	}
+
+	// El DEFAULT de la columna debe ser un id concreto para que ON DELETE SET DEFAULT
+	// mueva los links a 'General' al borrar su carpeta.
+	return execAll(tx,
+		fmt.Sprintf(`CREATE TABLE links_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			description TEXT,
+			folder_id INTEGER NOT NULL DEFAULT %d REFERENCES folders(id) ON DELETE SET DEFAULT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`, generalID),
+		fmt.Sprintf(`INSERT INTO links_new (id, name, url, description, folder_id, created_at)
+			SELECT l.id, l.name, l.url, l.description, COALESCE(f.id, %d), l.created_at
+			FROM links l LEFT JOIN folders f ON f.name = TRIM(l.category);`, generalID),
+		"DROP TABLE links;",
+		"ALTER TABLE links_new RENAME TO links;",
+		"CREATE INDEX idx_links_folder_id ON links(folder_id);",
+		fmt.Sprintf("INSERT OR REPLACE INTO settings (key, value) VALUES ('default_folder_id', '%d')", generalID),
+	)
+}
+
+// migrateNestedFolders agrega parent_id a folders para permitir jerarquías. El nombre deja
+// de ser único globalmente y pasa a ser único entre hermanos. También crea la vista
+// folder_paths con la ruta completa de cada carpeta ("Trabajo/Clientes/Acme").
+func migrateNestedFolders(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE folders_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			parent_id INTEGER REFERENCES folders(id),
+			name TEXT NOT NULL,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`INSERT INTO folders_new (id, parent_id, name, description, created_at)
+			SELECT id, NULL, name, description, created_at FROM folders;`,
+		"DROP TABLE folders;",
+		"ALTER TABLE folders_new RENAME TO folders;",
+		"CREATE UNIQUE INDEX idx_folders_parent_name ON folders(COALESCE(parent_id, 0), name);",
+		`CREATE VIEW folder_paths AS
+			WITH RECURSIVE tree(id, path, depth) AS (
+				SELECT id, name, 0 FROM folders WHERE parent_id IS NULL
+				UNION ALL
+				SELECT f.id, tree.path || '/' || f.name, tree.depth + 1
+				FROM folders f JOIN tree ON f.parent_id = tree.id
+			)
+			SELECT id, path, depth FROM tree;`,
+	)
+}
+
+// migrateLinksFTS crea el índice de texto completo links_fts (FTS5) sobre name, url y
+// description, sincronizado con links mediante triggers. Si la compilación de SQLite no
+// incluye FTS5 la migración no hace nada y la búsqueda usa LIKE; ensureLinksFTS vuelve a
+// intentarlo en cada arranque.
+// Nota: cualquier migración futura que reconstruya la tabla links debe recrear estos triggers.
+func migrateLinksFTS(tx *sql.Tx) error {
+	_, err := createLinksFTS(tx)
+	return err
+}
+
+// createLinksFTS crea links_fts, sus triggers y lo llena con los links existentes. Devuelve
+// false, sin error, si SQLite no incluye FTS5.
+func createLinksFTS(tx *sql.Tx) (bool, error) {
+	_, err := tx.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS links_fts USING fts5(
+		name, url, description,
+		content='links', content_rowid='id',
+		tokenize='unicode61 remove_diacritics 2'
+	);`)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			log.Println("⚠️ SQLite sin soporte FTS5: se usará la búsqueda LIKE")
+			return false, nil
+		}
+		return false, err
+	}
+
+	err = execAll(tx,
+		`CREATE TRIGGER IF NOT EXISTS links_fts_ai AFTER INSERT ON links BEGIN
+			INSERT INTO links_fts (rowid, name, url, description) VALUES (new.id, new.name, new.url, new.description);
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS links_fts_ad AFTER DELETE ON links BEGIN
+			INSERT INTO links_fts (links_fts, rowid, name, url, description) VALUES ('delete', old.id, old.name, old.url, old.description);
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS links_fts_au AFTER UPDATE ON links BEGIN
+			INSERT INTO links_fts (links_fts, rowid, name, url, description) VALUES ('delete', old.id, old.name, old.url, old.description);
+			INSERT INTO links_fts (rowid, name, url, description) VALUES (new.id, new.name, new.url, new.description);
+		END;`,
+		"INSERT INTO links_fts (links_fts) VALUES ('rebuild');",
+	)
+	return err == nil, err
+}
+
+// migrateLinkFrecency crea el registro de aperturas de cada link (link_launches) y la
+// tabla link_stats con la puntuación de frecencia acumulada. Se usa una tabla aparte para
+// no disparar los triggers de FTS de links en cada apertura.
+func migrateLinkFrecency(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE link_launches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			link_id INTEGER NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+			launched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		"CREATE INDEX idx_link_launches_link_id ON link_launches(link_id);",
+		`CREATE TABLE link_stats (
+			link_id INTEGER PRIMARY KEY REFERENCES links(id) ON DELETE CASCADE,
+			launch_count INTEGER NOT NULL DEFAULT 0,
+			frecency REAL NOT NULL DEFAULT 0,
+			last_launched_at INTEGER NOT NULL DEFAULT 0
+		);`,
+	)
+}
+
+// migrateQueryPicks crea query_picks, que recuerda qué link se eligió para cada prefijo de
+// consulta (ej: "j" -> Jira) para favorecerlo en búsquedas posteriores.
+func migrateQueryPicks(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE query_picks (
+			prefix TEXT NOT NULL,
+			link_id INTEGER NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+			pick_count INTEGER NOT NULL DEFAULT 0,
+			last_picked_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (prefix, link_id)
+		);`,
+		"CREATE INDEX idx_query_picks_link_id ON query_picks(link_id);",
+	)
+}
+
+// migrateSearchEngines crea search_engines (atajos tipo "g texto" -> Google) con los
+// motores más comunes y el motor por defecto para el texto libre.
+func migrateSearchEngines(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE search_engines (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			keyword TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`INSERT INTO search_engines (keyword, name, url) VALUES
+			('g', 'Google', 'https://www.google.com/search?q={query}'),
+			('ddg', 'DuckDuckGo', 'https://duckduckgo.com/?q={query}'),
+			('wiki', 'Wikipedia', 'https://es.wikipedia.org/w/index.php?search={query}'),
+			('so', 'Stack Overflow', 'https://stackoverflow.com/search?q={query}');`,
+		"INSERT OR IGNORE INTO settings (key, value) VALUES ('default_search_engine', 'g')",
+	)
+}
+
+// migrateLinkActionType agrega links.action_type (url, file, folder, application, command
+// o snippet) y clasifica los links existentes a partir de su URL o comando con
+// classifyLinkV9.
+func migrateLinkActionType(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE links ADD COLUMN action_type TEXT NOT NULL DEFAULT 'url'"); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("SELECT id, url FROM links")
+	if err != nil {
+		return err
+	}
+	types := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var target string
+		if err := rows.Scan(&id, &target); err != nil {
+			rows.Close()
+			return err
+		}
+		types[id] = classifyLinkV9(target)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, actionType := range types {
+		if actionType == "url" {
+			continue
+		}
+		if _, err := tx.Exec("UPDATE links SET action_type = ? WHERE id = ?", actionType, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkV9TLDs son los dominios de primer nivel que reconocía la versión 9 al clasificar una
+// URL sin esquema ("github.com").
+var linkV9TLDs = map[string]bool{
+	"com": true, "org": true, "net": true, "edu": true, "gov": true, "mil": true, "int": true,
+	"io": true, "dev": true, "app": true, "ai": true, "co": true, "me": true, "tv": true,
+	"info": true, "biz": true, "xyz": true, "tech": true, "site": true, "online": true,
+	"cloud": true, "gg": true, "ly": true, "sh": true, "so": true, "to": true, "fm": true,
+	"es": true, "mx": true, "ar": true, "cl": true, "pe": true, "uy": true, "ve": true,
+	"ec": true, "bo": true, "py": true, "cr": true, "gt": true, "do": true, "pr": true,
+	"us": true, "ca": true, "uk": true, "de": true, "fr": true, "it": true, "pt": true,
+	"br": true, "nl": true, "be": true, "ch": true, "at": true, "se": true, "no": true,
+	"dk": true, "fi": true, "pl": true, "eu": true, "ru": true, "jp": true, "cn": true,
+	"kr": true, "in": true, "au": true, "nz": true, "za": true, "page": true, "blog": true,
+}
+
+// classifyLinkV9 es una copia congelada del clasificador de la versión 9 (inferActionType),
+// para que la migración dé siempre el mismo resultado aunque el launcher cambie su forma de
+// reconocer URLs y rutas. No consulta el disco: una ruta sin barra final es un archivo
+// (archivos y carpetas se abren igual).
+//
+// Antes de esta versión todos los links se abrían en el navegador, así que ante la duda el
+// link sigue siendo una URL: solo se clasifica como comando lo que lleva argumentos ("code .",
+// "echo {1}"), que nunca pudo abrirse como URL. Así la migración no convierte datos guardados
+// en comandos de shell.
+func classifyLinkV9(target string) string {
+	target = strings.TrimSpace(target)
+	if strings.ContainsAny(target, "{}") {
+		if strings.Contains(target, "://") || !strings.ContainsAny(target, " \t") {
+			return "url"
+		}
+		return "command"
+	}
+	if u, err := url.Parse(target); err == nil && len(u.Scheme) > 1 && (u.Host != "" || u.Scheme == "mailto") {
+		return "url"
+	}
+	if target != "" && !strings.ContainsAny(target, " \t\\") {
+		if u, err := url.Parse("https://" + target); err == nil && u.Host != "" {
+			host := u.Hostname()
+			dot := strings.LastIndex(host, ".")
+			if host == "localhost" || net.ParseIP(host) != nil || (dot > 0 && linkV9TLDs[strings.ToLower(host[dot+1:])]) {
+				return "url"
+			}
+		}
+	}
+	isPath := filepath.IsAbs(target) || strings.HasPrefix(target, "~") || strings.HasPrefix(target, `\\`) ||
+		strings.HasPrefix(target, "/") || strings.HasPrefix(target, "./") || strings.HasPrefix(target, `.\`)
+	if isPath && !strings.ContainsAny(target, "\t") {
+		if strings.HasSuffix(target, "/") || strings.HasSuffix(target, `\`) {
+			return "folder"
+		}
+		return "file"
+	}
+	if strings.ContainsAny(target, " \t") {
+		return "command"
+	}
+	return "url"
+}
+
+// migrateApplications crea applications, la caché de las aplicaciones instaladas leídas de
+// los archivos .desktop. mod_time, size y locale permiten reindexar solo los archivos que
+// cambiaron (o todos si cambia el idioma del sistema).
+func migrateApplications(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE applications (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			desktop_id TEXT NOT NULL UNIQUE,
+			path TEXT NOT NULL,
+			name TEXT NOT NULL,
+			generic_name TEXT NOT NULL DEFAULT '',
+			comment TEXT NOT NULL DEFAULT '',
+			keywords TEXT NOT NULL DEFAULT '',
+			exec TEXT NOT NULL DEFAULT '',
+			icon TEXT NOT NULL DEFAULT '',
+			no_display INTEGER NOT NULL DEFAULT 0,
+			mod_time INTEGER NOT NULL DEFAULT 0,
+			size INTEGER NOT NULL DEFAULT 0,
+			locale TEXT NOT NULL DEFAULT '',
+			indexed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+	)
+}
+
+// migrateFileIndex crea indexed_files, el índice de archivos y carpetas de las raíces
+// configuradas, y la configuración por defecto de la búsqueda de archivos.
+func migrateFileIndex(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE indexed_files (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			root TEXT NOT NULL,
+			path TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			is_dir INTEGER NOT NULL DEFAULT 0,
+			mod_time INTEGER NOT NULL DEFAULT 0,
+			size INTEGER NOT NULL DEFAULT 0,
+			indexed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		"CREATE INDEX idx_indexed_files_name ON indexed_files(name COLLATE NOCASE);",
+		`INSERT OR IGNORE INTO settings (key, value) VALUES
+			('file_search_enabled', 'true'),
+			('file_search_roots', '~/Documents, ~/Desktop, ~/Projects'),
+			('file_search_include', ''),
+			('file_search_exclude', '.*, node_modules, __pycache__, *.tmp'),
+			('file_search_max_depth', '4');`,
+	)
+}
+
+// migrateClipboardHistory crea clipboard_history, los últimos textos copiados (sin
+// duplicados: volver a copiar un texto actualiza last_copied_at), y su configuración.
+// last_copied_at guarda milisegundos para ordenar bien los textos copiados en el mismo segundo.
+func migrateClipboardHistory(tx *sql.Tx) error {
+	return execAll(tx,
+		`CREATE TABLE clipboard_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			content TEXT NOT NULL UNIQUE,
+			pinned INTEGER NOT NULL DEFAULT 0,
+			copy_count INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			last_copied_at DATETIME DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+		);`,
+		"CREATE INDEX idx_clipboard_history_last_copied ON clipboard_history(last_copied_at);",
+		`INSERT OR IGNORE INTO settings (key, value) VALUES
+			('clipboard_history_enabled', 'true'),
+			('clipboard_history_size', '200'),
+			('clipboard_history_skip_sensitive', 'true'),
+			('clipboard_history_exclude', '');`,
+	)
+}
+
+// migratePasteRestore agrega la configuración para restaurar el portapapeles después de
+// pegar una transcripción o un snippet.
+func migratePasteRestore(tx *sql.Tx) error {
+	return execAll(tx,
+		`INSERT OR IGNORE INTO settings (key, value) VALUES
+			('paste_restore_clipboard', 'true'),
+			('paste_restore_delay_ms', '500');`,
+	)
+}
+
+// migratePasteStrategies agrega la estrategia de pegado por defecto y las reglas por
+// aplicación ("patrón = estrategia", una por línea) para las terminales y clientes de
+// escritorio remoto conocidos, donde Ctrl+V no funciona.
+func migratePasteStrategies(tx *sql.Tx) error {
+	rules := strings.Join([]string{
+		"mintty = shift_insert",
+		"putty = shift_insert",
+		"xterm = shift_insert",
+		"gnome-terminal* = ctrl_shift_v",
+		"konsole = ctrl_shift_v",
+		"kitty = ctrl_shift_v",
+		"alacritty = ctrl_shift_v",
+		"xfce4-terminal = ctrl_shift_v",
+		"tilix = ctrl_shift_v",
+		"wezterm* = ctrl_shift_v",
+		"foot = ctrl_shift_v",
+		"mstsc = type",
+		"vmconnect = type",
+	}, "\n")
+	_, err := tx.Exec(`INSERT OR IGNORE INTO settings (key, value) VALUES
+		('paste_strategy', 'ctrl_v'),
+		('paste_rules', ?);`, rules)
+	return err
+}
+
+// migrateTranscriberBackend agrega la elección del backend de transcripción y las
+// opciones de los backends HTTP (servidor de whisper.cpp y API compatible con OpenAI).
+func migrateTranscriberBackend(tx *sql.Tx) error {
+	return execAll(tx,
+		`INSERT OR IGNORE INTO settings (key, value) VALUES
+			('transcriber_backend', 'whisper-cli'),
+			('whisper_server_url', 'http://127.0.0.1:8080'),
+			('transcription_api_url', 'https://api.openai.com/v1'),
+			('transcription_api_key', ''),
+			('transcription_api_model', 'whisper-1');`,
+	)
+}
+
+// migrateWhisperOptions agrega los parámetros de whisper.cpp: modelo, idioma, hilos (todos
+// los núcleos de este equipo), beam search, best of, temperatura, prompt inicial y
+// traducción al inglés.
+func migrateWhisperOptions(tx *sql.Tx) error {
+	_, err := tx.Exec(`INSERT OR IGNORE INTO settings (key, value) VALUES
+		('whisper_model_path', ''),
+		('whisper_language', 'auto'),
+		('whisper_threads', ?),
+		('whisper_beam_size', '1'),
+		('whisper_best_of', '1'),
+		('whisper_temperature', '0'),
+		('whisper_initial_prompt', ''),
+		('whisper_translate', 'false');`, strconv.Itoa(runtime.NumCPU()))
+	return err
+}
+
+// migrateModelDownloads agrega el origen de las descargas de modelos y la URL opcional de
+// un manifiesto con sus sumas SHA256 (vacía para usar la lista incluida).
+func migrateModelDownloads(tx *sql.Tx) error {
+	return execAll(tx,
+		`INSERT OR IGNORE INTO settings (key, value) VALUES
+			('model_download_url', 'https://huggingface.co/ggerganov/whisper.cpp/resolve/main'),
+			('model_manifest_url', '');`,
+	)
+}
+
+// migrateUnverifiedModels agrega el permiso para activar modelos cuya suma SHA256 no se
+// puede comprobar. Está desactivado por defecto.
+func migrateUnverifiedModels(tx *sql.Tx) error {
+	return execAll(tx,
+		`INSERT OR IGNORE INTO settings (key, value) VALUES ('model_allow_unverified', 'false');`,
+	)
+}
+
+// migrateApplicationTryExec guarda el TryExec de cada aplicación y si su programa está
+// instalado, para volver a comprobarlo en cada reindexado sin releer el .desktop. Se borran
+// las fechas guardadas para que el próximo reindexado lea todos los archivos de nuevo.
+func migrateApplicationTryExec(tx *sql.Tx) error {
+	return execAll(tx,
+		"ALTER TABLE applications ADD COLUMN try_exec TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE applications ADD COLUMN available INTEGER NOT NULL DEFAULT 1",
+		"UPDATE applications SET mod_time = 0",
+	)
+}
+
+// migrateWhisperThreadsAuto guarda whisper_threads como 0 (todos los núcleos) cuando tenía
+// el número de núcleos que se fijó al instalar, o más de los que tiene el equipo. Así la
+// configuración no queda atada al equipo en el que se creó la base de datos.
+func migrateWhisperThreadsAuto(tx *sql.Tx) error {
+	_, err := tx.Exec(`UPDATE settings SET value = '0'
+		WHERE key = 'whisper_threads' AND (TRIM(value) = '' OR CAST(value AS INTEGER) >= ?)`, runtime.NumCPU())
+	return err
+}
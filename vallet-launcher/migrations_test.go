@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestClassifyLinkV9(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com":                  "url",
+		"github.com/usuario/repo":             "url",
+		"localhost:3000":                      "url",
+		"192.168.1.1:8080":                    "url",
+		"mailto:hola@ejemplo.es":              "url",
+		"https://google.com/search?q={query}": "url",
+		"notas.txt":                           "url", // Antes se abría en el navegador.
+		"1.5":                                 "url",
+		"intranet.corp/wiki":                  "url",
+		"notepad":                             "url",
+		"buscar{1}":                           "url",
+		"code .":                              "command",
+		"echo {1}":                            "command",
+		"/home/usuario/Documentos/":           "folder",
+		`\\servidor\compartido\`:              "folder",
+		"~/notas.txt":                         "file",
+		"~/Documentos":                        "file", // Sin consultar el disco.
+		"  https://github.com  ":              "url",
+	}
+	for target, want := range tests {
+		if got := classifyLinkV9(target); got != want {
+			t.Errorf("classifyLinkV9(%q) = %q, se esperaba %q", target, got, want)
+		}
+	}
+}
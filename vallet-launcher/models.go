@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"vallet-launcher/ai"
+
+	wailsruntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// manifestTimeout limita cuánto se espera al manifiesto de modelos configurado.
+const manifestTimeout = 15 * time.Second
+
+// ModelStatus es un modelo tal como se muestra en el panel de administración.
+type ModelStatus struct {
+	Name        string `json:"name"`        // Nombre corto (ej: "small").
+	File        string `json:"file"`        // Nombre del archivo.
+	SizeMB      int    `json:"sizeMB"`      // Tamaño aproximado de la descarga.
+	Description string `json:"description"` // Descripción del manifiesto.
+	Verified    bool   `json:"verified"`    // El manifiesto tiene su SHA256 y la descarga se verifica.
+	Installed   bool   `json:"installed"`   // Está en la carpeta de modelos.
+	Path        string `json:"path"`        // Ruta del archivo, si está instalado.
+	Size        int64  `json:"size"`        // Tamaño en disco, si está instalado.
+	Active      bool   `json:"active"`      // Es el modelo que se usa para transcribir.
+	Downloading bool   `json:"downloading"` // Se está descargando.
+}
+
+// modelDownloads registra las descargas en curso para poder cancelarlas.
+type modelDownloads struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newModelDownloads() *modelDownloads {
+	return &modelDownloads{cancels: map[string]context.CancelFunc{}}
+}
+
+// start registra la descarga de name; devuelve false si ya había una en curso.
+func (d *modelDownloads) start(name string, cancel context.CancelFunc) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.cancels[name]; ok {
+		return false
+	}
+	d.cancels[name] = cancel
+	return true
+}
+
+func (d *modelDownloads) finish(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.cancels, name)
+}
+
+func (d *modelDownloads) active(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.cancels[name]
+	return ok
+}
+
+func (d *modelDownloads) cancel(name string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cancel, ok := d.cancels[name]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func (d *modelDownloads) cancelAll() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, cancel := range d.cancels {
+		cancel()
+	}
+}
+
+// modelManager crea el administrador de modelos con el origen y el manifiesto
+// configurados. Si el manifiesto no se puede descargar se devuelve igualmente un
+// administrador con la lista incluida, junto con el error.
+func (a *App) modelManager(ctx context.Context) (*ai.ModelManager, error) {
+	dir, err := ai.DefaultModelsDir()
+	if err != nil {
+		return nil, err
+	}
+	source, _ := a.db.GetSetting("model_download_url")
+	manifestURL, _ := a.db.GetSetting("model_manifest_url")
+
+	manifest := ai.DefaultManifest()
+	manager := ai.NewModelManager(dir, source, manifest)
+	if manifestURL = strings.TrimSpace(manifestURL); manifestURL != "" {
+		ctx, cancel := context.WithTimeout(ctx, manifestTimeout)
+		defer cancel()
+		manifest, err := ai.FetchManifest(ctx, manifestURL)
+		if err != nil {
+			return manager, fmt.Errorf("no se pudo leer el manifiesto de modelos: %w", err)
+		}
+		manager.Manifest = manifest
+	}
+	return manager, nil
+}
+
+// GetModels devuelve los modelos del manifiesto y los instalados, indicando cuál está activo.
+func (a *App) GetModels() ([]ModelStatus, error) {
+	manager, err := a.modelManager(a.ctx)
+	if manager == nil {
+		return nil, err
+	}
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+	installed, err := manager.Installed()
+	if err != nil {
+		return nil, err
+	}
+	activePath := a.loadWhisperSettings().ModelPath
+
+	byFile := map[string]ai.InstalledModel{}
+	for _, m := range installed {
+		byFile[m.File] = m
+	}
+
+	var models []ModelStatus
+	for _, info := range manager.Manifest.Models {
+		status := ModelStatus{
+			Name:        info.Name,
+			File:        info.File,
+			SizeMB:      info.SizeMB,
+			Description: info.Description,
+			Verified:    info.SHA256 != "",
+			Downloading: a.downloads.active(info.Name),
+		}
+		if m, ok := byFile[info.File]; ok {
+			status.Installed, status.Path, status.Size = true, m.Path, m.Size
+			status.Active = m.Path == activePath
+			delete(byFile, info.File)
+		}
+		models = append(models, status)
+	}
+	// Modelos copiados a mano en la carpeta que no están en el manifiesto.
+	for _, m := range installed {
+		if _, ok := byFile[m.File]; !ok {
+			continue
+		}
+		models = append(models, ModelStatus{
+			Name:      m.Name,
+			File:      m.File,
+			Installed: true,
+			Path:      m.Path,
+			Size:      m.Size,
+			Active:    m.Path == activePath,
+		})
+	}
+	return models, nil
+}
+
+// DownloadModel empieza a descargar un modelo en segundo plano. El avance se emite con el
+// evento "model-download-progress" y el final con "model-download-finished". Si no hay
+// ningún modelo activo, el descargado pasa a serlo.
+func (a *App) DownloadModel(name string) error {
+	manager, err := a.modelManager(a.ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := manager.Manifest.Find(name); !ok {
+		return fmt.Errorf("modelo desconocido: %q", name)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if !a.downloads.start(name, cancel) {
+		cancel()
+		return fmt.Errorf("el modelo %s ya se está descargando", name)
+	}
+
+	go func() {
+		defer a.downloads.finish(name)
+		defer cancel()
+
+		fmt.Printf("⬇️ Descargando el modelo %s desde %s...\n", name, manager.Source)
+		path, err := manager.Download(ctx, name, func(p ai.DownloadProgress) {
+			wailsruntime.EventsEmit(a.ctx, "model-download-progress", p)
+		})
+
+		result := map[string]string{"name": name, "path": path}
+		if err != nil {
+			if ctx.Err() != nil {
+				err = fmt.Errorf("descarga cancelada")
+			}
+			fmt.Printf("❌ Error descargando el modelo %s: %v\n", name, err)
+			result["error"] = err.Error()
+		} else {
+			fmt.Printf("✅ Modelo %s descargado en %s\n", name, path)
+			if a.loadWhisperSettings().ModelPath == "" {
+				if err := a.activateModel(manager, name); err != nil {
+					fmt.Printf("⚠️ No se pudo activar el modelo %s: %v\n", name, err)
+				}
+			}
+		}
+		wailsruntime.EventsEmit(a.ctx, "model-download-finished", result)
+	}()
+	return nil
+}
+
+// CancelModelDownload detiene la descarga de un modelo. Lo descargado se conserva para
+// reanudarla más tarde.
+func (a *App) CancelModelDownload(name string) {
+	a.downloads.cancel(name)
+}
+
+// DeleteModel borra un modelo instalado. Si era el activo se vuelve al modelo por defecto.
+func (a *App) DeleteModel(name string) error {
+	if a.downloads.active(name) {
+		return fmt.Errorf("el modelo %s se está descargando", name)
+	}
+	manager, err := a.modelManager(a.ctx)
+	if manager == nil {
+		return err
+	}
+	path, err := manager.Path(name)
+	if err != nil {
+		return err
+	}
+	if err := manager.Delete(name); err != nil {
+		return err
+	}
+	if a.loadWhisperSettings().ModelPath == path {
+		return a.db.UpdateSetting("whisper_model_path", "")
+	}
+	return nil
+}
+
+// SetActiveModel elige el modelo instalado que se usa para transcribir.
+func (a *App) SetActiveModel(name string) error {
+	manager, err := a.modelManager(a.ctx)
+	if manager == nil {
+		return err
+	}
+	return a.activateModel(manager, name)
+}
+
+// activateModel comprueba el SHA256 del modelo y lo deja como activo. Los modelos sin suma
+// en el manifiesto (copiados a mano o de un manifiesto incompleto) solo se activan si el
+// usuario lo permitió con model_allow_unverified.
+func (a *App) activateModel(manager *ai.ModelManager, name string) error {
+	path, err := manager.Path(name)
+	if err != nil {
+		return err
+	}
+	if err := manager.Verify(name); err != nil {
+		allow, _ := a.db.GetSetting("model_allow_unverified")
+		if !errors.Is(err, ai.ErrUnverified) || allow != "true" {
+			return err
+		}
+		fmt.Printf("⚠️ Activando el modelo %s sin verificar su SHA256.\n", name)
+	}
+	return a.UpdateSettingBackend("whisper_model_path", path)
+}
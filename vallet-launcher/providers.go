@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultProviderTimeout es el tiempo máximo que se espera a un proveedor por consulta. Los
+// que no responden a tiempo se omiten para que el launcher no se bloquee al escribir.
+const defaultProviderTimeout = 400 * time.Millisecond
+
+// Result es una sugerencia del launcher producida por un proveedor.
+type Result struct {
+	Provider string `json:"provider"` // Nombre del proveedor que la generó.
+	ID       int    `json:"id"`       // Identificador dentro del proveedor (link, aplicación, archivo...).
+	Title    string `json:"title"`    // Texto principal.
+	Subtitle string `json:"subtitle"` // Texto secundario (URL, ruta, descripción...).
+	Type     string `json:"type"`     // Tipo de acción que se ejecutará (ver actions.go).
+	Target   string `json:"target"`   // URL, ruta, comando o valor asociado.
+	Score    int    `json:"score"`    // Relevancia; los resultados de todos los proveedores se ordenan por ella.
+	Query    string `json:"query"`    // Consulta que produjo el resultado (para aprender la elección).
+}
+
+// Provider es una fuente de resultados del launcher (links, aplicaciones, archivos...).
+type Provider interface {
+	// Name identifica al proveedor; se guarda en cada Result para saber quién lo ejecuta.
+	Name() string
+	// Search devuelve los resultados para la consulta. Debe respetar la cancelación de ctx.
+	Search(ctx context.Context, query string) []Result
+	// Execute abre o ejecuta un resultado devuelto por Search.
+	Execute(result Result) error
+}
+
+// ModeProvider es un proveedor que solo responde a las consultas que empiezan por su
+// prefijo (ej: "cb texto"). En ese caso es el único que se consulta y recibe la consulta
+// sin el prefijo.
+type ModeProvider interface {
+	Provider
+	Prefix() string
+}
+
+// registeredProvider es un proveedor junto con su tiempo máximo de respuesta.
+type registeredProvider struct {
+	provider Provider
+	timeout  time.Duration
+}
+
+// registerProviders registra los proveedores del launcher. El orden importa: a igual
+// puntuación, los resultados de los primeros proveedores aparecen antes.
+func (a *App) registerProviders() {
+	a.registerProvider(&linkProvider{app: a}, defaultProviderTimeout)
+	a.registerProvider(&calculatorProvider{app: a}, defaultProviderTimeout)
+	if supportsDesktopEntries() {
+		a.registerProvider(&applicationProvider{app: a}, defaultProviderTimeout)
+	}
+	a.registerProvider(&fileProvider{app: a}, defaultProviderTimeout)
+	a.registerProvider(&clipboardProvider{app: a}, defaultProviderTimeout)
+}
+
+// registerProvider agrega un proveedor al registro. Un timeout de 0 usa defaultProviderTimeout.
+func (a *App) registerProvider(p Provider, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultProviderTimeout
+	}
+	a.providers = append(a.providers, registeredProvider{provider: p, timeout: timeout})
+}
+
+// Search consulta todos los proveedores en paralelo, cada uno con su propio tiempo máximo,
+// y devuelve sus resultados mezclados y ordenados por puntuación. Si la consulta empieza
+// por el prefijo de un ModeProvider, solo se consulta ese proveedor.
+func (a *App) Search(query string) []Result {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []Result{}
+	}
+	if rp, rest, ok := a.modeProvider(query); ok {
+		results := searchWithTimeout(a.appContext(), rp, rest)
+		for i := range results {
+			results[i].Provider = rp.provider.Name()
+			results[i].Query = query
+		}
+		return sortResults(results)
+	}
+
+	// Una llamada a un motor de búsqueda ("g texto") no muestra sugerencias, así Enter
+	// siempre busca con el motor.
+	if engine, _ := a.engineCall(query); engine != nil {
+		return []Result{}
+	}
+
+	parent := a.appContext()
+	perProvider := make([][]Result, len(a.providers))
+	var wg sync.WaitGroup
+	for i, rp := range a.providers {
+		if _, isMode := rp.provider.(ModeProvider); isMode {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			perProvider[i] = searchWithTimeout(parent, rp, query)
+		}()
+	}
+	wg.Wait()
+
+	results := []Result{}
+	for i, providerResults := range perProvider {
+		name := a.providers[i].provider.Name()
+		for _, r := range providerResults {
+			r.Provider = name
+			r.Query = query
+			results = append(results, r)
+		}
+	}
+
+	return sortResults(results)
+}
+
+// sortResults ordena los resultados por puntuación. El orden es estable: a igual
+// puntuación se respeta el orden de registro de los proveedores.
+func sortResults(results []Result) []Result {
+	if results == nil {
+		return []Result{}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
+
+// appContext devuelve el contexto de la aplicación, o uno vacío si todavía no arrancó.
+func (a *App) appContext() context.Context {
+	if a.ctx == nil {
+		return context.Background()
+	}
+	return a.ctx
+}
+
+// modeProvider devuelve el proveedor cuyo prefijo inicia la consulta ("cb" o "cb texto")
+// y el resto de la consulta.
+func (a *App) modeProvider(query string) (registeredProvider, string, bool) {
+	for _, rp := range a.providers {
+		mp, ok := rp.provider.(ModeProvider)
+		if !ok {
+			continue
+		}
+		word, rest, _ := strings.Cut(query, " ")
+		if strings.EqualFold(word, mp.Prefix()) {
+			return rp, strings.TrimSpace(rest), true
+		}
+	}
+	return registeredProvider{}, "", false
+}
+
+// searchWithTimeout ejecuta la búsqueda de un proveedor y descarta su respuesta si tarda
+// más que su tiempo máximo (aunque el proveedor ignore la cancelación del contexto).
+func searchWithTimeout(parent context.Context, rp registeredProvider, query string) []Result {
+	ctx, cancel := context.WithTimeout(parent, rp.timeout)
+	defer cancel()
+
+	done := make(chan []Result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Error en el proveedor %s: %v", rp.provider.Name(), r)
+				done <- nil
+			}
+		}()
+		done <- rp.provider.Search(ctx, query)
+	}()
+
+	select {
+	case results := <-done:
+		return results
+	case <-ctx.Done():
+		log.Printf("⏱️ El proveedor %s no respondió en %s", rp.provider.Name(), rp.timeout)
+		return nil
+	}
+}
+
+// ExecuteResult ejecuta un resultado elegido en el launcher con el proveedor que lo generó.
+func (a *App) ExecuteResult(result Result) error {
+	for _, rp := range a.providers {
+		if rp.provider.Name() == result.Provider {
+			return rp.provider.Execute(result)
+		}
+	}
+	return fmt.Errorf("proveedor desconocido: %s", result.Provider)
+}
+
+// linkResult convierte un link puntuado en un resultado del launcher.
+func linkResult(s scoredLink, subtitle string) Result {
+	return Result{
+		ID:       s.link.ID,
+		Title:    s.link.Name,
+		Subtitle: subtitle,
+		Type:     s.link.Type,
+		Target:   s.link.URL,
+		Score:    s.score,
+	}
+}
+
+// linkProvider busca entre los links guardados por el usuario.
+type linkProvider struct {
+	app *App
+}
+
+func (p *linkProvider) Name() string { return SourceLink }
+
+func (p *linkProvider) Search(ctx context.Context, query string) []Result {
+	scored, err := p.app.db.searchLinksScored(ctx, query)
+	if err != nil {
+		log.Println("Error buscando links:", err)
+		return nil
+	}
+
+	results := make([]Result, 0, len(scored))
+	for _, s := range scored {
+		subtitle := s.link.URL
+		if s.link.Type == ActionSnippet {
+			subtitle = snippetPreview(s.link.URL)
+		}
+		results = append(results, linkResult(s, subtitle))
+	}
+	return results
+}
+
+// snippetPreview resume un snippet en una línea para mostrarlo como subtítulo.
+func snippetPreview(text string) string {
+	return actionLabels[ActionSnippet] + ": " + previewLine(text)
+}
+
+// previewLine resume un texto en su primera línea, recortada si es muy larga.
+func previewLine(text string) string {
+	const maxPreview = 80
+	line, _, multiline := strings.Cut(strings.TrimSpace(text), "\n")
+	line = strings.TrimSpace(line)
+	if runes := []rune(line); len(runes) > maxPreview {
+		line = string(runes[:maxPreview])
+		multiline = true
+	}
+	if multiline {
+		line += "…"
+	}
+	return line
+}
+
+func (p *linkProvider) Execute(result Result) error {
+	return p.app.OpenLink(result.ID, result.Query)
+}
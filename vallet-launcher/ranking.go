@@ -0,0 +1,206 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"vallet-launcher/fuzzy"
+	"vallet-launcher/templates"
+)
+
+const (
+	// exactAliasBonus asegura que un alias escrito completo quede siempre primero: supera la
+	// suma de todos los bonus de uso que puede acumular un prefijo.
+	exactAliasBonus = textMatchBonus + maxFrecencyBonus + maxPickBonus
+	// textMatchBonus se suma a los links que también devuelve la búsqueda de texto
+	// (FTS/LIKE), repartido según su posición en esos resultados.
+	textMatchBonus = 120
+	// frecencyWeight y maxFrecencyBonus controlan cuánto pesa el uso: el bonus crece con el
+	// logaritmo de la frecencia para que un link muy usado gane a un prefijo sin uso, pero
+	// nunca a un alias exacto.
+	frecencyWeight   = 80
+	maxFrecencyBonus = 600
+	// frecencyHalfLife es el tiempo tras el cual una apertura vale la mitad.
+	frecencyHalfLife = 72 * time.Hour
+	// pickWeight y maxPickBonus controlan cuánto pesa haber elegido el link para la misma
+	// consulta. Pesa más que la frecencia porque es específico de lo que se escribió.
+	pickWeight   = 150
+	maxPickBonus = 900
+	// maxLearnedPrefix limita la longitud de los prefijos guardados en query_picks.
+	maxLearnedPrefix = 20
+)
+
+// rankSignals agrupa la información de uso que complementa la coincidencia de texto.
+type rankSignals struct {
+	textHits   []Link          // Resultados de la búsqueda FTS/LIKE, en su orden.
+	frecencies map[int]float64 // Frecencia actual por ID de link.
+	picks      map[int]int     // Veces que se eligió cada link para esta consulta.
+}
+
+// scoredLink asocia un link con su puntuación para una consulta.
+type scoredLink struct {
+	link  Link
+	score int
+}
+
+// rankLinks ordena los links por relevancia para la consulta combinando la coincidencia
+// difusa en nombre, URL y descripción con el orden de la búsqueda de texto, la frecencia
+// de cada link y lo aprendido para esta consulta. Los links sin ninguna coincidencia se
+// descartan. Es el único criterio de orden del launcher, de modo que la primera sugerencia
+// y la acción de Enter siempre coinciden.
+func rankLinks(query string, links []Link, signals rankSignals) []Link {
+	if strings.TrimSpace(query) == "" {
+		return links
+	}
+
+	scored := scoreLinks(query, links, signals)
+	ranked := make([]Link, len(scored))
+	for i, s := range scored {
+		ranked[i] = s.link
+	}
+	return ranked
+}
+
+// scoreLinks puntúa y ordena los links como rankLinks, conservando la puntuación para
+// poder mezclarlos con los resultados de otros proveedores.
+func scoreLinks(query string, links []Link, signals rankSignals) []scoredLink {
+	query = strings.TrimSpace(query)
+
+	textHits := signals.textHits
+	bonus := make(map[int]int, len(textHits))
+	for i, hit := range textHits {
+		bonus[hit.ID] = textMatchBonus * (len(textHits) - i) / len(textHits)
+	}
+
+	scored := make([]scoredLink, 0, len(links))
+	for _, link := range links {
+		score := linkScore(query, link)
+		// Las señales se guardan por ID de link; aplicaciones y archivos tienen IDs propios.
+		if link.isSaved() {
+			score += bonus[link.ID]
+			if score > 0 {
+				score += frecencyBonus(signals.frecencies[link.ID])
+				score += pickBonus(signals.picks[link.ID])
+			}
+		}
+		if score > 0 {
+			scored = append(scored, scoredLink{link: link, score: score})
+		}
+	}
+
+	// Orden estable: a igual puntuación se mantiene el orden original (más recientes primero).
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	return scored
+}
+
+// linkScore puntúa un link para la consulta. El nombre (alias) pesa más que las palabras
+// clave de las aplicaciones, éstas más que la URL y ésta más que la descripción. La URL
+// exacta también cuenta como alias, igual que escribir el alias de una plantilla seguido
+// de argumentos ("jira PROJ-123"). En la URL y la descripción solo cuentan las
+// coincidencias de palabra (ver wordScore).
+func linkScore(query string, link Link) int {
+	name := fuzzy.Score(query, link.Name)
+	if name == fuzzy.ScoreExact || strings.EqualFold(query, link.URL) || isTemplateCall(query, link) {
+		name = fuzzy.ScoreExact + exactAliasBonus
+	}
+	url := wordScore(query, trimURLScheme(link.URL)) * 6 / 10
+	description := wordScore(query, link.Description) / 2
+
+	keywords := 0
+	for _, keyword := range link.Keywords {
+		keywords = max(keywords, fuzzy.Score(query, keyword)*7/10)
+	}
+
+	return max(name, url, description, keywords)
+}
+
+// wordScore es fuzzy.Score limitado a coincidencias al inicio del texto o de una palabra.
+// Se usa con la URL y la descripción, donde una subsecuencia dispersa o un error de tipeo
+// casi nunca es lo que se buscaba ("clima" no debe encontrar "Calendario de la empresa").
+func wordScore(query, target string) int {
+	score := fuzzy.Score(query, target)
+	if score < fuzzy.ScoreWordMatch {
+		return 0
+	}
+	return score
+}
+
+// isTemplateCall indica si la consulta es el alias de un link plantilla seguido de argumentos.
+func isTemplateCall(query string, link Link) bool {
+	args, ok := aliasArgs(query, link.Name)
+	return ok && len(args) > 0 && link.Type != ActionSnippet && templates.HasPlaceholders(link.URL)
+}
+
+// frecencyBonus convierte la frecencia de un link en puntos de ranking.
+func frecencyBonus(frecency float64) int {
+	if frecency <= 0 {
+		return 0
+	}
+	return min(int(frecencyWeight*math.Log2(1+frecency)), maxFrecencyBonus)
+}
+
+// pickBonus convierte las veces que se eligió un link para la consulta en puntos de ranking.
+func pickBonus(count int) int {
+	if count <= 0 {
+		return 0
+	}
+	return min(int(pickWeight*math.Log2(1+float64(count))), maxPickBonus)
+}
+
+// normalizeQuery normaliza una consulta para guardarla o buscarla en query_picks.
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// queryPrefixes devuelve los prefijos de la consulta normalizada, del más corto al más
+// largo, hasta maxLearnedPrefix caracteres.
+func queryPrefixes(query string) []string {
+	runes := []rune(normalizeQuery(query))
+	if len(runes) > maxLearnedPrefix {
+		runes = runes[:maxLearnedPrefix]
+	}
+
+	var prefixes []string
+	for i := 1; i <= len(runes); i++ {
+		prefix := strings.TrimSpace(string(runes[:i]))
+		if prefix != "" && (len(prefixes) == 0 || prefixes[len(prefixes)-1] != prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// queryPickKey devuelve la clave con la que se busca la consulta en query_picks: la
+// consulta normalizada, recortada igual que el prefijo más largo que guarda RecordQueryPick.
+func queryPickKey(query string) string {
+	prefixes := queryPrefixes(query)
+	if len(prefixes) == 0 {
+		return ""
+	}
+	return prefixes[len(prefixes)-1]
+}
+
+// decayFrecency aplica el decaimiento exponencial a una frecencia guardada en lastLaunched
+// (segundos Unix) hasta el instante now.
+func decayFrecency(frecency float64, lastLaunched int64, now time.Time) float64 {
+	if frecency <= 0 || lastLaunched <= 0 {
+		return 0
+	}
+	elapsed := now.Sub(time.Unix(lastLaunched, 0))
+	if elapsed <= 0 {
+		return frecency
+	}
+	return frecency * math.Pow(0.5, elapsed.Hours()/frecencyHalfLife.Hours())
+}
+
+// trimURLScheme quita el esquema y el "www." para que no cuenten en la coincidencia.
+func trimURLScheme(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	}
+	return strings.TrimPrefix(url, "www.")
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestLinkScoreIgnoresScatteredMatchesInURLAndDescription(t *testing.T) {
+	calendar := Link{ID: 1, Name: "cal", URL: "https://calendar.example.com", Description: "Calendario de la empresa"}
+	tests := []struct {
+		query string
+		match bool
+	}{
+		{"clima", false},    // Subsecuencia dispersa en la descripción.
+		{"cmpl", false},     // Subsecuencia dispersa en la URL.
+		{"empresa", true},   // Palabra de la descripción.
+		{"calendar", true},  // Prefijo de la URL.
+		{"calendari", true}, // Prefijo de una palabra de la descripción.
+		{"cl", true},        // Subsecuencia en el alias: el nombre sí la admite.
+	}
+	for _, tt := range tests {
+		if got := linkScore(tt.query, calendar) > 0; got != tt.match {
+			t.Errorf("linkScore(%q) > 0 = %v, se esperaba %v", tt.query, got, tt.match)
+		}
+	}
+}
+
+func TestQueryPickKeyMatchesLongestStoredPrefix(t *testing.T) {
+	tests := map[string]string{
+		"  Jira  ":                         "jira",
+		"abrir   el tablero":               "abrir el tablero",
+		"documentación del proyecto nuevo": "documentación del pr",
+		"abrir el tablero de  sprint":      "abrir el tablero de", // El espacio final se recorta.
+		"   ":                              "",
+	}
+	for query, want := range tests {
+		if got := queryPickKey(query); got != want {
+			t.Errorf("queryPickKey(%q) = %q, se esperaba %q", query, got, want)
+		}
+	}
+}
+
+func TestRankLinksPutsExactAliasFirst(t *testing.T) {
+	alias := Link{ID: 1, Name: "cal", URL: "https://calendar.example.com"}
+	popular := Link{ID: 2, Name: "calculadora", URL: "https://calc.example.com"}
+	signals := rankSignals{
+		textHits:   []Link{popular},
+		frecencies: map[int]float64{2: 1e6},
+		picks:      map[int]int{2: 1000},
+	}
+
+	ranked := rankLinks("CAL", []Link{popular, alias}, signals)
+	if len(ranked) != 2 || ranked[0].ID != alias.ID {
+		t.Fatalf("rankLinks(\"CAL\") = %v, se esperaba el alias exacto primero", ranked)
+	}
+}
+
+func TestTemplateCallWithMultiWordAlias(t *testing.T) {
+	wiki := Link{ID: 1, Name: "mi wiki", URL: "https://wiki.example.com/search?q={1:inicio}"}
+	tests := []struct {
+		query string
+		call  bool
+		url   string
+	}{
+		{"mi wiki golang", true, "https://wiki.example.com/search?q=golang"},
+		{"MI  Wiki go tips", true, "https://wiki.example.com/search?q=go+tips"},
+		{"mi wiki", false, "https://wiki.example.com/search?q=inicio"},
+		{"mi golang", false, "https://wiki.example.com/search?q=inicio"},       // Solo la primera palabra del alias.
+		{"mi wikipedia go", false, "https://wiki.example.com/search?q=inicio"}, // No corta dentro de una palabra.
+	}
+	for _, tt := range tests {
+		if got := isTemplateCall(tt.query, wiki); got != tt.call {
+			t.Errorf("isTemplateCall(%q) = %v, se esperaba %v", tt.query, got, tt.call)
+		}
+		url, err := expandLinkURL(wiki, tt.query)
+		if err != nil {
+			t.Fatalf("expandLinkURL(%q): %v", tt.query, err)
+		}
+		if url != tt.url {
+			t.Errorf("expandLinkURL(%q) = %q, se esperaba %q", tt.query, url, tt.url)
+		}
+	}
+}
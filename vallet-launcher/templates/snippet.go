@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CursorPlaceholder marca dónde debe quedar el cursor tras pegar un snippet.
+const CursorPlaceholder = "{cursor}"
+
+// SnippetVars son los valores disponibles para las variables de un snippet.
+type SnippetVars struct {
+	Now       time.Time // Fecha y hora para {date}, {time} y {datetime}.
+	Clipboard string    // Contenido del portapapeles antes de pegar, para {clipboard}.
+}
+
+// dateTokens traduce los formatos legibles de {date:...} al formato de Go.
+var dateTokens = strings.NewReplacer(
+	"YYYY", "2006", "YY", "06",
+	"MM", "01", "DD", "02",
+	"HH", "15", "mm", "04", "ss", "05",
+)
+
+// ExpandSnippet sustituye las variables de un snippet:
+//
+//	{date}      fecha actual (2006-01-02)
+//	{time}      hora actual (15:04)
+//	{datetime}  fecha y hora (2006-01-02 15:04)
+//	{date:DD/MM/YYYY}  fecha con formato (YYYY, YY, MM, DD, HH, mm, ss)
+//	{clipboard} contenido del portapapeles
+//	{cursor}    posición del cursor tras pegar
+//
+// Las llaves que no son una variable conocida se dejan tal cual. Devuelve el texto y
+// cuántos caracteres hay después de {cursor} (0 si no hay), para mover el cursor hacia
+// atrás tras pegar.
+func ExpandSnippet(text string, vars SnippetVars) (string, int) {
+	var b strings.Builder
+	cursor := -1
+
+	rest := text
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			b.WriteString(rest)
+			break
+		}
+
+		b.WriteString(rest[:open])
+		name := rest[open+1 : open+end]
+		if strings.ContainsAny(name, "{\n") {
+			// Llave literal (ej: JSON): se copia y se sigue buscando después de ella.
+			b.WriteByte('{')
+			rest = rest[open+1:]
+			continue
+		}
+		rest = rest[open+end+1:]
+
+		if name == "cursor" {
+			if cursor < 0 {
+				cursor = b.Len()
+			}
+			continue
+		}
+		if value, ok := snippetVariable(name, vars); ok {
+			b.WriteString(value)
+		} else {
+			b.WriteString("{" + name + "}")
+		}
+	}
+
+	expanded := b.String()
+	if cursor < 0 {
+		return expanded, 0
+	}
+	return expanded, utf8.RuneCountInString(expanded[cursor:])
+}
+
+// snippetVariable devuelve el valor de una variable de snippet.
+func snippetVariable(name string, vars SnippetVars) (string, bool) {
+	switch name {
+	case "date":
+		return vars.Now.Format("2006-01-02"), true
+	case "time":
+		return vars.Now.Format("15:04"), true
+	case "datetime":
+		return vars.Now.Format("2006-01-02 15:04"), true
+	case "clipboard":
+		return vars.Clipboard, true
+	}
+	if layout, ok := strings.CutPrefix(name, "date:"); ok && layout != "" {
+		return vars.Now.Format(dateTokens.Replace(layout)), true
+	}
+	return "", false
+}
@@ -0,0 +1,179 @@
+package templates
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// QueryPlaceholder es el nombre del marcador que recibe todos los argumentos juntos.
+const QueryPlaceholder = "query"
+
+// maxArgIndex limita los marcadores numerados ({1} ... {20}).
+const maxArgIndex = 20
+
+// placeholder es un marcador del tipo {1}, {1:main}, {query} o {query:default}.
+type placeholder struct {
+	index      int    // Posición del argumento (1..N) o 0 para {query}.
+	def        string // Valor por defecto si no se pasa el argumento.
+	hasDefault bool   // Indica si se especificó un valor por defecto.
+	inQuery    bool   // El marcador está después del '?' de la URL.
+}
+
+// segment es un trozo literal o un marcador de la plantilla.
+type segment struct {
+	literal     string
+	placeholder *placeholder
+}
+
+// Template es la URL (o comando) de un link con marcadores a sustituir, por ejemplo
+// "https://jira.example.com/browse/{1}" o "https://github.com/{1}/tree/{2:main}".
+type Template struct {
+	raw      string
+	segments []segment
+	maxIndex int  // Mayor índice numerado usado en la plantilla.
+	isURL    bool // Si es una URL, los valores se codifican al sustituirlos.
+}
+
+// HasPlaceholders indica si el texto parece una plantilla (contiene llaves).
+func HasPlaceholders(s string) bool {
+	return strings.ContainsAny(s, "{}")
+}
+
+// Parse analiza una plantilla y valida sus marcadores.
+func Parse(raw string) (*Template, error) {
+	t := &Template{raw: raw, isURL: strings.Contains(raw, "://")}
+	afterQuestion := false
+
+	rest := raw
+	for rest != "" {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			t.addLiteral(rest)
+			break
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("plantilla inválida: '}' sin '{' correspondiente en %q", raw)
+		}
+
+		literal := rest[:open]
+		t.addLiteral(literal)
+		if strings.Contains(literal, "?") {
+			afterQuestion = true
+		}
+
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return nil, fmt.Errorf("plantilla inválida: '{' sin cerrar en %q", raw)
+		}
+
+		p, err := parsePlaceholder(rest[open+1 : open+1+end])
+		if err != nil {
+			return nil, err
+		}
+		p.inQuery = afterQuestion
+		if p.index > t.maxIndex {
+			t.maxIndex = p.index
+		}
+		t.segments = append(t.segments, segment{placeholder: p})
+
+		rest = rest[open+1+end+1:]
+	}
+
+	return t, nil
+}
+
+// parsePlaceholder interpreta el contenido entre llaves: "1", "1:main", "query".
+func parsePlaceholder(body string) (*placeholder, error) {
+	name, def, hasDefault := strings.Cut(body, ":")
+	name = strings.TrimSpace(name)
+
+	p := &placeholder{def: def, hasDefault: hasDefault}
+	if name == QueryPlaceholder {
+		return p, nil
+	}
+
+	index, err := strconv.Atoi(name)
+	if err != nil || index < 1 || index > maxArgIndex {
+		return nil, fmt.Errorf("marcador inválido {%s}: usa {query} o un número entre 1 y %d", body, maxArgIndex)
+	}
+	p.index = index
+	return p, nil
+}
+
+func (t *Template) addLiteral(s string) {
+	if s != "" {
+		t.segments = append(t.segments, segment{literal: s})
+	}
+}
+
+// Expand sustituye los marcadores con los argumentos. {query} recibe todos los argumentos
+// separados por espacios y el marcador numerado más alto recibe también los argumentos
+// sobrantes, así "g foo bar" con "{1}" busca "foo bar". En URLs los valores se codifican
+// (como ruta antes del '?' y como parámetro después).
+func (t *Template) Expand(args []string) (string, error) {
+	var b strings.Builder
+	for _, seg := range t.segments {
+		if seg.placeholder == nil {
+			b.WriteString(seg.literal)
+			continue
+		}
+
+		value, err := t.value(seg.placeholder, args)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(t.escape(seg.placeholder, value))
+	}
+	return b.String(), nil
+}
+
+// value obtiene el valor de un marcador a partir de los argumentos o su valor por defecto.
+func (t *Template) value(p *placeholder, args []string) (string, error) {
+	var value string
+	switch {
+	case p.index == 0:
+		value = strings.Join(args, " ")
+	case p.index == t.maxIndex && len(args) > p.index:
+		value = strings.Join(args[p.index-1:], " ")
+	case p.index <= len(args):
+		value = args[p.index-1]
+	}
+
+	if value != "" {
+		return value, nil
+	}
+	if p.hasDefault {
+		return p.def, nil
+	}
+	if p.index == 0 {
+		return "", fmt.Errorf("falta el texto para {%s}", QueryPlaceholder)
+	}
+	return "", fmt.Errorf("falta el argumento {%d}", p.index)
+}
+
+func (t *Template) escape(p *placeholder, value string) string {
+	if !t.isURL {
+		return value
+	}
+	if p.inQuery {
+		return url.QueryEscape(value)
+	}
+	return url.PathEscape(value)
+}
+
+// HasQuery indica si la plantilla contiene el marcador {query}.
+func (t *Template) HasQuery() bool {
+	for _, seg := range t.segments {
+		if seg.placeholder != nil && seg.placeholder.index == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// String devuelve la plantilla original.
+func (t *Template) String() string {
+	return t.raw
+}
@@ -0,0 +1,97 @@
+package templates
+
+import "testing"
+
+func TestParseRejectsMalformedTemplates(t *testing.T) {
+	for _, raw := range []string{
+		"https://example.com/}",
+		"https://example.com/{1",
+		"https://example.com/{1{2}}",
+		"https://example.com/{}",
+		"https://example.com/{0}",
+		"https://example.com/{21}",
+		"https://example.com/{nombre}",
+		"https://example.com/{-1}",
+	} {
+		if _, err := Parse(raw); err == nil {
+			t.Errorf("Parse(%q) no devolvió error", raw)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		template string
+		args     []string
+		want     string
+	}{
+		{"https://jira.example.com/browse/{1}", []string{"PROJ-123"}, "https://jira.example.com/browse/PROJ-123"},
+		{"https://github.com/{1}/tree/{2:main}", []string{"vallet"}, "https://github.com/vallet/tree/main"},
+		{"https://github.com/{1}/tree/{2:main}", []string{"vallet", "dev"}, "https://github.com/vallet/tree/dev"},
+		{"https://example.com/{1:}", nil, "https://example.com/"},
+		{"https://example.com/?q={query:hola mundo}", nil, "https://example.com/?q=hola+mundo"}, // Los valores por defecto también se codifican.
+		{"https://www.google.com/search?q={query}", []string{"go", "fmt"}, "https://www.google.com/search?q=go+fmt"},
+		{"https://example.com/{1}/{2}", []string{"a", "b", "c"}, "https://example.com/a/b%20c"}, // Sobrantes al último.
+		{"https://example.com/{2}/{1}", []string{"a", "b"}, "https://example.com/b/a"},
+		{"https://example.com/q/{query}", []string{"x"}, "https://example.com/q/x"},
+		// Antes del '?' se codifica como ruta y después como parámetro.
+		{"https://example.com/{1}?q={2}", []string{"a/b c", "c&d=e"}, "https://example.com/a%2Fb%20c?q=c%26d%3De"},
+		// Sin "://" no es una URL: los valores se sustituyen sin codificar.
+		{"code ~/proyectos/{1}", []string{"a b"}, "code ~/proyectos/a b"},
+		{"echo {query}", []string{"a&b", "c"}, "echo a&b c"},
+	}
+	for _, tt := range tests {
+		tmpl, err := Parse(tt.template)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.template, err)
+			continue
+		}
+		got, err := tmpl.Expand(tt.args)
+		if err != nil {
+			t.Errorf("Expand(%q, %q): %v", tt.template, tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Expand(%q, %q) = %q, se esperaba %q", tt.template, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestExpandMissingArguments(t *testing.T) {
+	tests := []struct {
+		template string
+		args     []string
+	}{
+		{"https://jira.example.com/browse/{1}", nil},
+		{"https://github.com/{1}/tree/{2}", []string{"vallet"}},
+		{"https://www.google.com/search?q={query}", nil},
+		{"https://example.com/{1}", []string{""}},
+	}
+	for _, tt := range tests {
+		tmpl, err := Parse(tt.template)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.template, err)
+		}
+		if got, err := tmpl.Expand(tt.args); err == nil {
+			t.Errorf("Expand(%q, %q) = %q, se esperaba un error", tt.template, tt.args, got)
+		}
+	}
+}
+
+func TestHasQuery(t *testing.T) {
+	tests := map[string]bool{
+		"https://www.google.com/search?q={query}": true,
+		"https://example.com/?q={query:hola}":     true,
+		"https://example.com/{1}":                 false,
+		"https://example.com/":                    false,
+	}
+	for raw, want := range tests {
+		tmpl, err := Parse(raw)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", raw, err)
+		}
+		if got := tmpl.HasQuery(); got != want {
+			t.Errorf("HasQuery(%q) = %v, se esperaba %v", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Session es el tipo de sesión gráfica de Linux.
+type Session string
+
+const (
+	SessionX11     Session = "x11"
+	SessionWayland Session = "wayland"
+	SessionUnknown Session = ""
+)
+
+// SystemProbe da acceso al entorno para elegir el backend de teclado. Permite probar la
+// detección sin una pantalla real.
+type SystemProbe interface {
+	Getenv(key string) string
+	LookPath(file string) (string, error)
+}
+
+// osProbe consulta el entorno real del proceso.
+type osProbe struct{}
+
+func (osProbe) Getenv(key string) string             { return os.Getenv(key) }
+func (osProbe) LookPath(file string) (string, error) { return exec.LookPath(file) }
+
+// DetectSession averigua si la sesión gráfica es X11 o Wayland.
+func DetectSession(p SystemProbe) Session {
+	switch strings.ToLower(p.Getenv("XDG_SESSION_TYPE")) {
+	case "wayland":
+		return SessionWayland
+	case "x11":
+		return SessionX11
+	}
+	if p.Getenv("WAYLAND_DISPLAY") != "" {
+		return SessionWayland
+	}
+	if p.Getenv("DISPLAY") != "" {
+		return SessionX11
+	}
+	return SessionUnknown
+}
+
+// InputBackend construye las líneas de comandos de una herramienta externa que simula el
+// teclado (xdotool, ydotool o wtype).
+type InputBackend interface {
+	// Name es el nombre del ejecutable.
+	Name() string
+	// PasteCommand pulsa el atajo de pegar de la estrategia.
+	PasteCommand(s Strategy) ([]string, error)
+	// TypeCommand escribe el texto carácter a carácter.
+	TypeCommand(text string) []string
+	// LeftCommand pulsa la flecha izquierda n veces (n > 0).
+	LeftCommand(n int) []string
+}
+
+// xdotoolBackend funciona en X11 (y solo con ventanas X en Wayland).
+type xdotoolBackend struct{}
+
+func (xdotoolBackend) Name() string { return "xdotool" }
+
+func (xdotoolBackend) PasteCommand(s Strategy) ([]string, error) {
+	keys := map[Strategy]string{
+		StrategyCtrlV:       "ctrl+v",
+		StrategyCtrlShiftV:  "ctrl+shift+v",
+		StrategyShiftInsert: "shift+Insert",
+	}
+	combo, ok := keys[s]
+	if !ok {
+		return nil, fmt.Errorf("la estrategia %s no usa un atajo de pegar", s)
+	}
+	return []string{"xdotool", "key", "--clearmodifiers", combo}, nil
+}
+
+func (xdotoolBackend) TypeCommand(text string) []string {
+	return []string{"xdotool", "type", "--clearmodifiers", "--delay", "0", "--", text}
+}
+
+func (xdotoolBackend) LeftCommand(n int) []string {
+	return []string{"xdotool", "key", "--clearmodifiers", "--repeat", strconv.Itoa(n), "Left"}
+}
+
+// wtypeBackend funciona en compositores Wayland con el protocolo virtual-keyboard
+// (Sway, Hyprland y otros basados en wlroots).
+type wtypeBackend struct{}
+
+func (wtypeBackend) Name() string { return "wtype" }
+
+func (wtypeBackend) PasteCommand(s Strategy) ([]string, error) {
+	switch s {
+	case StrategyCtrlV:
+		return []string{"wtype", "-M", "ctrl", "v", "-m", "ctrl"}, nil
+	case StrategyCtrlShiftV:
+		return []string{"wtype", "-M", "ctrl", "-M", "shift", "v", "-m", "shift", "-m", "ctrl"}, nil
+	case StrategyShiftInsert:
+		return []string{"wtype", "-M", "shift", "-k", "Insert", "-m", "shift"}, nil
+	}
+	return nil, fmt.Errorf("la estrategia %s no usa un atajo de pegar", s)
+}
+
+func (wtypeBackend) TypeCommand(text string) []string {
+	return []string{"wtype", "--", text}
+}
+
+func (wtypeBackend) LeftCommand(n int) []string {
+	argv := []string{"wtype"}
+	for i := 0; i < n; i++ {
+		argv = append(argv, "-k", "Left")
+	}
+	return argv
+}
+
+// Códigos de tecla de Linux (input-event-codes.h) que usa ydotool.
+const (
+	keyLeftCtrl  = 29
+	keyLeftShift = 42
+	keyV         = 47
+	keyInsert    = 110
+	keyLeft      = 105
+)
+
+// ydotoolBackend funciona en X11 y en cualquier compositor Wayland a través de uinput;
+// necesita que el servicio ydotoold esté en marcha (ydotool 1.0 o posterior).
+type ydotoolBackend struct{}
+
+func (ydotoolBackend) Name() string { return "ydotool" }
+
+func (ydotoolBackend) PasteCommand(s Strategy) ([]string, error) {
+	var keys []int
+	switch s {
+	case StrategyCtrlV:
+		keys = []int{keyLeftCtrl, keyV}
+	case StrategyCtrlShiftV:
+		keys = []int{keyLeftCtrl, keyLeftShift, keyV}
+	case StrategyShiftInsert:
+		keys = []int{keyLeftShift, keyInsert}
+	default:
+		return nil, fmt.Errorf("la estrategia %s no usa un atajo de pegar", s)
+	}
+
+	// Pulsar las teclas en orden y soltarlas en orden inverso.
+	argv := []string{"ydotool", "key"}
+	for _, k := range keys {
+		argv = append(argv, fmt.Sprintf("%d:1", k))
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		argv = append(argv, fmt.Sprintf("%d:0", keys[i]))
+	}
+	return argv, nil
+}
+
+func (ydotoolBackend) TypeCommand(text string) []string {
+	return []string{"ydotool", "type", "--", text}
+}
+
+func (ydotoolBackend) LeftCommand(n int) []string {
+	argv := []string{"ydotool", "key"}
+	for i := 0; i < n; i++ {
+		argv = append(argv, fmt.Sprintf("%d:1", keyLeft), fmt.Sprintf("%d:0", keyLeft))
+	}
+	return argv
+}
+
+// ChooseBackends devuelve las herramientas instaladas que sirven para la sesión, de la más
+// a la menos adecuada. En Wayland no se usa xdotool, que solo llega a las ventanas X.
+func ChooseBackends(p SystemProbe) []InputBackend {
+	var candidates []InputBackend
+	switch DetectSession(p) {
+	case SessionWayland:
+		candidates = []InputBackend{wtypeBackend{}, ydotoolBackend{}}
+	case SessionX11:
+		candidates = []InputBackend{xdotoolBackend{}, ydotoolBackend{}}
+	default:
+		candidates = []InputBackend{ydotoolBackend{}}
+	}
+
+	var available []InputBackend
+	for _, b := range candidates {
+		if _, err := p.LookPath(b.Name()); err == nil {
+			available = append(available, b)
+		}
+	}
+	return available
+}
+
+// CommandRunner ejecuta una línea de comandos y espera a que termine.
+type CommandRunner func(argv []string) error
+
+// runCommand ejecuta argv e incluye su salida de error en el error.
+func runCommand(argv []string) error {
+	out, err := exec.Command(argv[0], argv[1:]...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w: %s", argv[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", argv[0], err)
+	}
+	return nil
+}
+
+// commandKeyboard simula el teclado ejecutando una herramienta externa.
+type commandKeyboard struct {
+	backend InputBackend
+	run     CommandRunner
+}
+
+// NewCommandKeyboard crea un teclado que ejecuta los comandos del backend con run.
+func NewCommandKeyboard(backend InputBackend, run CommandRunner) Keyboard {
+	return commandKeyboard{backend: backend, run: run}
+}
+
+func (k commandKeyboard) PressPaste(s Strategy) error {
+	argv, err := k.backend.PasteCommand(s)
+	if err != nil {
+		return err
+	}
+	return k.run(argv)
+}
+
+func (k commandKeyboard) TypeText(text string) error {
+	if text == "" {
+		return nil
+	}
+	return k.run(k.backend.TypeCommand(text))
+}
+
+func (k commandKeyboard) MoveCursorLeft(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	return k.run(k.backend.LeftCommand(n))
+}
+
+// fallbackKeyboard prueba cada teclado en orden hasta que uno funciona (ej: ydotool
+// instalado pero sin el servicio ydotoold, y luego keybd_event).
+type fallbackKeyboard []Keyboard
+
+func (f fallbackKeyboard) try(op func(Keyboard) error) error {
+	var errs []error
+	for _, k := range f {
+		err := op(k)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (f fallbackKeyboard) PressPaste(s Strategy) error {
+	return f.try(func(k Keyboard) error { return k.PressPaste(s) })
+}
+
+func (f fallbackKeyboard) TypeText(text string) error {
+	return f.try(func(k Keyboard) error { return k.TypeText(text) })
+}
+
+func (f fallbackKeyboard) MoveCursorLeft(n int) error {
+	return f.try(func(k Keyboard) error { return k.MoveCursorLeft(n) })
+}
+
+// NewFallbackKeyboard crea un teclado que usa el primero de keyboards que funcione.
+func NewFallbackKeyboard(keyboards ...Keyboard) Keyboard {
+	return fallbackKeyboard(keyboards)
+}
+
+// BackendKeyboard crea un teclado que prueba las herramientas de ChooseBackends en orden,
+// ejecutándolas con run, y por último last. Devuelve también las herramientas elegidas; si
+// no hay ninguna, el teclado es last.
+func BackendKeyboard(p SystemProbe, run CommandRunner, last Keyboard) (Keyboard, []InputBackend) {
+	backends := ChooseBackends(p)
+	if len(backends) == 0 {
+		return last, nil
+	}
+	keyboards := make([]Keyboard, 0, len(backends)+1)
+	for _, b := range backends {
+		keyboards = append(keyboards, NewCommandKeyboard(b, run))
+	}
+	return NewFallbackKeyboard(append(keyboards, last)...), backends
+}
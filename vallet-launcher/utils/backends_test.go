@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeProbe simula el entorno: variables y herramientas instaladas.
+type fakeProbe struct {
+	env   map[string]string
+	tools []string
+}
+
+func (p fakeProbe) Getenv(key string) string { return p.env[key] }
+
+func (p fakeProbe) LookPath(file string) (string, error) {
+	for _, t := range p.tools {
+		if t == file {
+			return "/usr/bin/" + file, nil
+		}
+	}
+	return "", errors.New("no encontrado")
+}
+
+// fakeRunner registra los comandos ejecutados y falla con las herramientas de broken.
+type fakeRunner struct {
+	broken []string
+	calls  [][]string
+}
+
+func (r *fakeRunner) run(argv []string) error {
+	r.calls = append(r.calls, argv)
+	for _, b := range r.broken {
+		if argv[0] == b {
+			return errors.New(b + " no funciona")
+		}
+	}
+	return nil
+}
+
+func backendNames(backends []InputBackend) []string {
+	var names []string
+	for _, b := range backends {
+		names = append(names, b.Name())
+	}
+	return names
+}
+
+func TestDetectSession(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Session
+	}{
+		{"XDG wayland", map[string]string{"XDG_SESSION_TYPE": "wayland", "DISPLAY": ":0"}, SessionWayland},
+		{"XDG x11", map[string]string{"XDG_SESSION_TYPE": "X11", "WAYLAND_DISPLAY": "wayland-0"}, SessionX11},
+		{"solo WAYLAND_DISPLAY", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, SessionWayland},
+		{"solo DISPLAY", map[string]string{"DISPLAY": ":0"}, SessionX11},
+		{"XDG tty sin pantallas", map[string]string{"XDG_SESSION_TYPE": "tty"}, SessionUnknown},
+		{"sin variables", nil, SessionUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectSession(fakeProbe{env: tt.env}); got != tt.want {
+				t.Errorf("DetectSession() = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChooseBackends(t *testing.T) {
+	x11 := map[string]string{"DISPLAY": ":0"}
+	wayland := map[string]string{"WAYLAND_DISPLAY": "wayland-0"}
+	all := []string{"xdotool", "ydotool", "wtype"}
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		tools []string
+		want  []string
+	}{
+		{"X11 con todo", x11, all, []string{"xdotool", "ydotool"}},
+		{"X11 solo ydotool", x11, []string{"ydotool", "wtype"}, []string{"ydotool"}},
+		{"Wayland con todo", wayland, all, []string{"wtype", "ydotool"}},
+		{"Wayland sin wtype", wayland, []string{"xdotool", "ydotool"}, []string{"ydotool"}},
+		{"Wayland solo xdotool", wayland, []string{"xdotool"}, nil},
+		{"sin sesión", nil, all, []string{"ydotool"}},
+		{"sin herramientas", x11, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := backendNames(ChooseBackends(fakeProbe{env: tt.env, tools: tt.tools}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChooseBackends() = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendPasteCommands(t *testing.T) {
+	tests := []struct {
+		backend  InputBackend
+		strategy Strategy
+		want     []string
+	}{
+		{xdotoolBackend{}, StrategyCtrlV, []string{"xdotool", "key", "--clearmodifiers", "ctrl+v"}},
+		{xdotoolBackend{}, StrategyCtrlShiftV, []string{"xdotool", "key", "--clearmodifiers", "ctrl+shift+v"}},
+		{xdotoolBackend{}, StrategyShiftInsert, []string{"xdotool", "key", "--clearmodifiers", "shift+Insert"}},
+		{wtypeBackend{}, StrategyCtrlV, []string{"wtype", "-M", "ctrl", "v", "-m", "ctrl"}},
+		{wtypeBackend{}, StrategyCtrlShiftV, []string{"wtype", "-M", "ctrl", "-M", "shift", "v", "-m", "shift", "-m", "ctrl"}},
+		{wtypeBackend{}, StrategyShiftInsert, []string{"wtype", "-M", "shift", "-k", "Insert", "-m", "shift"}},
+		{ydotoolBackend{}, StrategyCtrlV, []string{"ydotool", "key", "29:1", "47:1", "47:0", "29:0"}},
+		{ydotoolBackend{}, StrategyCtrlShiftV, []string{"ydotool", "key", "29:1", "42:1", "47:1", "47:0", "42:0", "29:0"}},
+		{ydotoolBackend{}, StrategyShiftInsert, []string{"ydotool", "key", "42:1", "110:1", "110:0", "42:0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend.Name()+"/"+string(tt.strategy), func(t *testing.T) {
+			got, err := tt.backend.PasteCommand(tt.strategy)
+			if err != nil {
+				t.Fatalf("PasteCommand: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PasteCommand() = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, b := range []InputBackend{xdotoolBackend{}, wtypeBackend{}, ydotoolBackend{}} {
+		if _, err := b.PasteCommand(StrategyType); err == nil {
+			t.Errorf("%s aceptó la estrategia type como atajo de pegar", b.Name())
+		}
+	}
+}
+
+func TestBackendTypeAndLeftCommands(t *testing.T) {
+	tests := []struct {
+		backend  InputBackend
+		wantType []string
+		wantLeft []string
+	}{
+		{xdotoolBackend{}, []string{"xdotool", "type", "--clearmodifiers", "--delay", "0", "--", "-ñ ü"}, []string{"xdotool", "key", "--clearmodifiers", "--repeat", "2", "Left"}},
+		{wtypeBackend{}, []string{"wtype", "--", "-ñ ü"}, []string{"wtype", "-k", "Left", "-k", "Left"}},
+		{ydotoolBackend{}, []string{"ydotool", "type", "--", "-ñ ü"}, []string{"ydotool", "key", "105:1", "105:0", "105:1", "105:0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend.Name(), func(t *testing.T) {
+			if got := tt.backend.TypeCommand("-ñ ü"); !reflect.DeepEqual(got, tt.wantType) {
+				t.Errorf("TypeCommand() = %q, se esperaba %q", got, tt.wantType)
+			}
+			if got := tt.backend.LeftCommand(2); !reflect.DeepEqual(got, tt.wantLeft) {
+				t.Errorf("LeftCommand() = %q, se esperaba %q", got, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestBackendKeyboardFallback(t *testing.T) {
+	x11 := fakeProbe{env: map[string]string{"DISPLAY": ":0"}, tools: []string{"xdotool", "ydotool"}}
+
+	t.Run("usa el primero que funciona", func(t *testing.T) {
+		runner := &fakeRunner{broken: []string{"xdotool"}}
+		last := &fakeKeyboard{}
+		kb, backends := BackendKeyboard(x11, runner.run, last)
+		if got := backendNames(backends); !reflect.DeepEqual(got, []string{"xdotool", "ydotool"}) {
+			t.Fatalf("backends = %v", got)
+		}
+		if err := kb.PressPaste(StrategyCtrlV); err != nil {
+			t.Fatalf("PressPaste: %v", err)
+		}
+		if len(runner.calls) != 2 || runner.calls[0][0] != "xdotool" || runner.calls[1][0] != "ydotool" {
+			t.Errorf("comandos = %q, se esperaba xdotool y después ydotool", runner.calls)
+		}
+		if len(last.pasted) != 0 {
+			t.Error("se usó el último recurso aunque ydotool funcionó")
+		}
+	})
+
+	t.Run("último recurso", func(t *testing.T) {
+		runner := &fakeRunner{broken: []string{"xdotool", "ydotool"}}
+		last := &fakeKeyboard{}
+		kb, _ := BackendKeyboard(x11, runner.run, last)
+		if err := kb.MoveCursorLeft(3); err != nil {
+			t.Fatalf("MoveCursorLeft: %v", err)
+		}
+		if last.left != 3 {
+			t.Errorf("el último recurso movió el cursor %d veces, se esperaban 3", last.left)
+		}
+	})
+
+	t.Run("todos fallan", func(t *testing.T) {
+		runner := &fakeRunner{broken: []string{"xdotool", "ydotool"}}
+		last := &fakeKeyboard{pasteErr: errors.New("keybd_event no funciona")}
+		kb, _ := BackendKeyboard(x11, runner.run, last)
+		if err := kb.PressPaste(StrategyCtrlV); err == nil {
+			t.Error("PressPaste no devolvió error con todos los teclados rotos")
+		}
+	})
+
+	t.Run("sin herramientas", func(t *testing.T) {
+		last := &fakeKeyboard{}
+		kb, backends := BackendKeyboard(fakeProbe{env: map[string]string{"DISPLAY": ":0"}}, (&fakeRunner{}).run, last)
+		if len(backends) != 0 || kb != Keyboard(last) {
+			t.Errorf("BackendKeyboard() = %v, %v; se esperaba solo el último recurso", kb, backendNames(backends))
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Strategy es la forma de introducir texto en la aplicación activa.
+type Strategy string
+
+const (
+	StrategyCtrlV       Strategy = "ctrl_v"       // Portapapeles + Ctrl+V (la mayoría de aplicaciones).
+	StrategyCtrlShiftV  Strategy = "ctrl_shift_v" // Portapapeles + Ctrl+Shift+V (terminales de Linux).
+	StrategyShiftInsert Strategy = "shift_insert" // Portapapeles + Shift+Insert (xterm, PuTTY, mintty).
+	StrategyType        Strategy = "type"         // Escribir carácter a carácter (escritorios remotos, apps que bloquean pegar).
+)
+
+// Strategies son las estrategias disponibles, en el orden en que se ofrecen al usuario.
+var Strategies = []Strategy{StrategyCtrlV, StrategyCtrlShiftV, StrategyShiftInsert, StrategyType}
+
+// ParseStrategy valida el nombre de una estrategia.
+func ParseStrategy(name string) (Strategy, error) {
+	name = strings.TrimSpace(name)
+	s := Strategy(strings.ToLower(name))
+	for _, known := range Strategies {
+		if s == known {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("estrategia de pegado desconocida: %q", name)
+}
+
+// usesClipboard indica si la estrategia pega a través del portapapeles.
+func (s Strategy) usesClipboard() bool {
+	return s != StrategyType
+}
+
+// Keyboard simula el teclado sobre la aplicación activa.
+type Keyboard interface {
+	// PressPaste pulsa el atajo de pegar de la estrategia (Ctrl+V, Ctrl+Shift+V o Shift+Insert).
+	PressPaste(s Strategy) error
+	// TypeText escribe el texto carácter a carácter, sin usar el portapapeles.
+	TypeText(text string) error
+	// MoveCursorLeft pulsa la flecha izquierda n veces.
+	MoveCursorLeft(n int) error
+}
+
+// WindowInfo identifica la ventana que tiene el foco.
+type WindowInfo struct {
+	Class   string // Clase de la ventana (ej: "ConsoleWindowClass", "kitty").
+	Process string // Nombre del ejecutable (ej: "WindowsTerminal.exe", "konsole").
+}
+
+func (w WindowInfo) String() string {
+	return fmt.Sprintf("%s (%s)", w.Process, w.Class)
+}
+
+// InjectionRule elige una estrategia para las ventanas cuya clase o proceso coincide con
+// el patrón.
+type InjectionRule struct {
+	Pattern  string   // Patrón glob sin distinguir mayúsculas (ej: "*terminal*", "putty.exe").
+	Strategy Strategy // Estrategia a usar en esas ventanas.
+}
+
+// ParseInjectionRules lee las reglas por aplicación, una por línea con el formato
+// "patrón = estrategia". Las líneas vacías y las que empiezan con '#' se ignoran. Las
+// reglas inválidas se omiten y se informan en el error, sin descartar las demás.
+func ParseInjectionRules(value string) ([]InjectionRule, error) {
+	var rules []InjectionRule
+	var errs []error
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern, name, ok := strings.Cut(line, "=")
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if !ok || pattern == "" {
+			errs = append(errs, fmt.Errorf("regla sin formato 'patrón = estrategia': %q", line))
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("patrón inválido %q", pattern))
+			continue
+		}
+		strategy, err := ParseStrategy(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rules = append(rules, InjectionRule{Pattern: pattern, Strategy: strategy})
+	}
+	return rules, errors.Join(errs...)
+}
+
+// Matches indica si la regla se aplica a la ventana. El proceso se compara también sin
+// la extensión .exe, así "putty" sirve en cualquier sistema.
+func (r InjectionRule) Matches(w WindowInfo) bool {
+	process := strings.ToLower(w.Process)
+	candidates := []string{strings.ToLower(w.Class), process, strings.TrimSuffix(process, ".exe")}
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		if ok, _ := path.Match(r.Pattern, c); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ChooseStrategy devuelve la estrategia de la primera regla que coincide con la ventana,
+// o la estrategia por defecto si ninguna coincide.
+func ChooseStrategy(rules []InjectionRule, w WindowInfo, fallback Strategy) Strategy {
+	for _, r := range rules {
+		if r.Matches(w) {
+			return r.Strategy
+		}
+	}
+	return fallback
+}
@@ -1,37 +1,88 @@
 package utils
 
 import (
-	"time"
+	"fmt"
+	"sync"
 
-	"github.com/atotto/clipboard"
 	"github.com/micmonay/keybd_event"
 )
 
-// PasteText simula la acción de pegar texto (Ctrl+V) después de copiarlo al portapapeles.
-func PasteText(text string) error {
-	// 1. Escribir el texto en el portapapeles del sistema.
-	if err := clipboard.WriteAll(text); err != nil {
-		return err
+// keybdKeyboard simula el teclado con keybd_event.
+type keybdKeyboard struct{}
+
+var (
+	defaultKeyboardOnce sync.Once
+	defaultKeyboard     Keyboard
+)
+
+// DefaultKeyboard devuelve el teclado que se usa para pegar en la aplicación activa. Se
+// elige la primera vez que se usa (en Linux depende de la sesión y de las herramientas
+// instaladas).
+func DefaultKeyboard() Keyboard {
+	defaultKeyboardOnce.Do(func() { defaultKeyboard = newDefaultKeyboard() })
+	return defaultKeyboard
+}
+
+// PasteText introduce un texto en la aplicación activa con la estrategia de opts (por
+// defecto, copiándolo al portapapeles y pulsando Ctrl+V). Según opts, el contenido anterior
+// del portapapeles se restaura después.
+func PasteText(text string, opts PasteOptions) error {
+	paster := Paster{
+		Clipboard: SystemClipboard,
+		Keyboard:  DefaultKeyboard(),
+		Options:   opts,
 	}
+	return paster.Paste(text)
+}
 
-	// 2. Inicializar el simulador de eventos de teclado.
+// PressPaste pulsa el atajo de pegar de la estrategia.
+func (keybdKeyboard) PressPaste(s Strategy) error {
 	kb, err := keybd_event.NewKeyBonding()
 	if err != nil {
 		return err
 	}
 
-	// 3. Configurar la combinación de teclas Ctrl + V.
-	kb.HasCTRL(true)
-	kb.SetKeys(keybd_event.VK_V)
+	switch s {
+	case StrategyCtrlV:
+		kb.HasCTRL(true)
+		kb.SetKeys(keybd_event.VK_V)
+	case StrategyCtrlShiftV:
+		kb.HasCTRL(true)
+		kb.HasSHIFT(true)
+		kb.SetKeys(keybd_event.VK_V)
+	case StrategyShiftInsert:
+		if vkInsert < 0 {
+			return fmt.Errorf("la tecla Insert no existe en este sistema")
+		}
+		kb.HasSHIFT(true)
+		kb.SetKeys(vkInsert)
+	default:
+		return fmt.Errorf("la estrategia %s no usa un atajo de pegar", s)
+	}
+	return kb.Launching()
+}
 
-	// Pequeña espera para asegurar que el portapapeles procesó el nuevo texto.
-	time.Sleep(100 * time.Millisecond)
+// TypeText escribe el texto carácter a carácter.
+func (keybdKeyboard) TypeText(text string) error {
+	return typeUnicode(text)
+}
 
-	// 4. Ejecutar la pulsación de teclas.
-	err = kb.Launching()
+// MoveCursorLeft pulsa la flecha izquierda n veces (para dejar el cursor dentro del texto pegado).
+func (keybdKeyboard) MoveCursorLeft(n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	kb, err := keybd_event.NewKeyBonding()
 	if err != nil {
 		return err
 	}
+	kb.SetKeys(keybd_event.VK_LEFT)
 
+	for i := 0; i < n; i++ {
+		if err := kb.Launching(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package utils
+
+// newDefaultKeyboard usa keybd_event, que en Windows y macOS llega a cualquier ventana.
+func newDefaultKeyboard() Keyboard {
+	return keybdKeyboard{}
+}
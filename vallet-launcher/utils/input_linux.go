@@ -0,0 +1,54 @@
+//go:build linux
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// newDefaultKeyboard prefiere en Linux las herramientas externas según la sesión (wtype o
+// ydotool en Wayland, xdotool o ydotool en X11); keybd_event queda como último recurso
+// porque no funciona en Wayland y no sabe escribir caracteres arbitrarios.
+func newDefaultKeyboard() Keyboard {
+	keyboard, backends := BackendKeyboard(osProbe{}, runCommand, keybdKeyboard{})
+	if len(backends) > 0 {
+		names := make([]string, 0, len(backends))
+		for _, b := range backends {
+			names = append(names, b.Name())
+		}
+		fmt.Printf("⌨️ Sesión %s: pegando con %s\n", DetectSession(osProbe{}), strings.Join(names, ", "))
+	}
+	return keyboard
+}
+
+// ActiveWindow devuelve la clase y el proceso de la ventana con el foco. Solo es posible
+// en X11 con xdotool; Wayland no permite a las aplicaciones consultar la ventana activa.
+func ActiveWindow() (WindowInfo, error) {
+	if DetectSession(osProbe{}) != SessionX11 {
+		return WindowInfo{}, errors.New("detectar la ventana activa solo es posible en X11")
+	}
+	if _, err := exec.LookPath("xdotool"); err != nil {
+		return WindowInfo{}, errors.New("se necesita xdotool para detectar la ventana activa")
+	}
+
+	class, err := exec.Command("xdotool", "getactivewindow", "getwindowclassname").Output()
+	if err != nil {
+		return WindowInfo{}, fmt.Errorf("xdotool: %w", err)
+	}
+	info := WindowInfo{Class: strings.TrimSpace(string(class))}
+
+	if pid, err := exec.Command("xdotool", "getactivewindow", "getwindowpid").Output(); err == nil {
+		procDir := filepath.Join("/proc", strings.TrimSpace(string(pid)))
+		if exe, err := os.Readlink(filepath.Join(procDir, "exe")); err == nil {
+			info.Process = filepath.Base(exe)
+		} else if comm, err := os.ReadFile(filepath.Join(procDir, "comm")); err == nil {
+			info.Process = strings.TrimSpace(string(comm))
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package utils
+
+import "errors"
+
+// typeUnicode no está disponible fuera de Windows: keybd_event solo pulsa teclas, no
+// escribe caracteres arbitrarios. En Linux se escribe con xdotool, ydotool o wtype.
+func typeUnicode(text string) error {
+	return errors.New("escribir carácter a carácter no está soportado en este sistema")
+}
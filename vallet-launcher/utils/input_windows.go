@@ -0,0 +1,73 @@
+//go:build windows
+
+package utils
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unsafe"
+)
+
+var sendInput = user32.NewProc("SendInput") // Enviar eventos de teclado sintéticos.
+
+const (
+	INPUT_KEYBOARD    = 1      // El evento es de teclado.
+	KEYEVENTF_KEYUP   = 0x0002 // Soltar la tecla.
+	KEYEVENTF_UNICODE = 0x0004 // wScan contiene un carácter UTF-16 en lugar de una tecla.
+	VK_RETURN         = 0x0D   // Tecla Enter.
+	VK_TAB            = 0x09   // Tecla Tab.
+)
+
+// keybdInput equivale a KEYBDINPUT.
+type keybdInput struct {
+	vk        uint16
+	scan      uint16
+	flags     uint32
+	time      uint32
+	extraInfo uintptr
+}
+
+// keyboardInput equivale a INPUT con el miembro ki; el relleno iguala el tamaño de la
+// unión (la mayor es MOUSEINPUT).
+type keyboardInput struct {
+	inputType uint32
+	ki        keybdInput
+	_         [8]byte
+}
+
+// typeUnicode escribe el texto enviando cada carácter como evento Unicode, así no depende
+// de la distribución del teclado. Enter y Tab se envían como teclas para que las
+// aplicaciones los interpreten como saltos de línea y tabulaciones.
+func typeUnicode(text string) error {
+	var inputs []keyboardInput
+	key := func(vk, scan uint16, flags uint32) {
+		inputs = append(inputs,
+			keyboardInput{inputType: INPUT_KEYBOARD, ki: keybdInput{vk: vk, scan: scan, flags: flags}},
+			keyboardInput{inputType: INPUT_KEYBOARD, ki: keybdInput{vk: vk, scan: scan, flags: flags | KEYEVENTF_KEYUP}},
+		)
+	}
+
+	for _, r := range text {
+		switch r {
+		case '\r':
+			continue
+		case '\n':
+			key(VK_RETURN, 0, 0)
+		case '\t':
+			key(VK_TAB, 0, 0)
+		default:
+			for _, unit := range utf16.Encode([]rune{r}) {
+				key(0, unit, KEYEVENTF_UNICODE)
+			}
+		}
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	sent, _, err := sendInput.Call(uintptr(len(inputs)), uintptr(unsafe.Pointer(&inputs[0])), unsafe.Sizeof(inputs[0]))
+	if int(sent) != len(inputs) {
+		return fmt.Errorf("SendInput envió %d de %d eventos: %v", sent, len(inputs), err)
+	}
+	return nil
+}
@@ -0,0 +1,4 @@
+package utils
+
+// vkInsert es -1 porque los teclados de Mac no tienen tecla Insert.
+const vkInsert = -1
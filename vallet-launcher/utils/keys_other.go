@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package utils
+
+import "github.com/micmonay/keybd_event"
+
+// vkInsert es la tecla Insert para Shift+Insert.
+const vkInsert = keybd_event.VK_INSERT
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// StartDetached inicia un proceso desvinculado del launcher: no hereda su consola ni su
+// sesión, así que sigue vivo aunque el launcher se cierre. dir es el directorio de
+// trabajo (vacío para el actual).
+func StartDetached(dir string, argv []string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("comando vacío")
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Se espera en segundo plano solo para liberar el proceso al terminar (evita zombis).
+	go cmd.Wait()
+	return nil
+}
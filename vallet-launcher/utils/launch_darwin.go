@@ -0,0 +1,13 @@
+//go:build darwin
+
+package utils
+
+// OpenPath abre un archivo, carpeta o URL con el programa predeterminado de macOS.
+func OpenPath(target string) error {
+	return StartDetached("", []string{"open", target})
+}
+
+// RevealPath muestra el archivo o carpeta seleccionado en el Finder.
+func RevealPath(path string) error {
+	return StartDetached("", []string{"open", "-R", path})
+}
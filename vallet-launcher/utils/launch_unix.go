@@ -0,0 +1,19 @@
+//go:build !windows
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach crea una sesión nueva para el proceso, de modo que no recibe las señales
+// dirigidas al grupo del launcher al cerrarlo.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// RunCommand ejecuta una línea de comandos con el shell sin esperar a que termine.
+func RunCommand(cmdline string) error {
+	return StartDetached("", []string{"/bin/sh", "-c", cmdline})
+}
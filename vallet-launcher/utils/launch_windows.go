@@ -0,0 +1,60 @@
+//go:build windows
+
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	createNewProcessGroup = 0x00000200 // CREATE_NEW_PROCESS_GROUP
+	detachedProcess       = 0x00000008 // DETACHED_PROCESS
+	swShowNormal          = 1          // SW_SHOWNORMAL
+	seErrNoAssoc          = 31         // SE_ERR_NOASSOC: ningún programa abre ese tipo de archivo.
+)
+
+var (
+	shell32      = syscall.NewLazyDLL("shell32.dll")
+	shellExecute = shell32.NewProc("ShellExecuteW") // Abrir un archivo o URL con su programa asociado.
+)
+
+// OpenPath abre un archivo, carpeta o URL con el programa predeterminado de Windows. Se usa
+// ShellExecute y no "cmd /C start" porque cmd interpreta &, ^ o | dentro del nombre
+// (ej: "R&D.docx") y un nombre preparado podría ejecutar otro comando.
+func OpenPath(target string) error {
+	file, err := syscall.UTF16PtrFromString(target)
+	if err != nil {
+		return err
+	}
+	// Sin verbo se usa la acción predeterminada del tipo de archivo.
+	ret, _, _ := shellExecute.Call(0, 0, uintptr(unsafe.Pointer(file)), 0, 0, swShowNormal)
+	switch {
+	case ret > 32:
+		return nil
+	case ret == seErrNoAssoc:
+		return fmt.Errorf("no hay ningún programa asociado para abrir %s", target)
+	default:
+		return fmt.Errorf("no se pudo abrir %s: %w", target, syscall.Errno(ret))
+	}
+}
+
+// RunCommand ejecuta una línea de comandos sin esperar a que termine. 'start' también
+// encuentra aplicaciones registradas que no están en el PATH (ej: "chrome").
+func RunCommand(cmdline string) error {
+	return StartDetached("", []string{"cmd", "/C", "start", "", cmdline})
+}
+
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: createNewProcessGroup | detachedProcess,
+	}
+}
+
+// RevealPath abre el Explorador de Windows con el archivo o carpeta seleccionado.
+func RevealPath(path string) error {
+	return StartDetached("", []string{"explorer", "/select," + path})
+}
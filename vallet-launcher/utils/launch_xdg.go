@@ -0,0 +1,48 @@
+//go:build !windows && !darwin
+
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+)
+
+// openers son los programas que abren archivos y URLs con la aplicación predeterminada
+// del escritorio, en orden de preferencia.
+var openers = [][]string{
+	{"xdg-open"},
+	{"gio", "open"},
+	{"kde-open5"},
+	{"exo-open"},
+}
+
+// OpenPath abre un archivo, carpeta o URL con el programa predeterminado del escritorio.
+func OpenPath(target string) error {
+	for _, opener := range openers {
+		path, err := exec.LookPath(opener[0])
+		if err != nil {
+			continue
+		}
+		argv := append([]string{path}, opener[1:]...)
+		return StartDetached("", append(argv, target))
+	}
+	return fmt.Errorf("no se encontró xdg-open para abrir %s", target)
+}
+
+// RevealPath muestra el archivo o carpeta seleccionado en el gestor de archivos mediante
+// la interfaz D-Bus org.freedesktop.FileManager1. Si no está disponible, abre la carpeta
+// que lo contiene.
+func RevealPath(path string) error {
+	if dbusSend, err := exec.LookPath("dbus-send"); err == nil {
+		uri := (&url.URL{Scheme: "file", Path: path}).String()
+		err := exec.Command(dbusSend, "--session", "--print-reply", "--dest=org.freedesktop.FileManager1",
+			"--type=method_call", "/org/freedesktop/FileManager1", "org.freedesktop.FileManager1.ShowItems",
+			"array:string:"+uri, "string:").Run()
+		if err == nil {
+			return nil
+		}
+	}
+	return OpenPath(filepath.Dir(path))
+}
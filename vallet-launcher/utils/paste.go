@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+const (
+	// clipboardSettleDelay es la espera tras escribir en el portapapeles antes de pegar.
+	clipboardSettleDelay = 100 * time.Millisecond
+	// DefaultRestoreDelay es cuánto se espera a que la aplicación destino lea el texto
+	// pegado antes de devolver al portapapeles su contenido anterior.
+	DefaultRestoreDelay = 500 * time.Millisecond
+)
+
+// Clipboard es el portapapeles sobre el que se pega. Permite sustituir el del sistema por
+// uno falso en las pruebas.
+type Clipboard interface {
+	ReadAll() (string, error)
+	WriteAll(text string) error
+}
+
+// systemClipboard es el portapapeles del sistema operativo.
+type systemClipboard struct{}
+
+func (systemClipboard) ReadAll() (string, error)   { return clipboard.ReadAll() }
+func (systemClipboard) WriteAll(text string) error { return clipboard.WriteAll(text) }
+
+// SystemClipboard es el portapapeles del sistema operativo.
+var SystemClipboard Clipboard = systemClipboard{}
+
+// pastesInProgress cuenta los pegados a través del portapapeles que están en curso.
+var pastesInProgress atomic.Int32
+
+// PasteInProgress indica si se está pegando a través del portapapeles. Mientras tanto el
+// portapapeles tiene contenido temporal (el texto pegado y luego el restaurado) que no debe
+// guardarse en el historial.
+func PasteInProgress() bool {
+	return pastesInProgress.Load() > 0
+}
+
+// PasteOptions configura cómo se pega un texto.
+type PasteOptions struct {
+	// Strategy es la forma de introducir el texto ("" usa StrategyCtrlV).
+	Strategy Strategy
+	// RestoreClipboard devuelve al portapapeles lo que tenía antes de pegar.
+	RestoreClipboard bool
+	// RestoreDelay es la espera antes de restaurar (0 usa DefaultRestoreDelay).
+	RestoreDelay time.Duration
+	// CursorLeft es cuántas posiciones se retrocede el cursor justo después de pegar
+	// (ej: hasta la posición de {cursor} en un snippet).
+	CursorLeft int
+}
+
+// Paster introduce texto en la aplicación activa.
+type Paster struct {
+	Clipboard Clipboard           // Portapapeles a usar.
+	Keyboard  Keyboard            // Teclado con el que se pega o se escribe.
+	Sleep     func(time.Duration) // Espera entre pasos (time.Sleep si es nil).
+	Options   PasteOptions        // Estrategia y restauración del portapapeles.
+}
+
+// Paste introduce el texto con la estrategia configurada. Las estrategias de portapapeles
+// copian el texto y pulsan el atajo de pegar; si está activada la restauración, guardan
+// antes el contenido del portapapeles y lo recuperan cuando la aplicación destino ya leyó
+// el texto, salvo que mientras tanto se haya copiado otra cosa.
+func (p Paster) Paste(text string) error {
+	sleep := p.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	strategy := p.Options.Strategy
+	if strategy == "" {
+		strategy = StrategyCtrlV
+	}
+
+	if !strategy.usesClipboard() {
+		if err := p.Keyboard.TypeText(text); err != nil {
+			return err
+		}
+		return p.Keyboard.MoveCursorLeft(p.Options.CursorLeft)
+	}
+
+	pastesInProgress.Add(1)
+	defer pastesInProgress.Add(-1)
+
+	// 1. Guardar el contenido actual. Si no se puede leer (ej: contiene una imagen) no se
+	// restaura, para no reemplazarlo por un texto vacío.
+	var previous string
+	restore := p.Options.RestoreClipboard
+	if restore {
+		var err error
+		previous, err = p.Clipboard.ReadAll()
+		restore = err == nil && previous != text
+	}
+
+	// 2. Escribir el texto y esperar a que el portapapeles lo procese.
+	if err := p.Clipboard.WriteAll(text); err != nil {
+		return err
+	}
+	sleep(clipboardSettleDelay)
+
+	// 3. Pegar en la aplicación activa.
+	if err := p.Keyboard.PressPaste(strategy); err != nil {
+		if restore {
+			p.Clipboard.WriteAll(previous)
+		}
+		return err
+	}
+
+	cursorErr := p.Keyboard.MoveCursorLeft(p.Options.CursorLeft)
+	if !restore {
+		return cursorErr
+	}
+
+	// 4. Restaurar cuando la aplicación destino ya consumió el texto.
+	delay := p.Options.RestoreDelay
+	if delay <= 0 {
+		delay = DefaultRestoreDelay
+	}
+	sleep(delay)
+	if current, err := p.Clipboard.ReadAll(); err == nil && current != text {
+		// El usuario copió otra cosa mientras tanto: no se pisa.
+		return cursorErr
+	}
+	if err := p.Clipboard.WriteAll(previous); err != nil {
+		return err
+	}
+	return cursorErr
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClipboard es un portapapeles en memoria que registra cada escritura.
+type fakeClipboard struct {
+	content string
+	readErr error
+	writes  []string
+}
+
+func (c *fakeClipboard) ReadAll() (string, error) {
+	if c.readErr != nil {
+		return "", c.readErr
+	}
+	return c.content, nil
+}
+
+func (c *fakeClipboard) WriteAll(text string) error {
+	c.content = text
+	c.writes = append(c.writes, text)
+	return nil
+}
+
+// fakeKeyboard registra las teclas pulsadas y permite simular fallos.
+type fakeKeyboard struct {
+	pasteErr error
+	pasted   []Strategy
+	typed    []string
+	left     int
+	onPaste  func() // Se llama al pulsar pegar (ej: para leer el portapapeles en ese momento).
+}
+
+func (k *fakeKeyboard) PressPaste(s Strategy) error {
+	k.pasted = append(k.pasted, s)
+	if k.onPaste != nil {
+		k.onPaste()
+	}
+	return k.pasteErr
+}
+
+func (k *fakeKeyboard) TypeText(text string) error {
+	k.typed = append(k.typed, text)
+	return nil
+}
+
+func (k *fakeKeyboard) MoveCursorLeft(n int) error {
+	k.left += n
+	return nil
+}
+
+// newTestPaster crea un Paster con fakes y sin esperas reales.
+func newTestPaster(cb *fakeClipboard, kb *fakeKeyboard, opts PasteOptions) Paster {
+	return Paster{Clipboard: cb, Keyboard: kb, Sleep: func(time.Duration) {}, Options: opts}
+}
+
+func TestPasteRestoresClipboard(t *testing.T) {
+	cb := &fakeClipboard{content: "anterior"}
+	var atPaste string
+	kb := &fakeKeyboard{onPaste: func() { atPaste = cb.content }}
+
+	err := newTestPaster(cb, kb, PasteOptions{RestoreClipboard: true}).Paste("dictado")
+	if err != nil {
+		t.Fatalf("Paste: %v", err)
+	}
+	if atPaste != "dictado" {
+		t.Errorf("al pegar el portapapeles tenía %q, se esperaba %q", atPaste, "dictado")
+	}
+	if len(kb.pasted) != 1 || kb.pasted[0] != StrategyCtrlV {
+		t.Errorf("atajos pulsados = %v, se esperaba [ctrl_v]", kb.pasted)
+	}
+	if cb.content != "anterior" {
+		t.Errorf("portapapeles final = %q, se esperaba %q", cb.content, "anterior")
+	}
+}
+
+func TestPasteWithoutRestore(t *testing.T) {
+	cb := &fakeClipboard{content: "anterior"}
+	kb := &fakeKeyboard{}
+
+	if err := newTestPaster(cb, kb, PasteOptions{RestoreClipboard: false}).Paste("dictado"); err != nil {
+		t.Fatalf("Paste: %v", err)
+	}
+	if cb.content != "dictado" {
+		t.Errorf("portapapeles final = %q, se esperaba %q", cb.content, "dictado")
+	}
+	if len(cb.writes) != 1 {
+		t.Errorf("escrituras = %v, se esperaba una sola", cb.writes)
+	}
+}
+
+func TestPasteKeepsContentCopiedDuringPaste(t *testing.T) {
+	cb := &fakeClipboard{content: "anterior"}
+	kb := &fakeKeyboard{}
+	p := newTestPaster(cb, kb, PasteOptions{RestoreClipboard: true, RestoreDelay: time.Second})
+	p.Sleep = func(d time.Duration) {
+		// Durante la espera previa a restaurar, el usuario copia otra cosa.
+		if d == time.Second {
+			cb.content = "copiado por el usuario"
+		}
+	}
+
+	if err := p.Paste("dictado"); err != nil {
+		t.Fatalf("Paste: %v", err)
+	}
+	if cb.content != "copiado por el usuario" {
+		t.Errorf("portapapeles final = %q, no se debía pisar lo copiado", cb.content)
+	}
+}
+
+func TestPasteRestoresWhenKeystrokeFails(t *testing.T) {
+	cb := &fakeClipboard{content: "anterior"}
+	kb := &fakeKeyboard{pasteErr: errors.New("sin teclado")}
+
+	err := newTestPaster(cb, kb, PasteOptions{RestoreClipboard: true}).Paste("dictado")
+	if err == nil {
+		t.Fatal("Paste no devolvió el error del teclado")
+	}
+	if cb.content != "anterior" {
+		t.Errorf("portapapeles final = %q, se esperaba %q", cb.content, "anterior")
+	}
+}
+
+func TestPasteSkipsRestoreWhenClipboardUnreadable(t *testing.T) {
+	cb := &fakeClipboard{readErr: errors.New("contiene una imagen")}
+	kb := &fakeKeyboard{}
+
+	if err := newTestPaster(cb, kb, PasteOptions{RestoreClipboard: true}).Paste("dictado"); err != nil {
+		t.Fatalf("Paste: %v", err)
+	}
+	if len(cb.writes) != 1 || cb.writes[0] != "dictado" {
+		t.Errorf("escrituras = %v, no se debía restaurar un contenido ilegible", cb.writes)
+	}
+}
+
+func TestPasteTypeStrategyLeavesClipboard(t *testing.T) {
+	cb := &fakeClipboard{content: "anterior"}
+	kb := &fakeKeyboard{}
+
+	err := newTestPaster(cb, kb, PasteOptions{Strategy: StrategyType, RestoreClipboard: true, CursorLeft: 3}).Paste("hola")
+	if err != nil {
+		t.Fatalf("Paste: %v", err)
+	}
+	if len(cb.writes) != 0 {
+		t.Errorf("escrituras = %v, la estrategia type no usa el portapapeles", cb.writes)
+	}
+	if len(kb.typed) != 1 || kb.typed[0] != "hola" || kb.left != 3 {
+		t.Errorf("typed = %v, left = %d", kb.typed, kb.left)
+	}
+}
+
+func TestPasteInProgress(t *testing.T) {
+	cb := &fakeClipboard{content: "anterior"}
+	var during bool
+	kb := &fakeKeyboard{onPaste: func() { during = PasteInProgress() }}
+
+	if err := newTestPaster(cb, kb, PasteOptions{RestoreClipboard: true}).Paste("dictado"); err != nil {
+		t.Fatalf("Paste: %v", err)
+	}
+	if !during {
+		t.Error("PasteInProgress() = false durante el pegado")
+	}
+	if PasteInProgress() {
+		t.Error("PasteInProgress() = true después del pegado")
+	}
+}
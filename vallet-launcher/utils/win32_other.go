@@ -0,0 +1,12 @@
+//go:build !windows
+
+package utils
+
+// ShowWindowNoActivate no hace nada fuera de Windows; la ventana se muestra con el runtime de Wails.
+func ShowWindowNoActivate(title string) {}
+
+// ResizeWindowNoActivate no hace nada fuera de Windows.
+func ResizeWindowNoActivate(title string, width, height int) {}
+
+// CenterWindowNoActivate no hace nada fuera de Windows.
+func CenterWindowNoActivate(title string, width, height int) {}
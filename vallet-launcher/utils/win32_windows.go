@@ -3,6 +3,8 @@
 package utils
 
 import (
+	"fmt"
+	"path/filepath"
 	"syscall"
 	"unsafe"
 )
@@ -61,3 +63,40 @@ func CenterWindowNoActivate(title string, width, height int) {
 		setWindowPos.Call(hwnd, 0, uintptr(x), uintptr(y), uintptr(width), uintptr(height), SWP_NOACTIVATE)
 	}
 }
+
+var (
+	getForegroundWindow       = user32.NewProc("GetForegroundWindow")      // Ventana con el foco.
+	getClassName              = user32.NewProc("GetClassNameW")            // Clase de una ventana.
+	getWindowThreadProcessID  = user32.NewProc("GetWindowThreadProcessId") // Proceso dueño de una ventana.
+	kernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	queryFullProcessImageName = kernel32.NewProc("QueryFullProcessImageNameW") // Ruta del ejecutable de un proceso.
+)
+
+// PROCESS_QUERY_LIMITED_INFORMATION permite leer la ruta del ejecutable de otro proceso.
+const PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
+
+// ActiveWindow devuelve la clase y el nombre del ejecutable de la ventana con el foco.
+func ActiveWindow() (WindowInfo, error) {
+	hwnd, _, _ := getForegroundWindow.Call()
+	if hwnd == 0 {
+		return WindowInfo{}, fmt.Errorf("no hay una ventana con el foco")
+	}
+
+	var info WindowInfo
+	class := make([]uint16, 256)
+	if n, _, _ := getClassName.Call(hwnd, uintptr(unsafe.Pointer(&class[0])), uintptr(len(class))); n > 0 {
+		info.Class = syscall.UTF16ToString(class[:n])
+	}
+
+	var pid uint32
+	getWindowThreadProcessID.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if process, err := syscall.OpenProcess(PROCESS_QUERY_LIMITED_INFORMATION, false, pid); err == nil {
+		defer syscall.CloseHandle(process)
+		name := make([]uint16, syscall.MAX_PATH)
+		size := uint32(len(name))
+		if ok, _, _ := queryFullProcessImageName.Call(uintptr(process), 0, uintptr(unsafe.Pointer(&name[0])), uintptr(unsafe.Pointer(&size))); ok != 0 {
+			info.Process = filepath.Base(syscall.UTF16ToString(name[:size]))
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows && !linux
+
+package utils
+
+import "errors"
+
+// ActiveWindow no sabe detectar la ventana con el foco en este sistema; las reglas por
+// aplicación no se aplican y se usa la estrategia por defecto.
+func ActiveWindow() (WindowInfo, error) {
+	return WindowInfo{}, errors.New("detectar la ventana activa no está soportado en este sistema")
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"vallet-launcher/ai"
+)
+
+// whisperSettingKeys son las claves de settings con los parámetros de whisper.cpp.
+var whisperSettingKeys = []string{
+	"whisper_model_path",
+	"whisper_language",
+	"whisper_threads",
+	"whisper_beam_size",
+	"whisper_best_of",
+	"whisper_temperature",
+	"whisper_initial_prompt",
+	"whisper_translate",
+}
+
+// WhisperSettings son los parámetros de transcripción que se editan en el panel de
+// administración.
+type WhisperSettings struct {
+	ModelPath     string  `json:"modelPath"`     // Ruta del modelo ("" para usar ggml-small.bin de la carpeta whisper/).
+	Language      string  `json:"language"`      // Código del idioma o "auto".
+	Threads       int     `json:"threads"`       // Hilos de CPU (0, el valor por defecto, para usar todos los núcleos).
+	BeamSize      int     `json:"beamSize"`      // Haces del beam search (1 = el más rápido).
+	BestOf        int     `json:"bestOf"`        // Candidatos al muestrear.
+	Temperature   float64 `json:"temperature"`   // Temperatura de muestreo (0 = determinista).
+	InitialPrompt string  `json:"initialPrompt"` // Texto que orienta el vocabulario y la puntuación.
+	Translate     bool    `json:"translate"`     // Traducir al inglés.
+}
+
+// defaultWhisperSettings devuelve la configuración por defecto.
+func defaultWhisperSettings() WhisperSettings {
+	o := ai.DefaultWhisperOptions()
+	return WhisperSettings{
+		Language: "auto",
+		Threads:  o.Threads,
+		BeamSize: o.BeamSize,
+		BestOf:   o.BestOf,
+	}
+}
+
+// set interpreta el valor guardado en key y lo asigna.
+func (s *WhisperSettings) set(key, value string) error {
+	value = strings.TrimSpace(value)
+	parseInt := func(name string, dst *int) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s inválido: %q", name, value)
+		}
+		*dst = n
+		return nil
+	}
+
+	switch key {
+	case "whisper_model_path":
+		s.ModelPath = value
+	case "whisper_language":
+		s.Language = strings.ToLower(value)
+	case "whisper_threads":
+		if value == "" {
+			s.Threads = 0 // Todos los núcleos.
+			return nil
+		}
+		return parseInt("número de hilos", &s.Threads)
+	case "whisper_beam_size":
+		return parseInt("tamaño del haz", &s.BeamSize)
+	case "whisper_best_of":
+		return parseInt("número de candidatos", &s.BestOf)
+	case "whisper_temperature":
+		t, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		if err != nil {
+			return fmt.Errorf("temperatura inválida: %q", value)
+		}
+		s.Temperature = t
+	case "whisper_initial_prompt":
+		s.InitialPrompt = value
+	case "whisper_translate":
+		s.Translate = value == "true"
+	default:
+		return fmt.Errorf("opción de whisper desconocida: %q", key)
+	}
+	return nil
+}
+
+// values devuelve los valores a guardar en settings, por clave.
+func (s WhisperSettings) values() map[string]string {
+	return map[string]string{
+		"whisper_model_path":     s.ModelPath,
+		"whisper_language":       s.Language,
+		"whisper_threads":        strconv.Itoa(s.Threads),
+		"whisper_beam_size":      strconv.Itoa(s.BeamSize),
+		"whisper_best_of":        strconv.Itoa(s.BestOf),
+		"whisper_temperature":    strconv.FormatFloat(s.Temperature, 'f', -1, 64),
+		"whisper_initial_prompt": s.InitialPrompt,
+		"whisper_translate":      strconv.FormatBool(s.Translate),
+	}
+}
+
+// options convierte la configuración en las opciones de los backends de whisper.cpp.
+func (s WhisperSettings) options() ai.WhisperOptions {
+	return ai.WhisperOptions{
+		ModelPath:     s.ModelPath,
+		Threads:       s.Threads,
+		BeamSize:      s.BeamSize,
+		BestOf:        s.BestOf,
+		Temperature:   s.Temperature,
+		InitialPrompt: s.InitialPrompt,
+		Translate:     s.Translate,
+	}
+}
+
+// validate comprueba el idioma y los rangos de los parámetros.
+func (s WhisperSettings) validate() error {
+	return errors.Join(ai.ValidateLanguage(s.Language), s.options().Validate())
+}
+
+// loadWhisperSettings lee la configuración de whisper.cpp. Los valores inválidos (ej: un
+// modelo borrado o más hilos de los que tiene el equipo) se sustituyen por los valores por
+// defecto.
+func (a *App) loadWhisperSettings() WhisperSettings {
+	s := defaultWhisperSettings()
+	for _, key := range whisperSettingKeys {
+		value, err := a.db.GetSetting(key)
+		if err != nil {
+			continue
+		}
+		candidate := s
+		if err := candidate.set(key, value); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+			continue
+		}
+		if err := candidate.validate(); err != nil {
+			fmt.Printf("⚠️ Se ignora %s: %v\n", key, err)
+			continue
+		}
+		s = candidate
+	}
+	return s
+}
+
+// GetWhisperSettings devuelve la configuración de whisper.cpp para el panel de administración.
+func (a *App) GetWhisperSettings() WhisperSettings {
+	return a.loadWhisperSettings()
+}
+
+// SaveWhisperSettings valida y guarda la configuración de whisper.cpp.
+func (a *App) SaveWhisperSettings(s WhisperSettings) error {
+	s.ModelPath = strings.TrimSpace(s.ModelPath)
+	s.Language = strings.ToLower(strings.TrimSpace(s.Language))
+	s.InitialPrompt = strings.TrimSpace(s.InitialPrompt)
+	if err := s.validate(); err != nil {
+		return err
+	}
+	for key, value := range s.values() {
+		if err := a.db.UpdateSetting(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetWhisperLanguages devuelve los idiomas que reconoce whisper, para elegir uno en el panel.
+func (a *App) GetWhisperLanguages() []ai.Language {
+	return ai.Languages()
+}
+
+// validateWhisperSetting comprueba un valor antes de guardarlo con UpdateSettingBackend.
+// Devuelve nil para las claves que no son de whisper.cpp.
+func (a *App) validateWhisperSetting(key, value string) error {
+	if !slices.Contains(whisperSettingKeys, key) {
+		return nil
+	}
+	s := a.loadWhisperSettings()
+	if err := s.set(key, value); err != nil {
+		return err
+	}
+	return s.validate()
+}
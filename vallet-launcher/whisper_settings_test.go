@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWhisperSettingsSet(t *testing.T) {
+	tests := []struct {
+		key, value string
+		check      func(WhisperSettings) bool
+	}{
+		{"whisper_model_path", " /modelos/ggml-base.bin ", func(s WhisperSettings) bool { return s.ModelPath == "/modelos/ggml-base.bin" }},
+		{"whisper_language", "ES", func(s WhisperSettings) bool { return s.Language == "es" }},
+		{"whisper_threads", "4", func(s WhisperSettings) bool { return s.Threads == 4 }},
+		{"whisper_threads", "0", func(s WhisperSettings) bool { return s.Threads == 0 }},
+		{"whisper_threads", "", func(s WhisperSettings) bool { return s.Threads == 0 }},
+		{"whisper_beam_size", "5", func(s WhisperSettings) bool { return s.BeamSize == 5 }},
+		{"whisper_best_of", " 3 ", func(s WhisperSettings) bool { return s.BestOf == 3 }},
+		{"whisper_temperature", "0.2", func(s WhisperSettings) bool { return s.Temperature == 0.2 }},
+		{"whisper_temperature", "0,2", func(s WhisperSettings) bool { return s.Temperature == 0.2 }},
+		{"whisper_initial_prompt", "Vallet, Jira.", func(s WhisperSettings) bool { return s.InitialPrompt == "Vallet, Jira." }},
+		{"whisper_translate", "true", func(s WhisperSettings) bool { return s.Translate }},
+		{"whisper_translate", "1", func(s WhisperSettings) bool { return !s.Translate }},
+	}
+	for _, tt := range tests {
+		s := WhisperSettings{Threads: 8, Translate: true}
+		if err := s.set(tt.key, tt.value); err != nil {
+			t.Errorf("set(%q, %q) = %v", tt.key, tt.value, err)
+			continue
+		}
+		if !tt.check(s) {
+			t.Errorf("set(%q, %q) dejó %+v", tt.key, tt.value, s)
+		}
+	}
+}
+
+func TestWhisperSettingsSetErrors(t *testing.T) {
+	tests := []struct{ key, value, wantErr string }{
+		{"whisper_threads", "muchos", "número de hilos inválido"},
+		{"whisper_beam_size", "1.5", "tamaño del haz inválido"},
+		{"whisper_best_of", "", "número de candidatos inválido"},
+		{"whisper_temperature", "0,2,1", "temperatura inválida"},
+		{"whisper_desconocida", "1", "opción de whisper desconocida"},
+	}
+	for _, tt := range tests {
+		var s WhisperSettings
+		if err := s.set(tt.key, tt.value); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("set(%q, %q) = %v, se esperaba un error con %q", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWhisperSettingsRoundTrip(t *testing.T) {
+	want := WhisperSettings{Language: "es", BeamSize: 5, BestOf: 2, Temperature: 0.4, InitialPrompt: "Hola.", Translate: true}
+	var got WhisperSettings
+	for key, value := range want.values() {
+		if err := got.set(key, value); err != nil {
+			t.Fatalf("set(%q, %q) = %v", key, value, err)
+		}
+	}
+	if got != want {
+		t.Errorf("values() y set() = %+v, se esperaba %+v", got, want)
+	}
+	if err := defaultWhisperSettings().validate(); err != nil {
+		t.Errorf("defaultWhisperSettings().validate() = %v", err)
+	}
+}